@@ -0,0 +1,236 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	lru "github.com/hashicorp/golang-lru"
+	"redo.ai/internal/model"
+	"redo.ai/internal/pkg/cursor"
+	"redo.ai/internal/pkg/entitlements"
+	"redo.ai/internal/service/admin"
+	"redo.ai/internal/service/audit"
+	"redo.ai/internal/service/link"
+	"redo.ai/internal/service/user"
+	"redo.ai/internal/utils"
+	"redo.ai/logger"
+)
+
+const (
+	defaultAdminPageSize = 50
+	maxAdminPageSize     = 200
+)
+
+type AdminHandler struct {
+	AdminService admin.AdminService
+	UserService  user.UserService
+	LinkService  link.LinkService
+	Audit        audit.AuditService
+	Cache        *lru.Cache
+}
+
+func NewAdminHandler(adminService admin.AdminService, userService user.UserService, linkService link.LinkService, auditService audit.AuditService, cache *lru.Cache) *AdminHandler {
+	return &AdminHandler{
+		AdminService: adminService,
+		UserService:  userService,
+		LinkService:  linkService,
+		Audit:        auditService,
+		Cache:        cache,
+	}
+}
+
+// AdminRouter serves the ops console under /api/admin/. Every route requires
+// the caller to hold the admin role.
+//
+//	GET  /api/admin/users?email=      search users by email
+//	PUT  /api/admin/users/role        change a user's role
+//	GET  /api/admin/links?user_id=    list any user's links
+//	POST /api/admin/links/disable     disable a link with a reason
+//	POST /api/admin/links/enable      re-enable a link
+//	GET  /api/admin/moderation        moderation audit log (?cursor=)
+func (ah *AdminHandler) AdminRouter() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		adminUser, ok := ah.requireAdmin(w, r)
+		if !ok {
+			return
+		}
+
+		switch strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/admin"), "/") {
+		case "users":
+			if validateMethod(w, r, http.MethodGet) {
+				ah.searchUsers(w, r)
+			}
+		case "users/role":
+			if validateMethod(w, r, http.MethodPut) {
+				ah.setRole(w, r, adminUser)
+			}
+		case "links":
+			if validateMethod(w, r, http.MethodGet) {
+				ah.listUserLinks(w, r)
+			}
+		case "links/disable":
+			if validateMethod(w, r, http.MethodPost) {
+				ah.setLinkActive(w, r, adminUser, false)
+			}
+		case "links/enable":
+			if validateMethod(w, r, http.MethodPost) {
+				ah.setLinkActive(w, r, adminUser, true)
+			}
+		case "moderation":
+			if validateMethod(w, r, http.MethodGet) {
+				ah.listModeration(w, r)
+			}
+		default:
+			utils.WriteJSONError(w, http.StatusNotFound, "Not Found")
+		}
+	}
+}
+
+// requireAdmin resolves the caller from the JWT sub and checks the admin role.
+func (ah *AdminHandler) requireAdmin(w http.ResponseWriter, r *http.Request) (*model.User, bool) {
+	sub, ok := verifySubFromContext(w, r)
+	if !ok {
+		return nil, false
+	}
+	usr, err := ah.UserService.GetByID(r.Context(), sub)
+	if err == sql.ErrNoRows {
+		utils.WriteJSONError(w, http.StatusForbidden, "Forbidden")
+		return nil, false
+	} else if err != nil {
+		logger.Error("requireAdmin: failed to load user for sub %s: %v", sub, err)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to verify user")
+		return nil, false
+	}
+	if usr.Role != string(entitlements.PlanAdmin) {
+		logger.Warn("requireAdmin: user %s with role %s denied", usr.UserID, usr.Role)
+		utils.WriteJSONError(w, http.StatusForbidden, "Forbidden")
+		return nil, false
+	}
+	return usr, true
+}
+
+func (ah *AdminHandler) searchUsers(w http.ResponseWriter, r *http.Request) {
+	email := strings.TrimSpace(r.URL.Query().Get("email"))
+	if email == "" {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Missing email")
+		return
+	}
+	users, err := ah.AdminService.SearchUsers(r.Context(), email, pageSize(r, defaultAdminPageSize, maxAdminPageSize))
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to search users")
+		return
+	}
+	utils.WriteJSON(w, http.StatusOK, users)
+}
+
+func (ah *AdminHandler) setRole(w http.ResponseWriter, r *http.Request, adminUser *model.User) {
+	var req model.SetRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || !IsValidUUID(req.UserID) {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	before, err := ah.UserService.GetByUserID(r.Context(), req.UserID)
+	if err == sql.ErrNoRows {
+		utils.WriteJSONError(w, http.StatusNotFound, "User not found")
+		return
+	} else if err != nil {
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to change role")
+		return
+	}
+	updated, err := ah.AdminService.SetRole(r.Context(), adminUser.UserID, req.UserID, req.Role)
+	switch err {
+	case nil:
+	case admin.ErrInvalidRole:
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid role")
+		return
+	case admin.ErrUserNotFound:
+		utils.WriteJSONError(w, http.StatusNotFound, "User not found")
+		return
+	default:
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to change role")
+		return
+	}
+	ah.evictUser(updated.UserID)
+	recordAudit(r, ah.Audit, adminUser.UserID, audit.ActionRoleChange, audit.TargetUser, updated.UserID,
+		map[string]string{"role": before.Role}, map[string]string{"role": updated.Role})
+	utils.WriteJSON(w, http.StatusOK, updated)
+}
+
+func (ah *AdminHandler) listUserLinks(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+	if !IsValidUUID(userID) {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid or missing user ID")
+		return
+	}
+	links, err := ah.LinkService.ListLinks(r.Context(), userID)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to fetch links")
+		return
+	}
+	utils.WriteJSON(w, http.StatusOK, links)
+}
+
+func (ah *AdminHandler) setLinkActive(w http.ResponseWriter, r *http.Request, adminUser *model.User, active bool) {
+	var req model.ModerateLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || !IsValidUUID(req.LinkID) {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+	if !active && req.Reason == "" {
+		utils.WriteJSONError(w, http.StatusBadRequest, "A reason is required to disable a link")
+		return
+	}
+	err := ah.AdminService.SetLinkActive(r.Context(), adminUser.UserID, req.LinkID, active, req.Reason)
+	if err == admin.ErrLinkNotFound {
+		utils.WriteJSONError(w, http.StatusNotFound, "Link not found")
+		return
+	} else if err != nil {
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to update link")
+		return
+	}
+	recordAudit(r, ah.Audit, adminUser.UserID, audit.ActionLinkModerate, audit.TargetLink, req.LinkID,
+		nil, map[string]any{"is_active": active, "reason": req.Reason})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (ah *AdminHandler) listModeration(w http.ResponseWriter, r *http.Request) {
+	page, err := ah.AdminService.ListModerationActions(r.Context(), r.URL.Query().Get("cursor"), pageSize(r, defaultAdminPageSize, maxAdminPageSize))
+	if err == cursor.ErrInvalidCursor {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid cursor")
+		return
+	} else if err != nil {
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to load moderation log")
+		return
+	}
+	utils.WriteJSON(w, http.StatusOK, page)
+}
+
+// evictUser drops cached login data for a user so a role change takes effect
+// on the next request. The cache is keyed by Auth0 sub, so it is scanned.
+func (ah *AdminHandler) evictUser(userID string) {
+	for _, key := range ah.Cache.Keys() {
+		cached, ok := ah.Cache.Peek(key)
+		if !ok {
+			continue
+		}
+		if u, ok := cached.(model.User); ok && u.UserID == userID {
+			ah.Cache.Remove(key)
+		}
+	}
+}
+
+// pageSize reads ?limit= clamped to [1, max].
+func pageSize(r *http.Request, def, max int) int {
+	n, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || n <= 0 {
+		return def
+	}
+	if n > max {
+		return max
+	}
+	return n
+}
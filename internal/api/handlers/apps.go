@@ -0,0 +1,225 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"redo.ai/internal/model"
+	"redo.ai/internal/pkg/entitlements"
+	"redo.ai/internal/pkg/platform"
+	"redo.ai/internal/service/applink"
+	"redo.ai/internal/service/audit"
+	"redo.ai/internal/service/user"
+	"redo.ai/internal/utils"
+	"redo.ai/logger"
+)
+
+// maxAppPaths and maxAppFingerprints bound one app registration.
+const (
+	maxAppPaths        = 20
+	maxAppFingerprints = 10
+)
+
+type AppHandler struct {
+	AppLinks    applink.AppLinkService
+	UserService user.UserService
+	Audit       audit.AuditService
+}
+
+func NewAppHandler(appLinks applink.AppLinkService, userService user.UserService, auditService audit.AuditService) *AppHandler {
+	return &AppHandler{
+		AppLinks:    appLinks,
+		UserService: userService,
+		Audit:       auditService,
+	}
+}
+
+// AppsRouter serves /api/apps: GET lists the registered apps, POST registers
+// one for a custom domain (or all of them when domain is empty) and
+// DELETE ?id= removes one.
+func (ah *AppHandler) AppsRouter() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := authorizeUser(w, r, ah.UserService)
+		if !ok {
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			apps, err := ah.AppLinks.ListApps(r.Context(), userID)
+			if err != nil {
+				utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to fetch apps")
+				return
+			}
+			utils.WriteJSON(w, http.StatusOK, apps)
+		case http.MethodPost:
+			if !requireFeature(w, r, ah.UserService, userID, entitlements.FeatureCustomDomains) {
+				return
+			}
+			var req model.CreateAppRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				utils.WriteJSONError(w, http.StatusBadRequest, "Invalid request payload")
+				return
+			}
+			if err := normalizeAppRequest(&req); err != nil {
+				writeErrorMessage(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			app, err := ah.AppLinks.CreateApp(r.Context(), userID, req)
+			if writeAppError(w, err) {
+				return
+			}
+			recordAudit(r, ah.Audit, userID, audit.ActionAppCreate, audit.TargetApp, app.ID, nil, app)
+			utils.WriteJSON(w, http.StatusCreated, app)
+		case http.MethodDelete:
+			id := r.URL.Query().Get("id")
+			if !IsValidUUID(id) {
+				utils.WriteJSONError(w, http.StatusBadRequest, "Invalid or missing app ID")
+				return
+			}
+			app, err := ah.AppLinks.DeleteApp(r.Context(), userID, id)
+			if writeAppError(w, err) {
+				return
+			}
+			recordAudit(r, ah.Audit, userID, audit.ActionAppDelete, audit.TargetApp, id, app, nil)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			utils.WriteJSONError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+		}
+	}
+}
+
+// normalizeAppRequest validates req and rewrites fingerprints into the form
+// assetlinks.json uses.
+func normalizeAppRequest(req *model.CreateAppRequest) error {
+	req.Platform = strings.ToLower(strings.TrimSpace(req.Platform))
+	req.AppID = strings.TrimSpace(req.AppID)
+	req.Domain = strings.ToLower(strings.TrimSpace(req.Domain))
+	switch req.Platform {
+	case model.AppPlatformIOS:
+		if !platform.ValidAppleAppID(req.AppID) {
+			return errors.New("app_id must be a Team ID and bundle ID, e.g. ABCDE12345.com.example.app")
+		}
+		if len(req.Fingerprints) > 0 {
+			return errors.New("sha256_cert_fingerprints only apply to Android apps")
+		}
+		if len(req.Paths) > maxAppPaths {
+			return fmt.Errorf("at most %d paths are allowed", maxAppPaths)
+		}
+		for _, p := range req.Paths {
+			if !platform.ValidAppLinkPath(p) {
+				return fmt.Errorf("invalid path %q", p)
+			}
+		}
+	case model.AppPlatformAndroid:
+		if !platform.ValidPackageName(req.AppID) {
+			return errors.New("app_id must be an Android package name")
+		}
+		if len(req.Paths) > 0 {
+			return errors.New("paths only apply to iOS apps")
+		}
+		if len(req.Fingerprints) == 0 || len(req.Fingerprints) > maxAppFingerprints {
+			return fmt.Errorf("between 1 and %d sha256_cert_fingerprints are required", maxAppFingerprints)
+		}
+		for i, fp := range req.Fingerprints {
+			normalized, err := platform.NormalizeFingerprint(fp)
+			if err != nil {
+				return fmt.Errorf("invalid fingerprint %q: %v", fp, err)
+			}
+			req.Fingerprints[i] = normalized
+		}
+	default:
+		return errors.New("platform must be ios or android")
+	}
+	return nil
+}
+
+func writeAppError(w http.ResponseWriter, err error) bool {
+	switch err {
+	case nil:
+		return false
+	case applink.ErrAppNotFound:
+		utils.WriteJSONError(w, http.StatusNotFound, "App not found")
+	case applink.ErrDomainNotFound:
+		utils.WriteJSONError(w, http.StatusNotFound, "Domain not found")
+	case applink.ErrAppExists:
+		utils.WriteJSONError(w, http.StatusConflict, "App already registered")
+	case applink.ErrTooManyApps:
+		writeErrorMessage(w, http.StatusConflict, err.Error())
+	default:
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to save app")
+	}
+	return true
+}
+
+// AppleAppSiteAssociation serves /.well-known/apple-app-site-association
+// for the custom domain in the Host header.
+func (ah *AppHandler) AppleAppSiteAssociation() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		apps, ok := ah.hostApps(w, r)
+		if !ok {
+			return
+		}
+		var ios []platform.IOSApp
+		for _, a := range apps {
+			if a.Platform == model.AppPlatformIOS {
+				ios = append(ios, platform.IOSApp{AppID: a.AppID, Paths: a.Paths})
+			}
+		}
+		if len(ios) == 0 {
+			utils.WriteJSONError(w, http.StatusNotFound, "Not Found")
+			return
+		}
+		writeWellKnown(w, platform.BuildAASA(ios))
+	}
+}
+
+// AssetLinks serves /.well-known/assetlinks.json for the custom domain in
+// the Host header.
+func (ah *AppHandler) AssetLinks() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		apps, ok := ah.hostApps(w, r)
+		if !ok {
+			return
+		}
+		var android []platform.AndroidApp
+		for _, a := range apps {
+			if a.Platform == model.AppPlatformAndroid {
+				android = append(android, platform.AndroidApp{Package: a.AppID, Fingerprints: a.Fingerprints})
+			}
+		}
+		if len(android) == 0 {
+			utils.WriteJSONError(w, http.StatusNotFound, "Not Found")
+			return
+		}
+		writeWellKnown(w, platform.BuildAssetLinks(android))
+	}
+}
+
+func (ah *AppHandler) hostApps(w http.ResponseWriter, r *http.Request) ([]model.AppAssociation, bool) {
+	if !validateMethod(w, r, http.MethodGet) {
+		return nil, false
+	}
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	apps, err := ah.AppLinks.AppsForHost(r.Context(), strings.TrimSuffix(host, "."))
+	if err != nil {
+		logger.Error("hostApps: failed to load apps for %s: %v", host, err)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to load apps")
+		return nil, false
+	}
+	return apps, true
+}
+
+// writeWellKnown writes an association file. Apple and Google fetch these
+// through their own CDNs, so a short cache keeps changes quick to land.
+func writeWellKnown(w http.ResponseWriter, doc any) {
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	utils.WriteJSON(w, http.StatusOK, doc)
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	jwtmiddleware "github.com/auth0/go-jwt-middleware/v2"
+	"github.com/auth0/go-jwt-middleware/v2/validator"
+)
+
+const testUserID = "0b6c3f0e-8c2f-4d7a-9b1e-5a4d3c2b1a00"
+
+// authedRequest builds a request that passes authorizeUser for testUserID.
+func authedRequest(method, target, body string) *http.Request {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	claims := &validator.ValidatedClaims{RegisteredClaims: validator.RegisteredClaims{Subject: "auth0|test"}}
+	r = r.WithContext(context.WithValue(r.Context(), jwtmiddleware.ContextKey{}, claims))
+	r.Header.Set("X-User-ID", testUserID)
+	return r
+}
+
+func TestAppsRouterEncodesValidationErrors(t *testing.T) {
+	ah := &AppHandler{UserService: planUsers{role: "pro"}}
+	tests := []struct{ body, want string }{
+		{`{"platform":"ios","app_id":"ABCDE12345.com.example.app","paths":["/a\"b c"]}`, `invalid path "/a\"b c"`},
+		{`{"platform":"android","app_id":"com.example.app","sha256_cert_fingerprints":["not\"hex"]}`, `invalid fingerprint "not\"hex"`},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		ah.AppsRouter()(rec, authedRequest(http.MethodPost, "/api/apps", tt.body))
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("%s: status = %d, want 400: %s", tt.body, rec.Code, rec.Body)
+		}
+		if msg := decodeErrorBody(t, rec.Body.Bytes()); !strings.HasPrefix(msg, tt.want) {
+			t.Errorf("message = %q, want it to start with %q", msg, tt.want)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"redo.ai/internal/model"
+	"redo.ai/internal/pkg/cursor"
+	"redo.ai/internal/pkg/entitlements"
+	"redo.ai/internal/service/audit"
+	"redo.ai/internal/service/user"
+	"redo.ai/internal/utils"
+	"redo.ai/logger"
+)
+
+type AuditHandler struct {
+	AuditService audit.AuditService
+	UserService  user.UserService
+}
+
+func NewAuditHandler(auditService audit.AuditService, userService user.UserService) *AuditHandler {
+	return &AuditHandler{
+		AuditService: auditService,
+		UserService:  userService,
+	}
+}
+
+// AuditRouter lists audit events. Regular users only see events they caused;
+// admins may filter by any actor.
+func (ah *AuditHandler) AuditRouter() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !validateMethod(w, r, http.MethodGet) {
+			return
+		}
+		sub, ok := verifySubFromContext(w, r)
+		if !ok {
+			return
+		}
+		usr, err := ah.UserService.GetByID(r.Context(), sub)
+		if err == sql.ErrNoRows {
+			utils.WriteJSONError(w, http.StatusNotFound, "User not found")
+			return
+		} else if err != nil {
+			logger.Error("AuditRouter: failed to load user for sub %s: %v", sub, err)
+			utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to verify user")
+			return
+		}
+
+		q := r.URL.Query()
+		filter := model.AuditFilter{
+			ActorID:    q.Get("actor_id"),
+			Action:     q.Get("action"),
+			TargetType: q.Get("target_type"),
+			TargetID:   q.Get("target_id"),
+			Cursor:     q.Get("cursor"),
+			Limit:      pageSize(r, audit.DefaultPageSize, audit.MaxPageSize),
+		}
+		if usr.Role != string(entitlements.PlanAdmin) {
+			filter.ActorID = usr.UserID
+		}
+		if filter.ActorID != "" && !IsValidUUID(filter.ActorID) {
+			utils.WriteJSONError(w, http.StatusBadRequest, "Invalid actor_id")
+			return
+		}
+		for name, dst := range map[string]*time.Time{"since": &filter.Since, "until": &filter.Until} {
+			if raw := q.Get(name); raw != "" {
+				t, err := time.Parse(time.RFC3339, raw)
+				if err != nil {
+					utils.WriteJSONError(w, http.StatusBadRequest, "Invalid "+name+" timestamp")
+					return
+				}
+				*dst = t
+			}
+		}
+
+		page, err := ah.AuditService.List(r.Context(), filter)
+		if err == cursor.ErrInvalidCursor {
+			utils.WriteJSONError(w, http.StatusBadRequest, "Invalid cursor")
+			return
+		} else if err != nil {
+			utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to load audit events")
+			return
+		}
+		utils.WriteJSON(w, http.StatusOK, page)
+	}
+}
+
+// recordAudit stores a mutation in the audit trail, computing the before/after
+// diff and stamping the request's IP and ID. Failures are logged but never
+// fail the request that already succeeded.
+func recordAudit(r *http.Request, svc audit.AuditService, actorID, action, targetType, targetID string, before, after any) {
+	if svc == nil {
+		return
+	}
+	b, a, err := audit.Diff(before, after)
+	if err != nil {
+		logger.Error("recordAudit: failed to diff %s on %s %s: %v", action, targetType, targetID, err)
+		return
+	}
+	ev := auditEventFromRequest(r, actorID)
+	ev.Action = action
+	ev.TargetType = targetType
+	ev.TargetID = targetID
+	ev.Before = b
+	ev.After = a
+	_ = svc.Record(r.Context(), ev)
+}
+
+// auditEventFromRequest returns an event stamped with the actor and the
+// request's IP and ID.
+func auditEventFromRequest(r *http.Request, actorID string) model.AuditEvent {
+	return model.AuditEvent{
+		ActorID:   actorID,
+		IP:        utils.ClientIP(r),
+		RequestID: utils.RequestIDFromContext(r.Context()),
+	}
+}
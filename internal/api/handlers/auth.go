@@ -63,7 +63,7 @@ func (au *AuthHandler) LoginHandler() http.HandlerFunc {
 				r.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
 
 				// 3. If the user doesn't exit sign them up
-				var user model.SignUpRequest
+				var user SignUpRequest
 				if err := json.NewDecoder(r.Body).Decode(&user); err != nil {
 					logger.Warn("Invalid request body during signup for sub: %s, error: %v", sub, err)
 					utils.WriteJSONError(w, http.StatusBadRequest, "Invalid request body")
@@ -76,6 +76,17 @@ func (au *AuthHandler) LoginHandler() http.HandlerFunc {
 					utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to create user")
 					return
 				}
+				if user.Name != "" || user.BusinessName != "" {
+					userData, err = au.UserService.UpdateProfile(r.Context(), userData.UserID, model.ProfileUpdate{
+						Name:         &user.Name,
+						BusinessName: &user.BusinessName,
+					})
+					if err != nil {
+						logger.Error("Failed to save profile for sub: %s, error: %v", sub, err)
+						utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to create user")
+						return
+					}
+				}
 			} else {
 				logger.Error("Database error fetching user for sub: %s, error: %v", sub, err)
 				utils.WriteJSONError(w, http.StatusInternalServerError, "Database error")
@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	"redo.ai/internal/model"
+	"redo.ai/internal/service/audit"
+	"redo.ai/internal/service/bio"
+	"redo.ai/internal/service/link"
+	"redo.ai/internal/service/user"
+	"redo.ai/internal/utils"
+	"redo.ai/logger"
+)
+
+// Limits on bio page text, in bytes.
+const (
+	maxBioTitleLength       = 100
+	maxBioDescriptionLength = 300
+	maxBioItemTitleLength   = 100
+	maxBioImageURLLength    = 2048
+)
+
+// bioHandlePattern allows lowercase letters, digits, '_' and inner dots.
+var bioHandlePattern = regexp.MustCompile(`^[a-z0-9_](?:[a-z0-9_.]{1,28}[a-z0-9_])$`)
+
+type BioHandler struct {
+	Bio         bio.BioService
+	UserService user.UserService
+	Audit       audit.AuditService
+}
+
+func NewBioHandler(bioService bio.BioService, userService user.UserService, auditService audit.AuditService) *BioHandler {
+	return &BioHandler{
+		Bio:         bioService,
+		UserService: userService,
+		Audit:       auditService,
+	}
+}
+
+// BioRouter serves /api/bio: GET returns the user's page, PUT creates or
+// replaces it with its items in order, and DELETE removes it.
+func (bh *BioHandler) BioRouter() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := authorizeUser(w, r, bh.UserService)
+		if !ok {
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			page, err := bh.Bio.GetPage(r.Context(), userID)
+			if writeBioError(w, err) {
+				return
+			}
+			utils.WriteJSON(w, http.StatusOK, page)
+		case http.MethodPut:
+			var req model.SaveBioPageRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				utils.WriteJSONError(w, http.StatusBadRequest, "Invalid request payload")
+				return
+			}
+			if msg := normalizeBioRequest(&req); msg != "" {
+				utils.WriteJSONError(w, http.StatusBadRequest, msg)
+				return
+			}
+			before, err := bh.Bio.GetPage(r.Context(), userID)
+			if err != nil && err != bio.ErrPageNotFound {
+				writeBioError(w, err)
+				return
+			}
+			page, err := bh.Bio.SavePage(r.Context(), userID, req)
+			if writeBioError(w, err) {
+				return
+			}
+			var beforeAudit any
+			if before.ID != "" {
+				beforeAudit = before
+			}
+			recordAudit(r, bh.Audit, userID, audit.ActionBioUpdate, audit.TargetBio, page.ID, beforeAudit, page)
+			utils.WriteJSON(w, http.StatusOK, page)
+		case http.MethodDelete:
+			page, err := bh.Bio.DeletePage(r.Context(), userID)
+			if writeBioError(w, err) {
+				return
+			}
+			recordAudit(r, bh.Audit, userID, audit.ActionBioDelete, audit.TargetBio, page.ID, page, nil)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			utils.WriteJSONError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+		}
+	}
+}
+
+// normalizeBioRequest validates req, lowercasing the handle and filling in
+// the default theme. It returns a message for the client, or "".
+func normalizeBioRequest(req *model.SaveBioPageRequest) string {
+	req.Handle = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(req.Handle), "@"))
+	req.Title = strings.TrimSpace(req.Title)
+	req.Description = strings.TrimSpace(req.Description)
+	req.AvatarURL = strings.TrimSpace(req.AvatarURL)
+	req.Theme = strings.ToLower(strings.TrimSpace(req.Theme))
+	if req.Theme == "" {
+		req.Theme = bio.DefaultTheme
+	}
+	switch {
+	case !bioHandlePattern.MatchString(req.Handle):
+		return "Handle must be 3-30 characters of letters, digits, '_' or '.'"
+	case len(req.Title) > maxBioTitleLength:
+		return fmt.Sprintf("Title must be at most %d bytes", maxBioTitleLength)
+	case len(req.Description) > maxBioDescriptionLength:
+		return fmt.Sprintf("Description must be at most %d bytes", maxBioDescriptionLength)
+	case req.AvatarURL != "" && !isImageURL(req.AvatarURL):
+		return "Invalid avatar URL"
+	case !slices.Contains(bio.Themes, req.Theme):
+		return fmt.Sprintf("Theme must be one of %s", strings.Join(bio.Themes, ", "))
+	case len(req.Items) > bio.MaxItems:
+		return bio.ErrTooManyItems.Error()
+	}
+	seen := make(map[string]bool, len(req.Items))
+	for i := range req.Items {
+		it := &req.Items[i]
+		it.ShortCode = ""
+		it.Title = strings.TrimSpace(it.Title)
+		it.Icon = strings.TrimSpace(it.Icon)
+		switch {
+		case !IsValidUUID(it.LinkID):
+			return fmt.Sprintf("Item %d: invalid or missing link_id", i+1)
+		case seen[it.LinkID]:
+			return fmt.Sprintf("Item %d: link is already listed", i+1)
+		case len(it.Title) > maxBioItemTitleLength:
+			return fmt.Sprintf("Item %d: title must be at most %d bytes", i+1, maxBioItemTitleLength)
+		case it.Icon != "" && !isImageURL(it.Icon):
+			return fmt.Sprintf("Item %d: invalid icon URL", i+1)
+		}
+		seen[it.LinkID] = true
+	}
+	return ""
+}
+
+// isImageURL reports whether s is an absolute http(s) URL a page can load.
+func isImageURL(s string) bool {
+	if len(s) > maxBioImageURLLength || !utils.IsValidURL(s) {
+		return false
+	}
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "https" || u.Scheme == "http") && u.Host != ""
+}
+
+func writeBioError(w http.ResponseWriter, err error) bool {
+	switch err {
+	case nil:
+		return false
+	case bio.ErrPageNotFound:
+		utils.WriteJSONError(w, http.StatusNotFound, "Bio page not found")
+	case link.ErrLinkNotFound:
+		utils.WriteJSONError(w, http.StatusNotFound, "Link not found")
+	case bio.ErrHandleTaken:
+		utils.WriteJSONError(w, http.StatusConflict, "Handle already taken")
+	default:
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to save bio page")
+	}
+	return true
+}
+
+// BioPageHandler serves bio pages at /@handle. It is mounted at the root, so
+// any other path that reaches it is a 404. Each item links to the short URL
+// marked ?src=bio, so visits go through the normal redirect and are counted
+// as bio clicks.
+func (bh *BioHandler) BioPageHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		handle, ok := strings.CutPrefix(strings.TrimSuffix(r.URL.Path, "/"), "/@")
+		if !ok || !bioHandlePattern.MatchString(strings.ToLower(handle)) {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		page, err := bh.Bio.PublicPage(r.Context(), handle)
+		if err == bio.ErrPageNotFound {
+			http.NotFound(w, r)
+			return
+		} else if err != nil {
+			logger.Error("BioPageHandler: failed to load @%s: %v", handle, err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		type item struct {
+			Title string
+			Icon  string
+			URL   string
+		}
+		items := make([]item, len(page.Items))
+		for i, it := range page.Items {
+			items[i] = item{
+				Title: it.Title,
+				Icon:  it.Icon,
+				URL:   "/go/" + url.PathEscape(it.ShortCode) + "?src=" + model.ClickSourceBio,
+			}
+		}
+		title := page.Title
+		if title == "" {
+			title = "@" + page.Handle
+		}
+		renderPage(w, http.StatusOK, "bio.html", struct {
+			Handle      string
+			Title       string
+			Description string
+			AvatarURL   string
+			Theme       string
+			Items       []item
+		}{page.Handle, title, page.Description, page.AvatarURL, page.Theme, items})
+	}
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+
+	"redo.ai/internal/model"
+	"redo.ai/internal/service/bio"
+)
+
+func TestBioHandlePattern(t *testing.T) {
+	for _, h := range []string{"abc", "jane.doe", "_under_", "a1b2c3", strings.Repeat("a", 30)} {
+		if !bioHandlePattern.MatchString(h) {
+			t.Errorf("handle %q rejected", h)
+		}
+	}
+	for _, h := range []string{"", "ab", strings.Repeat("a", 31), ".abc", "abc.", "Jane", "jane-doe", "jane doe", "@jane", "jane/doe"} {
+		if bioHandlePattern.MatchString(h) {
+			t.Errorf("handle %q accepted", h)
+		}
+	}
+}
+
+const testLinkID = "6f1c2b1e-3d4a-4b5c-8d9e-0f1a2b3c4d5e"
+
+func TestNormalizeBioRequest(t *testing.T) {
+	req := model.SaveBioPageRequest{
+		Handle:    "  @Jane.Doe ",
+		Title:     " Jane ",
+		AvatarURL: " https://cdn.example/me.png ",
+		Items:     []model.BioItem{{LinkID: testLinkID, ShortCode: "client-set", Title: " Shop "}},
+	}
+	if msg := normalizeBioRequest(&req); msg != "" {
+		t.Fatalf("normalizeBioRequest rejected a valid page: %s", msg)
+	}
+	if req.Handle != "jane.doe" || req.Title != "Jane" || req.AvatarURL != "https://cdn.example/me.png" {
+		t.Errorf("not normalized: %+v", req)
+	}
+	if req.Theme != bio.DefaultTheme {
+		t.Errorf("theme = %q, want default %q", req.Theme, bio.DefaultTheme)
+	}
+	if it := req.Items[0]; it.ShortCode != "" || it.Title != "Shop" {
+		t.Errorf("item not normalized: %+v", it)
+	}
+
+	tests := []struct {
+		name string
+		req  model.SaveBioPageRequest
+		want string
+	}{
+		{"bad handle", model.SaveBioPageRequest{Handle: "a"}, "Handle"},
+		{"long title", model.SaveBioPageRequest{Handle: "jane", Title: strings.Repeat("x", maxBioTitleLength+1)}, "Title"},
+		{"script avatar", model.SaveBioPageRequest{Handle: "jane", AvatarURL: "javascript:alert(1)"}, "avatar"},
+		{"unknown theme", model.SaveBioPageRequest{Handle: "jane", Theme: "neon"}, "Theme"},
+		{"bad link id", model.SaveBioPageRequest{Handle: "jane", Items: []model.BioItem{{LinkID: "nope"}}}, "Item 1: invalid"},
+		{"duplicate link", model.SaveBioPageRequest{Handle: "jane", Items: []model.BioItem{{LinkID: testLinkID}, {LinkID: testLinkID}}}, "Item 2: link is already listed"},
+		{"data icon", model.SaveBioPageRequest{Handle: "jane", Items: []model.BioItem{{LinkID: testLinkID, Icon: "data:image/png;base64,AA"}}}, "Item 1: invalid icon"},
+		{"too many items", model.SaveBioPageRequest{Handle: "jane", Items: make([]model.BioItem, bio.MaxItems+1)}, bio.ErrTooManyItems.Error()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if msg := normalizeBioRequest(&tt.req); !strings.Contains(msg, tt.want) {
+				t.Errorf("message = %q, want it to contain %q", msg, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,529 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"redo.ai/internal/model"
+	"redo.ai/internal/pkg/entitlements"
+	"redo.ai/internal/service/audit"
+	"redo.ai/internal/service/bulk"
+	"redo.ai/internal/service/link"
+	"redo.ai/internal/utils"
+	"redo.ai/logger"
+)
+
+const (
+	// bulkAsyncThreshold is the row count above which a bulk request runs as
+	// a background job even without ?async=true.
+	bulkAsyncThreshold = 500
+	maxBulkBodyBytes   = 10 << 20
+)
+
+// BulkLinksHandler serves /api/links/bulk. POST creates, PATCH updates and
+// DELETE deletes links. The body is a JSON array or a CSV file (text/csv, or
+// multipart/form-data with a "file" field) with a header row; CSV tags are
+// separated by "|". ?mode=atomic rolls back the whole batch on any row error,
+// the default partial mode keeps the rows that succeeded. Large batches, or
+// ?async=true, return 202 with a job to poll at /api/links/bulk/jobs?id=.
+func (lh *LinkHandler) BulkLinksHandler(w http.ResponseWriter, r *http.Request, userID string) {
+	if !requireFeature(w, r, lh.UserService, userID, entitlements.FeatureBulkImport) {
+		return
+	}
+	atomic, async, ok := parseBulkOptions(w, r)
+	if !ok {
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxBulkBodyBytes)
+
+	var (
+		op      string
+		payload bulkJobPayload
+		err     error
+	)
+	switch r.Method {
+	case http.MethodPost:
+		var items []model.BulkCreateItem
+		items, err = decodeBulkCreate(r)
+		op, payload.Total = bulk.OpCreate, len(items)
+		payload.Create, payload.Invalid = validateBulkCreate(items)
+	case http.MethodPatch:
+		var items []model.BulkUpdateItem
+		items, err = decodeBulkUpdate(r)
+		op, payload.Total = bulk.OpUpdate, len(items)
+		payload.Update, payload.Invalid = validateBulkUpdate(items)
+	case http.MethodDelete:
+		var items []model.BulkDeleteItem
+		items, err = decodeBulkDelete(r)
+		op, payload.Total = bulk.OpDelete, len(items)
+		payload.Delete, payload.Invalid = validateBulkDelete(items)
+	default:
+		utils.WriteJSONError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+		return
+	}
+	if err != nil {
+		writeErrorMessage(w, http.StatusBadRequest, "Invalid bulk payload: "+err.Error())
+		return
+	}
+	total, invalid := payload.Total, payload.Invalid
+	if total == 0 {
+		utils.WriteJSONError(w, http.StatusBadRequest, "No rows to process")
+		return
+	}
+	if total > link.MaxBulkRows {
+		utils.WriteJSONError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("At most %d rows per request", link.MaxBulkRows))
+		return
+	}
+
+	// In atomic mode a single invalid row fails the batch before touching
+	// the database.
+	if len(invalid) > 0 && (atomic || len(invalid) == total) {
+		utils.WriteJSON(w, http.StatusUnprocessableEntity, mergeBulkResult(model.BulkResult{}, invalid, total))
+		return
+	}
+
+	origin := auditEventFromRequest(r, userID)
+	if async || total > bulkAsyncThreshold {
+		if lh.Bulk == nil {
+			utils.WriteJSONError(w, http.StatusServiceUnavailable, "Background jobs are unavailable")
+			return
+		}
+		payload.Rows = payload.rows()
+		job, err := lh.Bulk.Submit(r.Context(), userID, op, atomic, total, payload, origin)
+		if err != nil {
+			utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to start bulk job")
+			return
+		}
+		w.Header().Set("Location", "/api/links/bulk/jobs?id="+job.ID)
+		utils.WriteJSON(w, http.StatusAccepted, job)
+		return
+	}
+
+	result, err := lh.runBulk(r.Context(), userID, op, atomic, payload, origin)
+	if err != nil {
+		if writeEntitlementError(w, err) {
+			return
+		}
+		logger.Error("BulkLinksHandler: %s failed for userID=%s: %v", op, userID, err)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Bulk operation failed")
+		return
+	}
+	status := http.StatusOK
+	if !result.Committed {
+		status = http.StatusUnprocessableEntity
+	}
+	utils.WriteJSON(w, status, result)
+}
+
+// bulkJobPayload holds the validated rows of a bulk request so a background
+// job can run it later. Item row numbers are not part of their JSON, so Rows
+// carries them in item order.
+type bulkJobPayload struct {
+	Total   int                    `json:"total"`
+	Rows    []int                  `json:"rows,omitempty"`
+	Create  []model.BulkCreateItem `json:"create,omitempty"`
+	Update  []model.BulkUpdateItem `json:"update,omitempty"`
+	Delete  []model.BulkDeleteItem `json:"delete,omitempty"`
+	Invalid []model.BulkRowResult  `json:"invalid,omitempty"`
+}
+
+func (p *bulkJobPayload) rows() []int {
+	rows := make([]int, 0, len(p.Create)+len(p.Update)+len(p.Delete))
+	for _, it := range p.Create {
+		rows = append(rows, it.Row)
+	}
+	for _, it := range p.Update {
+		rows = append(rows, it.Row)
+	}
+	for _, it := range p.Delete {
+		rows = append(rows, it.Row)
+	}
+	return rows
+}
+
+func (p *bulkJobPayload) restoreRows() error {
+	if len(p.Rows) != len(p.Create)+len(p.Update)+len(p.Delete) {
+		return errors.New("row numbers do not match the items")
+	}
+	i := 0
+	for j := range p.Create {
+		p.Create[j].Row = p.Rows[i]
+		i++
+	}
+	for j := range p.Update {
+		p.Update[j].Row = p.Rows[i]
+		i++
+	}
+	for j := range p.Delete {
+		p.Delete[j].Row = p.Rows[i]
+		i++
+	}
+	return nil
+}
+
+// runBulk applies a bulk payload, audits the committed rows and folds the
+// rows rejected by validation into the result.
+func (lh *LinkHandler) runBulk(ctx context.Context, userID, op string, atomic bool, p bulkJobPayload, origin model.AuditEvent) (model.BulkResult, error) {
+	var (
+		result model.BulkResult
+		err    error
+	)
+	switch op {
+	case bulk.OpCreate:
+		result, err = lh.LinkService.BulkCreateLinks(ctx, userID, p.Create, atomic)
+	case bulk.OpUpdate:
+		result, err = lh.LinkService.BulkUpdateLinks(ctx, userID, p.Update, atomic)
+	case bulk.OpDelete:
+		result, err = lh.LinkService.BulkDeleteLinks(ctx, userID, p.Delete, atomic)
+	default:
+		return model.BulkResult{}, fmt.Errorf("unknown bulk operation %q", op)
+	}
+	if err != nil {
+		return result, err
+	}
+	recordBulkAudit(ctx, lh.Audit, origin, bulkAuditActions[op], result)
+	return mergeBulkResult(result, p.Invalid, p.Total), nil
+}
+
+// RunBulkJob runs a background job submitted by BulkLinksHandler.
+func (lh *LinkHandler) RunBulkJob(ctx context.Context, job bulk.Job) (model.BulkResult, error) {
+	var p bulkJobPayload
+	if err := json.Unmarshal(job.Payload, &p); err != nil {
+		return model.BulkResult{}, fmt.Errorf("decode job payload failed: %w", err)
+	}
+	if err := p.restoreRows(); err != nil {
+		return model.BulkResult{}, err
+	}
+	return lh.runBulk(ctx, job.UserID, job.Operation, job.Atomic, p, job.Origin)
+}
+
+// BulkJobHandler reports the status of a background bulk job.
+func (lh *LinkHandler) BulkJobHandler(w http.ResponseWriter, r *http.Request, userID string) {
+	jobID := r.URL.Query().Get("id")
+	if !IsValidUUID(jobID) {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid or missing job ID")
+		return
+	}
+	if lh.Bulk == nil {
+		utils.WriteJSONError(w, http.StatusNotFound, "Job not found")
+		return
+	}
+	job, err := lh.Bulk.GetJob(r.Context(), userID, jobID)
+	if err == bulk.ErrJobNotFound {
+		utils.WriteJSONError(w, http.StatusNotFound, "Job not found")
+		return
+	} else if err != nil {
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to fetch job")
+		return
+	}
+	utils.WriteJSON(w, http.StatusOK, job)
+}
+
+func parseBulkOptions(w http.ResponseWriter, r *http.Request) (atomic, async bool, ok bool) {
+	params := r.URL.Query()
+	switch strings.ToLower(params.Get("mode")) {
+	case "", "partial":
+	case "atomic":
+		atomic = true
+	default:
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid mode, expected atomic or partial")
+		return false, false, false
+	}
+	if raw := params.Get("async"); raw != "" {
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			utils.WriteJSONError(w, http.StatusBadRequest, "Invalid async flag")
+			return false, false, false
+		}
+		async = v
+	}
+	return atomic, async, true
+}
+
+// mergeBulkResult folds rows rejected by validation into the service result,
+// ordered by row.
+func mergeBulkResult(result model.BulkResult, invalid []model.BulkRowResult, total int) model.BulkResult {
+	result.Total = total
+	result.Failed += len(invalid)
+	result.Rows = append(result.Rows, invalid...)
+	sort.SliceStable(result.Rows, func(i, j int) bool { return result.Rows[i].Row < result.Rows[j].Row })
+	return result
+}
+
+func invalidRow(row int, id, msg string) model.BulkRowResult {
+	return model.BulkRowResult{Row: row, LinkID: id, Status: model.BulkRowFailed, Error: msg}
+}
+
+func validateBulkCreate(items []model.BulkCreateItem) ([]model.BulkCreateItem, []model.BulkRowResult) {
+	valid := items[:0:0]
+	var invalid []model.BulkRowResult
+	for _, it := range items {
+		switch {
+		case !utils.IsValidURL(it.Destination):
+			invalid = append(invalid, invalidRow(it.Row, "", "invalid destination"))
+		case it.Slug != "" && !utils.IsValidSlug(it.Slug):
+			invalid = append(invalid, invalidRow(it.Row, "", "invalid slug"))
+		case it.CampaignID != "" && !IsValidUUID(it.CampaignID):
+			invalid = append(invalid, invalidRow(it.Row, "", "invalid campaign_id"))
+		case it.Password != "" && !isValidLinkPassword(it.Password):
+			invalid = append(invalid, invalidRow(it.Row, "", "invalid password"))
+		case validatePreview(it.Preview) != "":
+			invalid = append(invalid, invalidRow(it.Row, "", "invalid preview"))
+		default:
+			valid = append(valid, it)
+		}
+	}
+	return valid, invalid
+}
+
+func validateBulkUpdate(items []model.BulkUpdateItem) ([]model.BulkUpdateItem, []model.BulkRowResult) {
+	valid := items[:0:0]
+	var invalid []model.BulkRowResult
+	for _, it := range items {
+		switch {
+		case !IsValidUUID(it.ID):
+			invalid = append(invalid, invalidRow(it.Row, "", "invalid id"))
+		case it.Destination != nil && !utils.IsValidURL(*it.Destination):
+			invalid = append(invalid, invalidRow(it.Row, it.ID, "invalid destination"))
+		case it.Slug != nil && !utils.IsValidSlug(*it.Slug):
+			invalid = append(invalid, invalidRow(it.Row, it.ID, "invalid slug"))
+		case it.CampaignID != nil && *it.CampaignID != "" && !IsValidUUID(*it.CampaignID):
+			invalid = append(invalid, invalidRow(it.Row, it.ID, "invalid campaign_id"))
+		case it.Password != nil && *it.Password != "" && !isValidLinkPassword(*it.Password):
+			invalid = append(invalid, invalidRow(it.Row, it.ID, "invalid password"))
+		case validatePreview(it.Preview) != "":
+			invalid = append(invalid, invalidRow(it.Row, it.ID, "invalid preview"))
+		default:
+			valid = append(valid, it)
+		}
+	}
+	return valid, invalid
+}
+
+func validateBulkDelete(items []model.BulkDeleteItem) ([]model.BulkDeleteItem, []model.BulkRowResult) {
+	valid := items[:0:0]
+	var invalid []model.BulkRowResult
+	for _, it := range items {
+		if !IsValidUUID(it.ID) {
+			invalid = append(invalid, invalidRow(it.Row, "", "invalid id"))
+			continue
+		}
+		valid = append(valid, it)
+	}
+	return valid, invalid
+}
+
+func decodeBulkCreate(r *http.Request) ([]model.BulkCreateItem, error) {
+	var items []model.BulkCreateItem
+	records, isCSV, err := readBulkBody(r, &items)
+	if err != nil {
+		return nil, err
+	}
+	if isCSV {
+		if err := requireColumns(records, "destination"); err != nil {
+			return nil, err
+		}
+		for i, rec := range records {
+			items = append(items, model.BulkCreateItem{Row: i + 1, CreateLinkRequest: model.CreateLinkRequest{
+				Slug:        rec["slug"],
+				Destination: rec["destination"],
+				Title:       rec["title"],
+				Tags:        splitTags(rec["tags"]),
+				CampaignID:  rec["campaign_id"],
+			}})
+		}
+		return items, nil
+	}
+	for i := range items {
+		items[i].Row = i + 1
+	}
+	return items, nil
+}
+
+// decodeBulkUpdate treats empty CSV cells as "leave unchanged".
+func decodeBulkUpdate(r *http.Request) ([]model.BulkUpdateItem, error) {
+	var items []model.BulkUpdateItem
+	records, isCSV, err := readBulkBody(r, &items)
+	if err != nil {
+		return nil, err
+	}
+	if isCSV {
+		if err := requireColumns(records, "id"); err != nil {
+			return nil, err
+		}
+		for i, rec := range records {
+			it := model.BulkUpdateItem{Row: i + 1, ID: rec["id"]}
+			it.Slug = optional(rec, "slug")
+			it.Destination = optional(rec, "destination")
+			it.Title = optional(rec, "title")
+			it.CampaignID = optional(rec, "campaign_id")
+			if v := optional(rec, "tags"); v != nil {
+				tags := splitTags(*v)
+				it.Tags = &tags
+			}
+			if v := optional(rec, "is_active"); v != nil {
+				active, err := strconv.ParseBool(*v)
+				if err != nil {
+					return nil, fmt.Errorf("row %d: invalid is_active", i+1)
+				}
+				it.IsActive = &active
+			}
+			items = append(items, it)
+		}
+		return items, nil
+	}
+	for i := range items {
+		items[i].Row = i + 1
+	}
+	return items, nil
+}
+
+func decodeBulkDelete(r *http.Request) ([]model.BulkDeleteItem, error) {
+	var items []model.BulkDeleteItem
+	records, isCSV, err := readBulkBody(r, &items)
+	if err != nil {
+		return nil, err
+	}
+	if isCSV {
+		if err := requireColumns(records, "id"); err != nil {
+			return nil, err
+		}
+		for i, rec := range records {
+			items = append(items, model.BulkDeleteItem{Row: i + 1, ID: rec["id"]})
+		}
+		return items, nil
+	}
+	for i := range items {
+		items[i].Row = i + 1
+	}
+	return items, nil
+}
+
+// readBulkBody decodes a JSON array into jsonDst, or returns the CSV records
+// keyed by lower-cased header when the upload is CSV.
+func readBulkBody(r *http.Request, jsonDst any) ([]map[string]string, bool, error) {
+	upload, isCSV, err := csvUpload(r)
+	if err != nil {
+		return nil, isCSV, err
+	}
+	if isCSV {
+		defer upload.Close()
+		records, err := readCSV(upload)
+		return records, true, err
+	}
+	if err := json.NewDecoder(r.Body).Decode(jsonDst); err != nil {
+		return nil, false, errors.New("body must be a JSON array")
+	}
+	return nil, false, nil
+}
+
+// csvUpload returns the CSV sent as the raw body (text/csv) or as the "file"
+// field of a multipart form. isCSV is false for any other content type.
+func csvUpload(r *http.Request) (upload io.ReadCloser, isCSV bool, err error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "text/csv", "application/csv":
+		return r.Body, true, nil
+	case "multipart/form-data":
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			return nil, true, errors.New("missing CSV file")
+		}
+		return file, true, nil
+	default:
+		return nil, false, nil
+	}
+}
+
+func readCSV(body io.Reader) ([]map[string]string, error) {
+	reader := csv.NewReader(body)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.New("invalid CSV header")
+	}
+	for i := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(header[i]))
+	}
+	reader.FieldsPerRecord = len(header)
+
+	var records []map[string]string
+	for {
+		fields, err := reader.Read()
+		if err == io.EOF {
+			return records, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %v", err)
+		}
+		if len(records) == link.MaxBulkRows {
+			return nil, fmt.Errorf("more than %d rows", link.MaxBulkRows)
+		}
+		rec := make(map[string]string, len(header))
+		for i, name := range header {
+			rec[name] = strings.TrimSpace(fields[i])
+		}
+		records = append(records, rec)
+	}
+}
+
+func requireColumns(records []map[string]string, columns ...string) error {
+	if len(records) == 0 {
+		return nil
+	}
+	for _, c := range columns {
+		if _, ok := records[0][c]; !ok {
+			return fmt.Errorf("CSV is missing the %s column", c)
+		}
+	}
+	return nil
+}
+
+func optional(rec map[string]string, column string) *string {
+	if v, ok := rec[column]; ok && v != "" {
+		return &v
+	}
+	return nil
+}
+
+func splitTags(raw string) []string {
+	if raw == "" {
+		return nil
+	}
+	return strings.Split(raw, "|")
+}
+
+var bulkAuditActions = map[string]string{
+	bulk.OpCreate: audit.ActionLinkCreate,
+	bulk.OpUpdate: audit.ActionLinkUpdate,
+	bulk.OpDelete: audit.ActionLinkDelete,
+}
+
+// recordBulkAudit records one event per committed row. base carries the
+// actor and request details captured when the request was made, since a
+// background job runs after it has ended.
+func recordBulkAudit(ctx context.Context, svc audit.AuditService, base model.AuditEvent, action string, result model.BulkResult) {
+	if svc == nil || !result.Committed {
+		return
+	}
+	for _, row := range result.Rows {
+		if row.Status != model.BulkRowOK {
+			continue
+		}
+		ev := base
+		ev.Action = action
+		ev.TargetType = audit.TargetLink
+		ev.TargetID = row.LinkID
+		_ = svc.Record(ctx, ev)
+	}
+}
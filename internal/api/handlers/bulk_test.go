@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"redo.ai/internal/model"
+	"redo.ai/internal/service/user"
+)
+
+func TestReadCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    []map[string]string
+		wantErr bool
+	}{
+		{name: "Empty body", body: "", want: nil},
+		{name: "Header only", body: "destination,slug\n", want: nil},
+		{
+			name: "Header is normalized and cells trimmed",
+			body: " Destination , SLUG\nhttps://a.example ,  one\nhttps://b.example,two\n",
+			want: []map[string]string{
+				{"destination": "https://a.example", "slug": "one"},
+				{"destination": "https://b.example", "slug": "two"},
+			},
+		},
+		{
+			name: "Quoted fields",
+			body: "destination,title\n\"https://a.example/?q=1,2\",\"Hello, world\"\n",
+			want: []map[string]string{{"destination": "https://a.example/?q=1,2", "title": "Hello, world"}},
+		},
+		{name: "Short row", body: "destination,slug\nhttps://a.example\n", wantErr: true},
+		{name: "Long row", body: "destination\nhttps://a.example,extra\n", wantErr: true},
+		{name: "Bare quote", body: "destination\nhttps://a\"example\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readCSV(strings.NewReader(tt.body))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateBulkCreate(t *testing.T) {
+	item := func(row int, dest, slug, campaign string) model.BulkCreateItem {
+		return model.BulkCreateItem{Row: row, CreateLinkRequest: model.CreateLinkRequest{
+			Destination: dest, Slug: slug, CampaignID: campaign,
+		}}
+	}
+	items := []model.BulkCreateItem{
+		item(1, "https://example.com", "", ""),
+		item(2, "not a url", "", ""),
+		item(3, "https://example.com", "bad slug!", ""),
+		item(4, "https://example.com", "", "not-a-uuid"),
+		item(5, "https://example.com", "ok-slug", "3f1c2e4a-8b7d-4c6e-9a1b-2d3e4f5a6b7c"),
+	}
+	valid, invalid := validateBulkCreate(items)
+
+	if got := rowsOf(valid); !reflect.DeepEqual(got, []int{1, 5}) {
+		t.Errorf("valid rows = %v, want [1 5]", got)
+	}
+	want := []model.BulkRowResult{
+		invalidRow(2, "", "invalid destination"),
+		invalidRow(3, "", "invalid slug"),
+		invalidRow(4, "", "invalid campaign_id"),
+	}
+	if !reflect.DeepEqual(invalid, want) {
+		t.Errorf("invalid = %+v, want %+v", invalid, want)
+	}
+	if &valid[0] == &items[0] {
+		t.Error("validateBulkCreate must not reuse the input's backing array")
+	}
+}
+
+func TestValidateBulkUpdate(t *testing.T) {
+	const id = "3f1c2e4a-8b7d-4c6e-9a1b-2d3e4f5a6b7c"
+	str := func(s string) *string { return &s }
+	items := []model.BulkUpdateItem{
+		{Row: 1, ID: id},
+		{Row: 2, ID: "nope"},
+		{Row: 3, ID: id, UpdateLinkRequest: model.UpdateLinkRequest{Destination: str("not a url")}},
+		{Row: 4, ID: id, UpdateLinkRequest: model.UpdateLinkRequest{Slug: str("")}},
+		{Row: 5, ID: id, UpdateLinkRequest: model.UpdateLinkRequest{CampaignID: str("")}},
+		{Row: 6, ID: id, UpdateLinkRequest: model.UpdateLinkRequest{CampaignID: str("x")}},
+	}
+	valid, invalid := validateBulkUpdate(items)
+
+	if got := updateRowsOf(valid); !reflect.DeepEqual(got, []int{1, 5}) {
+		t.Errorf("valid rows = %v, want [1 5]", got)
+	}
+	want := []model.BulkRowResult{
+		invalidRow(2, "", "invalid id"),
+		invalidRow(3, id, "invalid destination"),
+		invalidRow(4, id, "invalid slug"),
+		invalidRow(6, id, "invalid campaign_id"),
+	}
+	if !reflect.DeepEqual(invalid, want) {
+		t.Errorf("invalid = %+v, want %+v", invalid, want)
+	}
+}
+
+func TestBulkJobPayloadRoundTrip(t *testing.T) {
+	in := bulkJobPayload{
+		Total: 3,
+		Update: []model.BulkUpdateItem{
+			{Row: 1, ID: "a"},
+			{Row: 3, ID: "b"},
+		},
+		Invalid: []model.BulkRowResult{invalidRow(2, "", "invalid id")},
+	}
+	in.Rows = in.rows()
+	raw, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bulkJobPayload
+	if err := json.Unmarshal(raw, &out); err != nil {
+		t.Fatal(err)
+	}
+	if err := out.restoreRows(); err != nil {
+		t.Fatal(err)
+	}
+	if got := updateRowsOf(out.Update); !reflect.DeepEqual(got, []int{1, 3}) {
+		t.Errorf("restored rows = %v, want [1 3]", got)
+	}
+
+	out.Rows = out.Rows[:1]
+	if err := out.restoreRows(); err == nil {
+		t.Error("restoreRows accepted mismatched row numbers")
+	}
+}
+
+func rowsOf(items []model.BulkCreateItem) []int {
+	var rows []int
+	for _, it := range items {
+		rows = append(rows, it.Row)
+	}
+	return rows
+}
+
+func updateRowsOf(items []model.BulkUpdateItem) []int {
+	var rows []int
+	for _, it := range items {
+		rows = append(rows, it.Row)
+	}
+	return rows
+}
+
+// planUsers answers the user lookups of authorizeUser and requireFeature;
+// other UserService methods are not used by the handlers under test.
+type planUsers struct {
+	user.UserService
+	role string
+}
+
+func (p planUsers) GetByUserID(ctx context.Context, userID string) (*model.User, error) {
+	return &model.User{UserID: userID, Role: p.role}, nil
+}
+
+func (p planUsers) UserExists(ctx context.Context, userID string) (bool, error) {
+	return true, nil
+}
+
+// decodeErrorBody fails the test unless body is a JSON error object, and
+// returns its message.
+func decodeErrorBody(t *testing.T, body []byte) string {
+	t.Helper()
+	var resp struct {
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatalf("error body is not valid JSON: %v\n%s", err, body)
+	}
+	return resp.Error
+}
+
+func TestBulkLinksHandlerEscapesParseErrors(t *testing.T) {
+	lh := &LinkHandler{UserService: planUsers{role: "pro"}}
+	req := httptest.NewRequest(http.MethodPost, "/api/links/bulk", strings.NewReader("destination,slug\nhttps://a.example,a\"b\n"))
+	req.Header.Set("Content-Type", "text/csv")
+	rec := httptest.NewRecorder()
+	lh.BulkLinksHandler(rec, req, "u1")
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", rec.Code)
+	}
+	if msg := decodeErrorBody(t, rec.Body.Bytes()); !strings.Contains(msg, `bare "`) {
+		t.Errorf("message = %q, want the CSV parser's error", msg)
+	}
+}
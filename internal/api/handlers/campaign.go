@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"redo.ai/internal/model"
+	"redo.ai/internal/pkg/entitlements"
+	"redo.ai/internal/service/campaign"
+	"redo.ai/internal/service/user"
+	"redo.ai/internal/utils"
+	"redo.ai/logger"
+)
+
+type CampaignHandler struct {
+	CampaignService campaign.CampaignService
+	UserService     user.UserService
+}
+
+func NewCampaignHandler(campaignService campaign.CampaignService, userService user.UserService) *CampaignHandler {
+	return &CampaignHandler{
+		CampaignService: campaignService,
+		UserService:     userService,
+	}
+}
+
+// CampaignsRouter serves /api/campaigns (CRUD, ?id= selects one campaign)
+// and GET /api/campaigns/analytics?id= for aggregated click analytics.
+// Links join a campaign through campaign_id on link create/update.
+func (ch *CampaignHandler) CampaignsRouter() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := authorizeUser(w, r, ch.UserService)
+		if !ok {
+			return
+		}
+		campaignID := r.URL.Query().Get("id")
+
+		switch strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/campaigns"), "/") {
+		case "":
+		case "analytics":
+			if validateMethod(w, r, http.MethodGet) {
+				ch.analytics(w, r, userID, campaignID)
+			}
+			return
+		default:
+			utils.WriteJSONError(w, http.StatusNotFound, "Not Found")
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			if campaignID == "" {
+				list, err := ch.CampaignService.ListCampaigns(r.Context(), userID)
+				if err != nil {
+					utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to fetch campaigns")
+					return
+				}
+				utils.WriteJSON(w, http.StatusOK, list)
+				return
+			}
+			if !requireCampaignID(w, campaignID) {
+				return
+			}
+			c, err := ch.CampaignService.GetCampaign(r.Context(), userID, campaignID)
+			if writeCampaignError(w, err) {
+				return
+			}
+			utils.WriteJSON(w, http.StatusOK, c)
+		case http.MethodPost:
+			var req model.CampaignRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				utils.WriteJSONError(w, http.StatusBadRequest, "Invalid request payload")
+				return
+			}
+			c, err := ch.CampaignService.CreateCampaign(r.Context(), userID, req)
+			if writeCampaignError(w, err) {
+				return
+			}
+			utils.WriteJSON(w, http.StatusCreated, c)
+		case http.MethodPut:
+			if !requireCampaignID(w, campaignID) {
+				return
+			}
+			var req model.CampaignRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				utils.WriteJSONError(w, http.StatusBadRequest, "Invalid request payload")
+				return
+			}
+			c, err := ch.CampaignService.UpdateCampaign(r.Context(), userID, campaignID, req)
+			if writeCampaignError(w, err) {
+				return
+			}
+			utils.WriteJSON(w, http.StatusOK, c)
+		case http.MethodDelete:
+			if !requireCampaignID(w, campaignID) {
+				return
+			}
+			if writeCampaignError(w, ch.CampaignService.DeleteCampaign(r.Context(), userID, campaignID)) {
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			utils.WriteJSONError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+		}
+	}
+}
+
+func (ch *CampaignHandler) analytics(w http.ResponseWriter, r *http.Request, userID, campaignID string) {
+	if !requireCampaignID(w, campaignID) {
+		return
+	}
+	usr, err := ch.UserService.GetByUserID(r.Context(), userID)
+	if err != nil {
+		logger.Error("campaign analytics: failed to fetch user: %v", err)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to verify user")
+		return
+	}
+	ent := entitlements.For(usr.Role)
+	if writeEntitlementError(w, ent.RequireFeature(entitlements.FeatureAnalytics)) {
+		return
+	}
+	result, err := ch.CampaignService.CampaignAnalytics(r.Context(), userID, campaignID, ent.AnalyticsSince(time.Now().UTC()))
+	if writeCampaignError(w, err) {
+		return
+	}
+	utils.WriteJSON(w, http.StatusOK, result)
+}
+
+func requireCampaignID(w http.ResponseWriter, id string) bool {
+	if !IsValidUUID(id) {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid or missing campaign ID")
+		return false
+	}
+	return true
+}
+
+func writeCampaignError(w http.ResponseWriter, err error) bool {
+	switch err {
+	case nil:
+		return false
+	case campaign.ErrInvalidCampaign:
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid campaign name")
+	case campaign.ErrCampaignExists:
+		utils.WriteJSONError(w, http.StatusConflict, "Campaign already exists")
+	case campaign.ErrCampaignNotFound:
+		utils.WriteJSONError(w, http.StatusNotFound, "Campaign not found")
+	default:
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Campaign request failed")
+	}
+	return true
+}
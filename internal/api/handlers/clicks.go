@@ -4,8 +4,10 @@ package handlers
 import (
 	"net/http"
 	"strings"
+	"time"
 
 	"redo.ai/internal/model"
+	"redo.ai/internal/pkg/entitlements"
 	"redo.ai/internal/service/clicks"
 	"redo.ai/internal/service/user"
 	"redo.ai/internal/utils"
@@ -38,7 +40,7 @@ func (h *ClickHandler) ClicksRouter() http.HandlerFunc {
 			utils.WriteJSONError(w, http.StatusUnauthorized, "Invalid user")
 			return
 		}
-		usr, err := h.UserService.GetByID(r.Context(), userID)
+		usr, err := h.UserService.GetByUserID(r.Context(), userID)
 		if err != nil {
 			logger.Error("ClicksRouter: failed to fetch user: %v", err)
 			utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to verify user")
@@ -52,6 +54,12 @@ func (h *ClickHandler) ClicksRouter() http.HandlerFunc {
 			h.handleGroupedClicks(w, r, usr.UserID, usr.Role, "country")
 		case "by-device":
 			h.handleGroupedClicks(w, r, usr.UserID, usr.Role, "device")
+		case "by-source":
+			h.handleGroupedClicks(w, r, usr.UserID, usr.Role, "source")
+		case "conversions-by-link":
+			h.handleConversionsByLink(w, r, usr.UserID, usr.Role)
+		case "conversions-per-day":
+			h.handleConversionsPerDay(w, r, usr.UserID, usr.Role)
 		default:
 			utils.WriteJSONError(w, http.StatusNotFound, "Unknown analytics view")
 		}
@@ -59,8 +67,7 @@ func (h *ClickHandler) ClicksRouter() http.HandlerFunc {
 }
 
 func (h *ClickHandler) handleClicksPerDay(w http.ResponseWriter, r *http.Request, userID string, plan string) {
-	if plan == "free" {
-		utils.WriteJSONError(w, http.StatusForbidden, "Upgrade to access analytics")
+	if writeEntitlementError(w, entitlements.For(plan).RequireFeature(entitlements.FeatureAnalytics)) {
 		return
 	}
 	results, err := h.ClickService.ClicksPerDay(r.Context(), userID)
@@ -72,20 +79,60 @@ func (h *ClickHandler) handleClicksPerDay(w http.ResponseWriter, r *http.Request
 	utils.WriteJSON(w, http.StatusOK, results)
 }
 
+// handleConversionsByLink reports the conversion rate and revenue of each
+// link within the plan's analytics window.
+func (h *ClickHandler) handleConversionsByLink(w http.ResponseWriter, r *http.Request, userID, plan string) {
+	ent := entitlements.For(plan)
+	if writeEntitlementError(w, ent.RequireFeature(entitlements.FeatureAnalytics)) {
+		return
+	}
+	results, err := h.ClickService.ConversionsByLink(r.Context(), userID, ent.AnalyticsSince(time.Now().UTC()))
+	if err != nil {
+		logger.Error("handleConversionsByLink: error: %v", err)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to load conversions")
+		return
+	}
+	utils.WriteJSON(w, http.StatusOK, results)
+}
+
+// handleConversionsPerDay reports the daily conversion rate and revenue,
+// optionally for one link with ?link_id=.
+func (h *ClickHandler) handleConversionsPerDay(w http.ResponseWriter, r *http.Request, userID, plan string) {
+	ent := entitlements.For(plan)
+	if writeEntitlementError(w, ent.RequireFeature(entitlements.FeatureAnalytics)) {
+		return
+	}
+	linkID := r.URL.Query().Get("link_id")
+	if linkID != "" && !IsValidUUID(linkID) {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid link_id")
+		return
+	}
+	results, err := h.ClickService.ConversionsPerDay(r.Context(), userID, linkID, ent.AnalyticsSince(time.Now().UTC()))
+	if err != nil {
+		logger.Error("handleConversionsPerDay: error: %v", err)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to load conversions")
+		return
+	}
+	utils.WriteJSON(w, http.StatusOK, results)
+}
+
 func (h *ClickHandler) handleGroupedClicks(w http.ResponseWriter, r *http.Request, userID, plan, groupBy string) {
-	if plan == "free" {
-		utils.WriteJSONError(w, http.StatusForbidden, "Upgrade to access analytics")
+	ent := entitlements.For(plan)
+	if writeEntitlementError(w, ent.RequireFeature(entitlements.FeatureAnalytics)) {
 		return
 	}
+	since := ent.AnalyticsSince(time.Now().UTC())
 	var (
 		results []model.GroupedMetric
 		err     error
 	)
 	switch groupBy {
 	case "country":
-		results, err = h.ClickService.GetClicksGroupedByCountry(r.Context(), userID)
+		results, err = h.ClickService.GetClicksGroupedByCountry(r.Context(), userID, since)
 	case "device":
-		results, err = h.ClickService.GetClicksGroupedByDevice(r.Context(), userID)
+		results, err = h.ClickService.GetClicksGroupedByDevice(r.Context(), userID, since)
+	case "source":
+		results, err = h.ClickService.GetClicksGroupedBySource(r.Context(), userID, since)
 	default:
 		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid grouping")
 		return
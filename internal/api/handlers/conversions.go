@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"redo.ai/internal/model"
+	"redo.ai/internal/pkg/signer"
+	"redo.ai/internal/service/clicks"
+	"redo.ai/internal/service/user"
+	"redo.ai/internal/utils"
+	"redo.ai/logger"
+)
+
+// ClickIDParam is the query parameter that carries the signed click ID to
+// destinations of links with click IDs enabled.
+const ClickIDParam = "redo_cid"
+
+// ConversionSignatureHeader carries the signature of a conversion report
+// body: the base64url HMAC-SHA256 of the exact body, keyed by the link
+// owner's conversion secret.
+const ConversionSignatureHeader = "X-Redo-Signature"
+
+// maxConversionValue bounds reported revenue, in currency units.
+const maxConversionValue = 1e9
+
+const maxConversionBody = 4 << 10
+
+// transparentGIF is a 1x1 transparent GIF served by the conversion pixel.
+var transparentGIF = []byte{
+	0x47, 0x49, 0x46, 0x38, 0x39, 0x61, 0x01, 0x00, 0x01, 0x00, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0xff, 0xff, 0xff, 0x21, 0xf9, 0x04, 0x01, 0x00, 0x00, 0x00, 0x00, 0x2c, 0x00, 0x00, 0x00, 0x00,
+	0x01, 0x00, 0x01, 0x00, 0x00, 0x02, 0x02, 0x44, 0x01, 0x00, 0x3b,
+}
+
+type ConversionHandler struct {
+	ClickService clicks.ClickService
+	UserService  user.UserService
+	Signer       *signer.Signer
+}
+
+func NewConversionHandler(cs clicks.ClickService, us user.UserService, s *signer.Signer) *ConversionHandler {
+	return &ConversionHandler{ClickService: cs, UserService: us, Signer: s}
+}
+
+type conversionRequest struct {
+	ClickID string   `json:"click_id"`
+	Value   *float64 `json:"value"`
+}
+
+// ConversionsRouter serves the public conversion endpoints that destination
+// sites call with the redo_cid they received:
+//
+//	POST /api/conversions         {"click_id": "...", "value": 19.99}
+//	GET  /api/conversions/pixel   ?cid=..., answers with a 1x1 GIF
+//
+// value is optional revenue in currency units. Anyone who saw the click ID,
+// including the visitor, can call these endpoints, so revenue is only
+// accepted from a server: the POST must carry ConversionSignatureHeader
+// signed with the link owner's conversion secret. Unsigned POSTs and the
+// pixel record the conversion without revenue. A click converts once; later
+// reports are acknowledged but ignored.
+func (ch *ConversionHandler) ConversionsRouter() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/conversions"), "/") {
+		case "":
+			if !validateMethod(w, r, http.MethodPost) {
+				return
+			}
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxConversionBody))
+			if err != nil {
+				utils.WriteJSONError(w, http.StatusBadRequest, "Invalid request payload")
+				return
+			}
+			var req conversionRequest
+			if err := json.Unmarshal(body, &req); err != nil {
+				utils.WriteJSONError(w, http.StatusBadRequest, "Invalid request payload")
+				return
+			}
+			conv, status, msg := ch.record(r, req, body)
+			if status != http.StatusOK {
+				utils.WriteJSONError(w, status, msg)
+				return
+			}
+			utils.WriteJSON(w, http.StatusOK, conv)
+		case "pixel":
+			if !validateMethod(w, r, http.MethodGet) {
+				return
+			}
+			// The pixel runs in the visitor's browser, so it only counts the
+			// conversion. It always answers with the image so a failure never
+			// shows as a broken image on the destination site.
+			ch.record(r, conversionRequest{ClickID: r.URL.Query().Get("cid")}, nil)
+			w.Header().Set("Content-Type", "image/gif")
+			w.Header().Set("Cache-Control", "no-store")
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write(transparentGIF)
+		default:
+			utils.WriteJSONError(w, http.StatusNotFound, "Not Found")
+		}
+	}
+}
+
+// SecretHandler serves /api/conversions/secret for the signed-in user: GET
+// returns the conversion secret, creating it on first use, and POST replaces
+// it.
+func (ch *ConversionHandler) SecretHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := authorizeUser(w, r, ch.UserService)
+		if !ok {
+			return
+		}
+		if r.Method != http.MethodGet && r.Method != http.MethodPost {
+			utils.WriteJSONError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+			return
+		}
+		secret, err := ch.ClickService.ConversionSecret(r.Context(), userID, r.Method == http.MethodPost)
+		if err != nil {
+			utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to load conversion secret")
+			return
+		}
+		w.Header().Set("Cache-Control", "no-store")
+		utils.WriteJSON(w, http.StatusOK, map[string]string{"secret": secret})
+	}
+}
+
+// record stores a conversion. body is the signed request body, or nil when
+// the report cannot carry revenue. On failure it returns the status and
+// message for the client.
+func (ch *ConversionHandler) record(r *http.Request, req conversionRequest, body []byte) (model.Conversion, int, string) {
+	clickID, ok := verifyClickID(ch.Signer, req.ClickID)
+	if !ok {
+		logger.Warn("conversion: rejected click id %q", req.ClickID)
+		return model.Conversion{}, http.StatusBadRequest, "Invalid click_id"
+	}
+	var cents *int64
+	if req.Value != nil && body != nil {
+		v := *req.Value
+		if math.IsNaN(v) || v < 0 || v > maxConversionValue {
+			return model.Conversion{}, http.StatusBadRequest, "Invalid value"
+		}
+		secret, err := ch.ClickService.ClickConversionSecret(r.Context(), clickID)
+		if err == clicks.ErrClickNotFound {
+			return model.Conversion{}, http.StatusNotFound, "Click not found"
+		} else if err != nil {
+			return model.Conversion{}, http.StatusInternalServerError, "Failed to record conversion"
+		}
+		if !verifyConversionBody(secret, body, r.Header.Get(ConversionSignatureHeader)) {
+			logger.Warn("conversion: unsigned revenue report for click %s", clickID)
+			return model.Conversion{}, http.StatusUnauthorized, "Reports with a value must be signed"
+		}
+		c := int64(math.Round(v * 100))
+		cents = &c
+	}
+	conv, err := ch.ClickService.RecordConversion(r.Context(), clickID, cents)
+	if err == clicks.ErrClickNotFound {
+		return model.Conversion{}, http.StatusNotFound, "Click not found"
+	} else if err != nil {
+		return model.Conversion{}, http.StatusInternalServerError, "Failed to record conversion"
+	}
+	return conv, http.StatusOK, ""
+}
+
+// verifyConversionBody checks sig against the owner's secret; a user without
+// a secret cannot report revenue.
+func verifyConversionBody(secret string, body []byte, sig string) bool {
+	if secret == "" || sig == "" {
+		return false
+	}
+	return signer.New([]byte(secret)).Verify(string(body), sig)
+}
+
+// signClickID returns the token handed to destinations: the click ID and its
+// signature.
+func signClickID(s *signer.Signer, clickID string) string {
+	return clickID + "." + s.Sign("click|"+clickID)
+}
+
+// verifyClickID checks a token from signClickID and returns the click ID.
+func verifyClickID(s *signer.Signer, token string) (string, bool) {
+	clickID, sig, ok := strings.Cut(token, ".")
+	if !ok || s == nil || !IsValidUUID(clickID) || !s.Verify("click|"+clickID, sig) {
+		return "", false
+	}
+	return clickID, true
+}
+
+// withClickID adds the signed click ID to a destination URL. The existing
+// query is kept byte for byte, since destinations may depend on its order.
+func withClickID(destination, token string) string {
+	u, err := url.Parse(destination)
+	if err != nil {
+		return destination
+	}
+	param := ClickIDParam + "=" + url.QueryEscape(token)
+	if u.RawQuery == "" {
+		u.RawQuery = param
+	} else {
+		u.RawQuery += "&" + param
+	}
+	return u.String()
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+
+	"redo.ai/internal/pkg/signer"
+)
+
+func TestClickIDRoundTrip(t *testing.T) {
+	s := signer.New([]byte("secret"))
+	const id = "3f1c2e4a-8b7d-4c6e-9a1b-2d3e4f5a6b7c"
+	token := signClickID(s, id)
+
+	got, ok := verifyClickID(s, token)
+	if !ok || got != id {
+		t.Fatalf("verifyClickID = %q, %v; want %q, true", got, ok, id)
+	}
+
+	_, sig, _ := strings.Cut(token, ".")
+	rejected := map[string]string{
+		"other click":    "0b6f3f1e-6a0e-4f4c-9d55-7a8a3f0f2c11." + sig,
+		"bad signature":  id + ".AAAA",
+		"no signature":   id,
+		"not a uuid":     "nope." + s.Sign("click|nope"),
+		"unscoped":       id + "." + s.Sign(id),
+		"empty":          "",
+		"other key":      signClickID(signer.New([]byte("other")), id),
+		"trailing bytes": token + "x",
+	}
+	for name, tok := range rejected {
+		if _, ok := verifyClickID(s, tok); ok {
+			t.Errorf("%s: token %q verified", name, tok)
+		}
+	}
+	if _, ok := verifyClickID(nil, token); ok {
+		t.Error("verified without a signer")
+	}
+}
+
+func TestWithClickID(t *testing.T) {
+	tests := []struct {
+		dest, want string
+	}{
+		{"https://shop.example/", "https://shop.example/?redo_cid=tok.sig"},
+		{"https://shop.example/p?b=2&a=1", "https://shop.example/p?b=2&a=1&redo_cid=tok.sig"},
+		{"https://shop.example/p?q=a%20b#section", "https://shop.example/p?q=a%20b&redo_cid=tok.sig#section"},
+		{"https://shop.example/p?", "https://shop.example/p?redo_cid=tok.sig"},
+	}
+	for _, tt := range tests {
+		if got := withClickID(tt.dest, "tok.sig"); got != tt.want {
+			t.Errorf("withClickID(%q) = %q, want %q", tt.dest, got, tt.want)
+		}
+	}
+	if got := withClickID("https://shop.example/", "a+b/c="); got != "https://shop.example/?redo_cid=a%2Bb%2Fc%3D" {
+		t.Errorf("token not escaped: %q", got)
+	}
+	if got := withClickID("://bad", "tok"); got != "://bad" {
+		t.Errorf("unparseable destination changed: %q", got)
+	}
+}
+
+func TestVerifyConversionBody(t *testing.T) {
+	body := []byte(`{"click_id":"x","value":19.99}`)
+	sig := signer.New([]byte("owner-secret")).Sign(string(body))
+
+	if !verifyConversionBody("owner-secret", body, sig) {
+		t.Fatal("valid signature rejected")
+	}
+	if verifyConversionBody("owner-secret", []byte(`{"click_id":"x","value":1999}`), sig) {
+		t.Error("signature accepted for a changed body")
+	}
+	if verifyConversionBody("other-secret", body, sig) {
+		t.Error("signature accepted under another secret")
+	}
+	if verifyConversionBody("owner-secret", body, "") {
+		t.Error("missing signature accepted")
+	}
+	if verifyConversionBody("", body, signer.New(nil).Sign(string(body))) {
+		t.Error("accepted a report for an owner without a secret")
+	}
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+
+	"redo.ai/internal/model"
+	"redo.ai/internal/pkg/entitlements"
+	"redo.ai/internal/service/applink"
+	"redo.ai/internal/service/audit"
+	"redo.ai/internal/utils"
+)
+
+// DomainsRouter serves /api/domains: GET lists the user's custom domains,
+// POST adds one, DELETE ?id= removes one and POST /api/domains/verify?id=
+// checks its DNS verification record.
+func (ah *AppHandler) DomainsRouter() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := authorizeUser(w, r, ah.UserService)
+		if !ok {
+			return
+		}
+
+		if r.URL.Path == "/api/domains/verify" {
+			if !validateMethod(w, r, http.MethodPost) {
+				return
+			}
+			id := r.URL.Query().Get("id")
+			if !IsValidUUID(id) {
+				utils.WriteJSONError(w, http.StatusBadRequest, "Invalid or missing domain ID")
+				return
+			}
+			d, err := ah.AppLinks.VerifyDomain(r.Context(), userID, id)
+			if writeDomainError(w, err) {
+				return
+			}
+			recordAudit(r, ah.Audit, userID, audit.ActionDomainUpdate, audit.TargetDomain, id, nil, d)
+			utils.WriteJSON(w, http.StatusOK, d)
+			return
+		} else if r.URL.Path != "/api/domains" {
+			utils.WriteJSONError(w, http.StatusNotFound, "Not Found")
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			domains, err := ah.AppLinks.ListDomains(r.Context(), userID)
+			if err != nil {
+				utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to fetch domains")
+				return
+			}
+			utils.WriteJSON(w, http.StatusOK, domains)
+		case http.MethodPost:
+			if !requireFeature(w, r, ah.UserService, userID, entitlements.FeatureCustomDomains) {
+				return
+			}
+			var req model.CreateDomainRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				utils.WriteJSONError(w, http.StatusBadRequest, "Invalid request payload")
+				return
+			}
+			domain, err := normalizeDomain(req.Domain)
+			if err != nil {
+				writeErrorMessage(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			d, err := ah.AppLinks.CreateDomain(r.Context(), userID, domain)
+			if writeDomainError(w, err) {
+				return
+			}
+			recordAudit(r, ah.Audit, userID, audit.ActionDomainCreate, audit.TargetDomain, d.ID, nil, d)
+			utils.WriteJSON(w, http.StatusCreated, d)
+		case http.MethodDelete:
+			id := r.URL.Query().Get("id")
+			if !IsValidUUID(id) {
+				utils.WriteJSONError(w, http.StatusBadRequest, "Invalid or missing domain ID")
+				return
+			}
+			d, err := ah.AppLinks.DeleteDomain(r.Context(), userID, id)
+			if writeDomainError(w, err) {
+				return
+			}
+			recordAudit(r, ah.Audit, userID, audit.ActionDomainDelete, audit.TargetDomain, id, d, nil)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			utils.WriteJSONError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+		}
+	}
+}
+
+// normalizeDomain lowercases a hostname and rejects anything that is not a
+// plain multi-label DNS name, such as URLs, IP addresses and ports.
+func normalizeDomain(s string) (string, error) {
+	d := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(s)), ".")
+	invalid := errors.New("domain must be a hostname such as links.example.com")
+	if len(d) == 0 || len(d) > 253 || net.ParseIP(d) != nil {
+		return "", invalid
+	}
+	labels := strings.Split(d, ".")
+	if len(labels) < 2 {
+		return "", invalid
+	}
+	for _, l := range labels {
+		if len(l) == 0 || len(l) > 63 || l[0] == '-' || l[len(l)-1] == '-' {
+			return "", invalid
+		}
+		for _, c := range l {
+			if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '-' {
+				return "", invalid
+			}
+		}
+	}
+	return d, nil
+}
+
+func writeDomainError(w http.ResponseWriter, err error) bool {
+	switch err {
+	case nil:
+		return false
+	case applink.ErrDomainNotFound:
+		utils.WriteJSONError(w, http.StatusNotFound, "Domain not found")
+	case applink.ErrDomainExists:
+		utils.WriteJSONError(w, http.StatusConflict, "Domain already registered")
+	case applink.ErrDomainInUse:
+		utils.WriteJSONError(w, http.StatusConflict, "Domain is used by links")
+	case applink.ErrDomainUnverified:
+		utils.WriteJSONError(w, http.StatusUnprocessableEntity, "Verification record not found")
+	default:
+		if !writeEntitlementError(w, err) {
+			utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to save domain")
+		}
+	}
+	return true
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import "testing"
+
+func TestNormalizeDomain(t *testing.T) {
+	valid := map[string]string{
+		"links.example.com":   "links.example.com",
+		" Links.Example.COM ": "links.example.com",
+		"go.example.co.uk.":   "go.example.co.uk",
+		"a-b.example.io":      "a-b.example.io",
+	}
+	for in, want := range valid {
+		got, err := normalizeDomain(in)
+		if err != nil || got != want {
+			t.Errorf("normalizeDomain(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+
+	for _, in := range []string{
+		"",
+		"localhost",
+		"https://links.example.com",
+		"links.example.com:8080",
+		"links.example.com/path",
+		"127.0.0.1",
+		"-bad.example.com",
+		"bad-.example.com",
+		"a..example.com",
+		"under_score.example.com",
+	} {
+		if got, err := normalizeDomain(in); err == nil {
+			t.Errorf("normalizeDomain(%q) = %q, want error", in, got)
+		}
+	}
+}
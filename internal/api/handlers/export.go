@@ -0,0 +1,249 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"redo.ai/internal/model"
+	"redo.ai/internal/pkg/entitlements"
+	"redo.ai/internal/service/clicks"
+	"redo.ai/internal/service/link"
+	"redo.ai/internal/service/user"
+	"redo.ai/internal/utils"
+	"redo.ai/logger"
+)
+
+// exportFlushEvery is how many records are written between flushes so the
+// client sees progress on long exports.
+const exportFlushEvery = 500
+
+type ExportHandler struct {
+	LinkService  link.LinkService
+	ClickService clicks.ClickService
+	UserService  user.UserService
+}
+
+func NewExportHandler(linkService link.LinkService, clickService clicks.ClickService, userService user.UserService) *ExportHandler {
+	return &ExportHandler{
+		LinkService:  linkService,
+		ClickService: clickService,
+		UserService:  userService,
+	}
+}
+
+// ExportRouter serves GET /api/export/links and GET /api/export/clicks.
+// ?format=csv (default) or ndjson selects the encoding. Click exports accept
+// link_id, from and to (RFC 3339, to exclusive) and are limited to the plan's
+// analytics retention window.
+func (eh *ExportHandler) ExportRouter() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !validateMethod(w, r, http.MethodGet) {
+			return
+		}
+		userID, ok := authorizeUser(w, r, eh.UserService)
+		if !ok {
+			return
+		}
+		format := strings.ToLower(r.URL.Query().Get("format"))
+		if format == "" {
+			format = "csv"
+		}
+		if format != "csv" && format != "ndjson" {
+			utils.WriteJSONError(w, http.StatusBadRequest, "Invalid format, expected csv or ndjson")
+			return
+		}
+
+		switch strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/export"), "/") {
+		case "links":
+			eh.exportLinks(w, r, userID, format)
+		case "clicks":
+			eh.exportClicks(w, r, userID, format)
+		default:
+			utils.WriteJSONError(w, http.StatusNotFound, "Not Found")
+		}
+	}
+}
+
+var linkExportHeader = []string{"id", "slug", "short_code", "destination", "title", "tags", "campaign_id", "is_active", "clicks", "created_at"}
+
+func (eh *ExportHandler) exportLinks(w http.ResponseWriter, r *http.Request, userID, format string) {
+	out := newExportWriter(w, format, "links", linkExportHeader)
+	err := eh.LinkService.StreamLinks(r.Context(), userID, func(lk model.Link) error {
+		return out.write(lk, []string{
+			lk.LinkID, lk.Slug, lk.ShortCode, lk.Destination, lk.Title, strings.Join(lk.Tags, "|"),
+			lk.CampaignID, strconv.FormatBool(lk.Is_active), strconv.Itoa(lk.ClickCount), lk.CreatedAt,
+		})
+	})
+	out.finish(err)
+}
+
+var clickExportHeader = []string{"id", "link_id", "created_at", "ip", "referrer", "user_agent", "device_type", "country", "conversion", "is_high_value", "source", "variant_id", "revenue_cents", "converted_at"}
+
+func (eh *ExportHandler) exportClicks(w http.ResponseWriter, r *http.Request, userID, format string) {
+	usr, err := eh.UserService.GetByUserID(r.Context(), userID)
+	if err != nil {
+		logger.Error("exportClicks: failed to fetch user: %v", err)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to verify user")
+		return
+	}
+	ent := entitlements.For(usr.Role)
+	if writeEntitlementError(w, ent.RequireFeature(entitlements.FeatureAnalytics)) {
+		return
+	}
+
+	params := r.URL.Query()
+	filter := model.ClickFilter{UserID: userID, LinkID: params.Get("link_id")}
+	if filter.LinkID != "" {
+		if !IsValidUUID(filter.LinkID) {
+			utils.WriteJSONError(w, http.StatusBadRequest, "Invalid link_id")
+			return
+		}
+		if _, err := eh.LinkService.GetLink(r.Context(), userID, filter.LinkID); err == link.ErrLinkNotFound {
+			utils.WriteJSONError(w, http.StatusNotFound, "Link not found")
+			return
+		} else if err != nil {
+			utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to export clicks")
+			return
+		}
+	}
+	for name, dst := range map[string]*time.Time{"from": &filter.From, "to": &filter.To} {
+		if raw := params.Get(name); raw != "" {
+			t, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				utils.WriteJSONError(w, http.StatusBadRequest, "Invalid "+name+" timestamp")
+				return
+			}
+			*dst = t
+		}
+	}
+	if since := ent.AnalyticsSince(time.Now().UTC()); !since.IsZero() && filter.From.Before(since) {
+		filter.From = since
+	}
+
+	out := newExportWriter(w, format, "clicks", clickExportHeader)
+	err = eh.ClickService.StreamClicks(r.Context(), filter, func(c model.Click) error {
+		return out.write(c, []string{
+			c.ID, c.LinkID, c.CreatedAt.UTC().Format(time.RFC3339Nano), c.IP, c.Referrer, c.UserAgent,
+			c.DeviceType, c.Country, strconv.FormatBool(c.Conversion), strconv.FormatBool(c.IsHighValue), c.Source, c.VariantID,
+			formatCents(c.RevenueCents), formatTimePtr(c.ConvertedAt),
+		})
+	})
+	out.finish(err)
+}
+
+// exportWriter streams records as CSV or NDJSON. Headers are sent with the
+// first record, so failures before that still get a JSON error response.
+type exportWriter struct {
+	w       http.ResponseWriter
+	format  string
+	name    string
+	header  []string
+	csv     *csv.Writer
+	json    *json.Encoder
+	started bool
+	written int
+}
+
+func newExportWriter(w http.ResponseWriter, format, name string, header []string) *exportWriter {
+	return &exportWriter{w: w, format: format, name: name, header: header}
+}
+
+func (e *exportWriter) start() error {
+	e.started = true
+	filename := fmt.Sprintf("%s-%s.%s", e.name, time.Now().UTC().Format("20060102-150405"), e.format)
+	e.w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	if e.format == "ndjson" {
+		e.w.Header().Set("Content-Type", "application/x-ndjson")
+		e.w.WriteHeader(http.StatusOK)
+		e.json = json.NewEncoder(e.w)
+		return nil
+	}
+	e.w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	e.w.WriteHeader(http.StatusOK)
+	e.csv = csv.NewWriter(e.w)
+	return e.csv.Write(e.header)
+}
+
+func (e *exportWriter) write(record any, fields []string) error {
+	if !e.started {
+		if err := e.start(); err != nil {
+			return err
+		}
+	}
+	var err error
+	if e.json != nil {
+		err = e.json.Encode(record)
+	} else {
+		for i := range fields {
+			fields[i] = csvSafe(fields[i])
+		}
+		err = e.csv.Write(fields)
+	}
+	if err != nil {
+		return err
+	}
+	e.written++
+	if e.written%exportFlushEvery == 0 {
+		e.flush()
+	}
+	return nil
+}
+
+func (e *exportWriter) flush() {
+	if e.csv != nil {
+		e.csv.Flush()
+	}
+	if f, ok := e.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// finish flushes the stream. An empty export still produces a CSV header.
+// Once rows have been sent the status can no longer change, so a late error
+// only truncates the stream and is logged.
+func (e *exportWriter) finish(err error) {
+	if err != nil {
+		if !e.started {
+			logger.Error("export %s failed: %v", e.name, err)
+			utils.WriteJSONError(e.w, http.StatusInternalServerError, "Export failed")
+			return
+		}
+		logger.Error("export %s aborted after %d records: %v", e.name, e.written, err)
+		e.flush()
+		return
+	}
+	if !e.started {
+		if err := e.start(); err != nil {
+			logger.Error("export %s: failed to write header: %v", e.name, err)
+			return
+		}
+	}
+	e.flush()
+}
+
+// csvSafe neutralises cells a spreadsheet would evaluate as a formula.
+func csvSafe(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+func formatCents(c *int64) string {
+	if c == nil {
+		return ""
+	}
+	return strconv.FormatInt(*c, 10)
+}
+
+func formatTimePtr(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
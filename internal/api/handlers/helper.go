@@ -2,10 +2,15 @@ package handlers
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
 
 	"github.com/google/uuid"
 	"redo.ai/internal/api/middleware"
+	"redo.ai/internal/pkg/entitlements"
+	"redo.ai/internal/pkg/urlpolicy"
+	"redo.ai/internal/service/link"
 	"redo.ai/internal/service/user"
 	"redo.ai/internal/utils"
 	"redo.ai/logger"
@@ -66,7 +71,115 @@ func checkUserExists(ctx context.Context, userService user.UserService, w http.R
 	return true
 }
 
+// authorizeUser runs the standard checks for user-scoped API routes: a JWT
+// sub, a well-formed X-User-ID and an existing user. It writes the error
+// response itself.
+func authorizeUser(w http.ResponseWriter, r *http.Request, userService user.UserService) (string, bool) {
+	if _, ok := verifySubFromContext(w, r); !ok {
+		return "", false
+	}
+	userID, ok := extractUserIDFromRequest(w, r)
+	if !ok {
+		return "", false
+	}
+	if !checkUserExists(r.Context(), userService, w, userID) {
+		return "", false
+	}
+	return userID, true
+}
+
 func IsValidUUID(s string) bool {
 	_, err := uuid.Parse(s)
 	return err == nil
 }
+
+// requireFeature loads the user's plan and writes a 403 when it lacks
+// feature.
+func requireFeature(w http.ResponseWriter, r *http.Request, userService user.UserService, userID string, feature entitlements.Feature) bool {
+	usr, err := userService.GetByUserID(r.Context(), userID)
+	if err != nil {
+		logger.Error("requireFeature: failed to fetch user %s: %v", userID, err)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to verify user")
+		return false
+	}
+	return !writeEntitlementError(w, entitlements.For(usr.Role).RequireFeature(feature))
+}
+
+// entitlementError is the body returned whenever a plan limit blocks a request.
+type entitlementError struct {
+	Error   string `json:"error"`
+	Code    string `json:"code"`
+	Plan    string `json:"plan"`
+	Limit   string `json:"limit,omitempty"`
+	Feature string `json:"feature,omitempty"`
+	Allowed *int   `json:"allowed,omitempty"`
+	Used    *int   `json:"used,omitempty"`
+}
+
+// writeEntitlementError writes a 402 for exhausted quotas or a 403 for
+// features missing from the plan. It returns false when err is neither.
+func writeEntitlementError(w http.ResponseWriter, err error) bool {
+	var qe *entitlements.QuotaError
+	if errors.As(err, &qe) {
+		utils.WriteJSON(w, http.StatusPaymentRequired, entitlementError{
+			Error:   "Plan limit reached",
+			Code:    "quota_exceeded",
+			Plan:    string(qe.Plan),
+			Limit:   string(qe.Limit),
+			Allowed: &qe.Allowed,
+			Used:    &qe.Used,
+		})
+		return true
+	}
+	var fe *entitlements.FeatureError
+	if errors.As(err, &fe) {
+		utils.WriteJSON(w, http.StatusForbidden, entitlementError{
+			Error:   "Upgrade required",
+			Code:    "feature_unavailable",
+			Plan:    string(fe.Plan),
+			Feature: string(fe.Feature),
+		})
+		return true
+	}
+	return false
+}
+
+// writeErrorMessage writes an error body whose message may carry quotes or
+// other text from the request, such as parser errors. WriteJSONError splices
+// its message into the body unescaped, so it is only safe for fixed text.
+func writeErrorMessage(w http.ResponseWriter, status int, message string) {
+	utils.WriteJSON(w, status, map[string]string{"error": message})
+}
+
+// destinationError is the body returned when the destination policy rejects
+// a URL.
+type destinationError struct {
+	Error  string `json:"error"`
+	Code   string `json:"code"`
+	Rule   string `json:"rule"`
+	Reason string `json:"reason"`
+}
+
+// writeDestinationError writes a 400 for destinations the policy engine
+// rejected. It returns false for any other error.
+func writeDestinationError(w http.ResponseWriter, err error) bool {
+	var v *urlpolicy.Violation
+	if !errors.As(err, &v) {
+		return false
+	}
+	utils.WriteJSON(w, http.StatusBadRequest, destinationError{
+		Error:  "Destination not allowed",
+		Code:   "destination_blocked",
+		Rule:   v.Rule,
+		Reason: v.Reason,
+	})
+	return true
+}
+
+var linkPasswordMessage = fmt.Sprintf("Password must be %d to %d bytes", link.MinPasswordLength, link.MaxPasswordLength)
+
+// isValidLinkPassword checks a new link password against the lengths bcrypt
+// can hash without truncating.
+func isValidLinkPassword(p string) bool {
+	return len(p) >= link.MinPasswordLength && len(p) <= link.MaxPasswordLength
+}
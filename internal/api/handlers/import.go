@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"redo.ai/internal/model"
+	"redo.ai/internal/pkg/entitlements"
+	"redo.ai/internal/pkg/importer"
+	"redo.ai/internal/service/audit"
+	"redo.ai/internal/service/link"
+	"redo.ai/internal/utils"
+	"redo.ai/logger"
+)
+
+// ImportLinksHandler serves POST /api/links/import?format=bitly|rebrandly|csv.
+// The body is the exported CSV (text/csv, or multipart/form-data with a
+// "file" field). ?dry_run=true reports what would be imported without
+// committing anything.
+func (lh *LinkHandler) ImportLinksHandler(w http.ResponseWriter, r *http.Request, userID string) {
+	if !requireFeature(w, r, lh.UserService, userID, entitlements.FeatureBulkImport) {
+		return
+	}
+	params := r.URL.Query()
+	format := strings.ToLower(params.Get("format"))
+	if format == "" {
+		format = importer.FormatCSV
+	}
+	if !importer.IsValidFormat(format) {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid format, expected bitly, rebrandly or csv")
+		return
+	}
+	dryRun := false
+	if raw := params.Get("dry_run"); raw != "" {
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			utils.WriteJSONError(w, http.StatusBadRequest, "Invalid dry_run flag")
+			return
+		}
+		dryRun = v
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxBulkBodyBytes)
+	upload, isCSV, err := csvUpload(r)
+	if err != nil {
+		writeErrorMessage(w, http.StatusBadRequest, "Invalid import payload: "+err.Error())
+		return
+	}
+	if !isCSV {
+		utils.WriteJSONError(w, http.StatusUnsupportedMediaType, "Expected a CSV upload")
+		return
+	}
+	defer upload.Close()
+	items, err := importer.Parse(format, upload, link.MaxBulkRows)
+	if err != nil {
+		writeErrorMessage(w, http.StatusBadRequest, "Invalid import payload: "+err.Error())
+		return
+	}
+	if len(items) == 0 {
+		utils.WriteJSONError(w, http.StatusBadRequest, "No rows to import")
+		return
+	}
+
+	valid, invalid, unusableCodes := validateImport(items)
+	report := model.ImportReport{Format: format, DryRun: dryRun, Total: len(items)}
+	if len(valid) > 0 {
+		report, err = lh.LinkService.ImportLinks(r.Context(), userID, format, valid, dryRun)
+		if err != nil {
+			if writeEntitlementError(w, err) {
+				return
+			}
+			logger.Error("ImportLinksHandler: import failed for userID=%s: %v", userID, err)
+			utils.WriteJSONError(w, http.StatusInternalServerError, "Import failed")
+			return
+		}
+		report.Total = len(items)
+	}
+
+	// Codes our slug rules reject were never tried; report them as reissued.
+	for i := range report.Rows {
+		if code, ok := unusableCodes[report.Rows[i].Row]; ok {
+			report.Rows[i].OriginalShortCode = code
+			if report.Rows[i].Status == model.BulkRowOK {
+				report.ShortCodesReissued++
+			}
+		}
+	}
+	report.Rows = append(report.Rows, invalid...)
+	report.Failed += len(invalid)
+	sort.SliceStable(report.Rows, func(i, j int) bool { return report.Rows[i].Row < report.Rows[j].Row })
+
+	if !dryRun && lh.Audit != nil {
+		base := auditEventFromRequest(r, userID)
+		for _, row := range report.Rows {
+			if row.Status == model.BulkRowOK {
+				ev := base
+				ev.Action = audit.ActionLinkCreate
+				ev.TargetType = audit.TargetLink
+				ev.TargetID = row.LinkID
+				_ = lh.Audit.Record(r.Context(), ev)
+			}
+		}
+	}
+	utils.WriteJSON(w, http.StatusOK, report)
+}
+
+// validateImport splits rows the service can attempt from rows that fail our
+// format rules. Original short codes that are not valid slugs are dropped
+// so a fresh code is issued; they are returned by row for the report.
+func validateImport(items []model.ImportItem) ([]model.ImportItem, []model.ImportRowResult, map[int]string) {
+	valid := items[:0:0]
+	var invalid []model.ImportRowResult
+	unusable := map[int]string{}
+	for _, it := range items {
+		fail := func(msg string) {
+			invalid = append(invalid, model.ImportRowResult{
+				Row:               it.Row,
+				Status:            model.BulkRowFailed,
+				Destination:       it.Destination,
+				Slug:              it.Slug,
+				OriginalShortCode: it.OriginalShortCode,
+				Error:             msg,
+			})
+		}
+		switch {
+		case !utils.IsValidURL(it.Destination):
+			fail("invalid destination")
+			continue
+		case it.Slug != "" && !utils.IsValidSlug(it.Slug):
+			fail("invalid slug")
+			continue
+		case it.CampaignID != "" && !IsValidUUID(it.CampaignID):
+			fail("invalid campaign_id")
+			continue
+		}
+		if it.OriginalShortCode != "" && !utils.IsValidSlug(it.OriginalShortCode) {
+			unusable[it.Row] = it.OriginalShortCode
+			it.OriginalShortCode = ""
+		}
+		valid = append(valid, it)
+	}
+	return valid, invalid, unusable
+}
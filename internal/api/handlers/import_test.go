@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestImportLinksHandlerEncodesParseErrors(t *testing.T) {
+	lh := &LinkHandler{UserService: planUsers{role: "pro"}}
+	req := httptest.NewRequest(http.MethodPost, "/api/links/import?format=csv", strings.NewReader("destination,slug\nhttps://a.example,a,extra\n"))
+	req.Header.Set("Content-Type", "text/csv")
+	rec := httptest.NewRecorder()
+	lh.ImportLinksHandler(rec, req, "u1")
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400: %s", rec.Code, rec.Body)
+	}
+	if msg := decodeErrorBody(t, rec.Body.Bytes()); !strings.HasPrefix(msg, "Invalid import payload: invalid CSV") {
+		t.Errorf("message = %q, want the parser error", msg)
+	}
+}
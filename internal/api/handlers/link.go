@@ -3,10 +3,18 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	lru "github.com/hashicorp/golang-lru"
 	"redo.ai/internal/model"
 	"redo.ai/internal/pkg/platform"
+	"redo.ai/internal/pkg/signer"
+	"redo.ai/internal/pkg/throttle"
+	"redo.ai/internal/service/applink"
+	"redo.ai/internal/service/audit"
+	"redo.ai/internal/service/bulk"
 	"redo.ai/internal/service/link"
 	"redo.ai/internal/service/user"
 	"redo.ai/internal/utils"
@@ -17,7 +25,30 @@ type LinkHandler struct {
 	LinkService link.LinkService
 	UserService user.UserService
 	Platform    platform.PlatformDetector
+	Audit       audit.AuditService
+	Bulk        bulk.BulkService
 	Cache       *lru.Cache
+	// AccessSigner signs the cookie that remembers a correct link password;
+	// without it visitors are asked every time.
+	AccessSigner *signer.Signer
+	// ClickSigner signs the click IDs appended to destinations of links with
+	// conversion tracking; without it no click IDs are appended.
+	ClickSigner *signer.Signer
+	// PasswordAttempts throttles wrong passwords per link and IP.
+	// PasswordBackoff slows down guesses per link and source network, so
+	// rotating addresses within a network does not help and a guesser
+	// elsewhere cannot lock the link's real visitors out.
+	PasswordAttempts *throttle.Limiter
+	PasswordBackoff  *throttle.Backoff
+	// ShortURLBase is the scheme and host short URLs are served from, e.g.
+	// https://redo.ai. Without it no short URLs are built.
+	ShortURLBase string
+	// CountryHeader names the request header a proxy or CDN fills with the
+	// visitor's country code for routing rules; CF-IPCountry by default.
+	CountryHeader string
+	// AppLinks supplies the Android apps registered for custom domains, so
+	// visits on those domains can open the app; nil skips the lookup.
+	AppLinks applink.AppLinkService
 }
 
 func NewLinkHandler(userService user.UserService, linkService link.LinkService, cache *lru.Cache) *LinkHandler {
@@ -25,6 +56,9 @@ func NewLinkHandler(userService user.UserService, linkService link.LinkService,
 		LinkService: linkService,
 		UserService: userService,
 		Cache:       cache,
+
+		PasswordAttempts: throttle.New(5, 15*time.Minute),
+		PasswordBackoff:  throttle.NewBackoff(10, 2*time.Second, 10*time.Minute, time.Hour),
 	}
 }
 
@@ -44,6 +78,40 @@ func (lh *LinkHandler) LinksRouter() http.HandlerFunc {
 			return
 		}
 
+		switch sub := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/links"), "/"); sub {
+		case "":
+		case "search":
+			if validateMethod(w, r, http.MethodGet) {
+				lh.SearchLinksHandler(w, r, userID)
+			}
+			return
+		case "bulk":
+			lh.BulkLinksHandler(w, r, userID)
+			return
+		case "availability":
+			if validateMethod(w, r, http.MethodGet) {
+				lh.SlugAvailabilityHandler(w, r, userID)
+			}
+			return
+		case "import":
+			if validateMethod(w, r, http.MethodPost) {
+				lh.ImportLinksHandler(w, r, userID)
+			}
+			return
+		case "bulk/jobs":
+			if validateMethod(w, r, http.MethodGet) {
+				lh.BulkJobHandler(w, r, userID)
+			}
+			return
+		default:
+			if id, rest, ok := strings.Cut(sub, "/"); ok && IsValidUUID(id) {
+				lh.linkSubresource(w, r, userID, id, rest)
+				return
+			}
+			utils.WriteJSONError(w, http.StatusNotFound, "Not Found")
+			return
+		}
+
 		switch r.Method {
 		case http.MethodPost:
 			lh.CreateLinkHandler(w, r, userID)
@@ -63,6 +131,26 @@ func (lh *LinkHandler) LinksRouter() http.HandlerFunc {
 	}
 }
 
+// linkSubresource dispatches /api/links/{id}/... routes.
+func (lh *LinkHandler) linkSubresource(w http.ResponseWriter, r *http.Request, userID, linkID, rest string) {
+	switch rest {
+	case "qr":
+		lh.LinkQRHandler(w, r, userID, linkID)
+	case "variants":
+		lh.LinkVariantsHandler(w, r, userID, linkID)
+	case "rules":
+		lh.LinkRulesHandler(w, r, userID, linkID)
+	case "schedules":
+		lh.LinkSchedulesHandler(w, r, userID, linkID)
+	case "variants/stats":
+		if validateMethod(w, r, http.MethodGet) {
+			lh.LinkVariantStatsHandler(w, r, userID, linkID)
+		}
+	default:
+		utils.WriteJSONError(w, http.StatusNotFound, "Not Found")
+	}
+}
+
 func (lh *LinkHandler) CreateLinkHandler(w http.ResponseWriter, r *http.Request, userID string) {
 	var req model.CreateLinkRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -73,6 +161,18 @@ func (lh *LinkHandler) CreateLinkHandler(w http.ResponseWriter, r *http.Request,
 		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid format")
 		return
 	}
+	if req.CampaignID != "" && !IsValidUUID(req.CampaignID) {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid campaign_id")
+		return
+	}
+	if req.Password != "" && !isValidLinkPassword(req.Password) {
+		utils.WriteJSONError(w, http.StatusBadRequest, linkPasswordMessage)
+		return
+	}
+	if msg := validatePreview(req.Preview); msg != "" {
+		utils.WriteJSONError(w, http.StatusBadRequest, msg)
+		return
+	}
 
 	lk, err := lh.LinkService.CreateLink(r.Context(), userID, req)
 	if err != nil {
@@ -80,39 +180,105 @@ func (lh *LinkHandler) CreateLinkHandler(w http.ResponseWriter, r *http.Request,
 			utils.WriteJSONError(w, http.StatusConflict, "Slug already exists")
 			return
 		}
+		if err == link.ErrCampaignNotFound {
+			utils.WriteJSONError(w, http.StatusBadRequest, "Campaign not found")
+			return
+		}
+		if writeSlugPolicyError(w, err) || writeDestinationError(w, err) {
+			return
+		}
+		if writeEntitlementError(w, err) {
+			return
+		}
 		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to create link")
 		return
 	}
 
-	if cached, ok := lh.Cache.Get(userID); ok {
-		if links, ok := cached.([]model.Link); ok {
-			lh.Cache.Add(userID, append(links, lk))
-		}
-	}
+	recordAudit(r, lh.Audit, userID, audit.ActionLinkCreate, audit.TargetLink, lk.LinkID, nil, lk)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 
 }
 
+// ListLinksHandler returns one page of links. Supported query parameters:
+// limit, cursor, active, created_from, created_to (RFC 3339), domain, tag,
+// campaign_id, q (matches slug, title or destination), sort
+// (created|clicks|slug) and order (asc|desc). A clicks sort ranks and shows
+// click counts as of its first page.
+//
+// Clients written before pagination call GET /api/links without parameters
+// and expect every link as a bare array. They still get that, marked
+// deprecated; any parameter selects the paginated response.
 func (lh *LinkHandler) ListLinksHandler(w http.ResponseWriter, r *http.Request, userID string) {
-	if cached, ok := lh.Cache.Get(userID); ok {
-		if links, ok := cached.([]model.Link); ok {
-			w.Header().Set("Content-Type", "application/json")
-			_ = json.NewEncoder(w).Encode(links)
-			return
-		}
+	if r.URL.RawQuery == "" {
+		lh.listAllLinks(w, r, userID)
+		return
+	}
+	q, ok := parseLinkQuery(w, r)
+	if !ok {
+		return
 	}
+	page, err := lh.LinkService.QueryLinks(r.Context(), userID, q)
+	if err == link.ErrInvalidQuery {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid sort, order or cursor")
+		return
+	} else if err != nil {
+		logger.Error("ListLinksHandler: failed to fetch links: %v", err)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to fetch links")
+		return
+	}
+	utils.WriteJSON(w, http.StatusOK, page)
+}
+
+// listAllLinks serves the pre-pagination response of GET /api/links.
+func (lh *LinkHandler) listAllLinks(w http.ResponseWriter, r *http.Request, userID string) {
 	links, err := lh.LinkService.ListLinks(r.Context(), userID)
 	if err != nil {
-		logger.Error("ListLinksHandler: failed to fetch links: %v", err)
+		logger.Error("listAllLinks: failed to fetch links: %v", err)
 		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to fetch links")
 		return
 	}
-	lh.Cache.Add(userID, links)
-	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(links)
+	w.Header().Set("Deprecation", "true")
+	w.Header().Set("Link", "</api/links?limit="+strconv.Itoa(link.DefaultPageSize)+`>; rel="successor-version"`)
+	utils.WriteJSON(w, http.StatusOK, links)
+}
 
+func parseLinkQuery(w http.ResponseWriter, r *http.Request) (model.LinkQuery, bool) {
+	params := r.URL.Query()
+	q := model.LinkQuery{
+		Limit:      pageSize(r, link.DefaultPageSize, link.MaxPageSize),
+		Cursor:     params.Get("cursor"),
+		Domain:     params.Get("domain"),
+		Tag:        params.Get("tag"),
+		CampaignID: params.Get("campaign_id"),
+		Search:     strings.TrimSpace(params.Get("q")),
+		Sort:       params.Get("sort"),
+		Order:      strings.ToLower(params.Get("order")),
+	}
+	if raw := params.Get("active"); raw != "" {
+		active, err := strconv.ParseBool(raw)
+		if err != nil {
+			utils.WriteJSONError(w, http.StatusBadRequest, "Invalid active filter")
+			return q, false
+		}
+		q.Active = &active
+	}
+	if q.CampaignID != "" && !IsValidUUID(q.CampaignID) {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid campaign_id")
+		return q, false
+	}
+	for name, dst := range map[string]*time.Time{"created_from": &q.CreatedFrom, "created_to": &q.CreatedTo} {
+		if raw := params.Get(name); raw != "" {
+			t, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				utils.WriteJSONError(w, http.StatusBadRequest, "Invalid "+name+" timestamp")
+				return q, false
+			}
+			*dst = t
+		}
+	}
+	return q, true
 }
 
 func (lh *LinkHandler) DeleteLinkHandler(w http.ResponseWriter, r *http.Request, userID string) {
@@ -122,6 +288,14 @@ func (lh *LinkHandler) DeleteLinkHandler(w http.ResponseWriter, r *http.Request,
 		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid or missing link ID")
 		return
 	}
+	before, err := lh.LinkService.GetLink(r.Context(), userID, linkID)
+	if err == link.ErrLinkNotFound {
+		utils.WriteJSONError(w, http.StatusNotFound, "Link not found or unauthorized")
+		return
+	} else if err != nil {
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to delete link")
+		return
+	}
 	if err := lh.LinkService.DeleteLink(r.Context(), userID, linkID); err != nil {
 		if err == link.ErrLinkNotFound {
 			logger.Error("DeleteLinkHandler: link not found or unauthorized: %v", err)
@@ -132,12 +306,77 @@ func (lh *LinkHandler) DeleteLinkHandler(w http.ResponseWriter, r *http.Request,
 		}
 		return
 	}
+	recordAudit(r, lh.Audit, userID, audit.ActionLinkDelete, audit.TargetLink, linkID, before, nil)
 	logger.Info("link deleted")
 	w.WriteHeader(http.StatusNoContent)
 }
 
 func (lh *LinkHandler) UpdateLinkHandler(w http.ResponseWriter, r *http.Request, userID string) {
-	utils.WriteJSONError(w, http.StatusNotImplemented, "Update link not implemented yet")
+	linkID := r.URL.Query().Get("id")
+	if linkID == "" || !IsValidUUID(linkID) {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid or missing link ID")
+		return
+	}
+	var req model.UpdateLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	if (req.Slug != nil && !utils.IsValidSlug(*req.Slug)) || (req.Destination != nil && !utils.IsValidURL(*req.Destination)) {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid format")
+		return
+	}
+	if req.CampaignID != nil && *req.CampaignID != "" && !IsValidUUID(*req.CampaignID) {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid campaign_id")
+		return
+	}
+	if req.Password != nil && *req.Password != "" && !isValidLinkPassword(*req.Password) {
+		utils.WriteJSONError(w, http.StatusBadRequest, linkPasswordMessage)
+		return
+	}
+	if msg := validatePreview(req.Preview); msg != "" {
+		utils.WriteJSONError(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	before, err := lh.LinkService.GetLink(r.Context(), userID, linkID)
+	if err == link.ErrLinkNotFound {
+		utils.WriteJSONError(w, http.StatusNotFound, "Link not found")
+		return
+	} else if err != nil {
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to update link")
+		return
+	}
+
+	updated, err := lh.LinkService.UpdateLink(r.Context(), userID, linkID, req)
+	switch err {
+	case nil:
+	case link.ErrLinkNotFound:
+		utils.WriteJSONError(w, http.StatusNotFound, "Link not found")
+		return
+	case link.ErrSlugAlreadyExists:
+		utils.WriteJSONError(w, http.StatusConflict, "Slug already exists")
+		return
+	case link.ErrCampaignNotFound:
+		utils.WriteJSONError(w, http.StatusBadRequest, "Campaign not found")
+		return
+	case link.ErrLinkDisabledByAdmin:
+		utils.WriteJSONError(w, http.StatusForbidden, "Link was disabled by an admin")
+		return
+	case link.ErrSlugReserved, link.ErrSlugTooShort:
+		writeSlugPolicyError(w, err)
+		return
+	default:
+		if writeDestinationError(w, err) {
+			return
+		}
+		logger.Error("UpdateLinkHandler: failed to update link: %v", err)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to update link")
+		return
+	}
+
+	recordAudit(r, lh.Audit, userID, audit.ActionLinkUpdate, audit.TargetLink, linkID, before, updated)
+	utils.WriteJSON(w, http.StatusOK, updated)
 }
 
 func (lh *LinkHandler) GetLinkHandler(w http.ResponseWriter, r *http.Request, userID string) {
@@ -146,16 +385,59 @@ func (lh *LinkHandler) GetLinkHandler(w http.ResponseWriter, r *http.Request, us
 		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid or missing link ID")
 		return
 	}
-	links, err := lh.LinkService.ListLinks(r.Context(), userID)
+	lk, err := lh.LinkService.GetLink(r.Context(), userID, linkID)
+	if err == link.ErrLinkNotFound {
+		utils.WriteJSONError(w, http.StatusNotFound, "Link not found")
+		return
+	} else if err != nil {
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to retrieve link")
+		return
+	}
+	utils.WriteJSON(w, http.StatusOK, lk)
+}
+
+// SearchLinksHandler ranks the caller's links against ?q= across slug, short
+// code, destination and title.
+func (lh *LinkHandler) SearchLinksHandler(w http.ResponseWriter, r *http.Request, userID string) {
+	term := strings.TrimSpace(r.URL.Query().Get("q"))
+	if term == "" {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Missing search query")
+		return
+	}
+	results, err := lh.LinkService.SearchLinks(r.Context(), userID, term, pageSize(r, 20, link.MaxSearchResults))
 	if err != nil {
-		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to retrieve links")
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Search failed")
 		return
 	}
-	for _, link := range links {
-		if link.LinkID == linkID {
-			_ = json.NewEncoder(w).Encode(link)
-			return
-		}
+	utils.WriteJSON(w, http.StatusOK, results)
+}
+
+// SlugAvailabilityHandler reports whether ?slug= can be claimed and suggests
+// alternatives when it cannot.
+func (lh *LinkHandler) SlugAvailabilityHandler(w http.ResponseWriter, r *http.Request, userID string) {
+	slug := strings.TrimSpace(r.URL.Query().Get("slug"))
+	if slug == "" || !utils.IsValidSlug(slug) {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid or missing slug")
+		return
+	}
+	result, err := lh.LinkService.CheckSlugAvailability(r.Context(), userID, slug)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to check slug")
+		return
+	}
+	utils.WriteJSON(w, http.StatusOK, result)
+}
+
+// writeSlugPolicyError writes a 400 for slugs rejected by the reserved list
+// or the plan's minimum length. It returns false for any other error.
+func writeSlugPolicyError(w http.ResponseWriter, err error) bool {
+	switch err {
+	case link.ErrSlugReserved:
+		utils.WriteJSONError(w, http.StatusBadRequest, "Slug is reserved")
+	case link.ErrSlugTooShort:
+		utils.WriteJSONError(w, http.StatusBadRequest, "Slug is too short for your plan")
+	default:
+		return false
 	}
-	utils.WriteJSONError(w, http.StatusNotFound, "Link not found")
+	return true
 }
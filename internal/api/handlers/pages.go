@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"embed"
+	"html/template"
+	"net/http"
+	"time"
+
+	"redo.ai/logger"
+)
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+var pages = template.Must(template.ParseFS(templateFS, "templates/*.html"))
+
+// renderPage writes an HTML page that must not be cached or framed.
+func renderPage(w http.ResponseWriter, status int, name string, data any) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Frame-Options", "DENY")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	w.WriteHeader(status)
+	if err := pages.ExecuteTemplate(w, name, data); err != nil {
+		logger.Error("renderPage: %s: %v", name, err)
+	}
+}
+
+func renderPasswordPrompt(w http.ResponseWriter, r *http.Request, status int, message string) {
+	renderPage(w, status, "password.html", struct {
+		Action string
+		Error  string
+	}{Action: r.URL.RequestURI(), Error: message})
+}
+
+// renderComingSoon is served in place of the redirect until a scheduled link
+// goes live. Visits are not counted as clicks.
+func renderComingSoon(w http.ResponseWriter, activatesAt time.Time) {
+	renderPage(w, http.StatusOK, "coming_soon.html", struct {
+		ActivatesAt string
+	}{ActivatesAt: activatesAt.UTC().Format(time.RFC3339)})
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"redo.ai/internal/model"
+	"redo.ai/internal/pkg/ogmeta"
+)
+
+// previewCrawlers are User-Agent fragments of the bots that build link
+// previews for social networks and messengers. Search engine crawlers are
+// not listed; they follow the redirect like visitors.
+var previewCrawlers = []string{
+	"facebookexternalhit", "facebot", "twitterbot", "linkedinbot",
+	"slackbot-linkexpanding", "slack-imgproxy", "discordbot", "whatsapp",
+	"telegrambot", "pinterestbot", "redditbot", "skypeuripreview",
+	"embedly", "iframely", "vkshare", "mastodon", "bluesky",
+}
+
+// isPreviewCrawler reports whether the request comes from a link preview
+// bot.
+func isPreviewCrawler(r *http.Request) bool {
+	ua := strings.ToLower(r.UserAgent())
+	for _, bot := range previewCrawlers {
+		if strings.Contains(ua, bot) {
+			return true
+		}
+	}
+	return false
+}
+
+// validatePreview checks a share preview from the API and returns a message
+// for the client, or "" when it is acceptable.
+func validatePreview(p *model.LinkPreview) string {
+	if p == nil {
+		return ""
+	}
+	p.Title = strings.TrimSpace(p.Title)
+	p.Description = strings.TrimSpace(p.Description)
+	p.Image = strings.TrimSpace(p.Image)
+	switch {
+	case len(p.Title) > ogmeta.MaxTitleLength:
+		return fmt.Sprintf("Preview title must be at most %d bytes", ogmeta.MaxTitleLength)
+	case len(p.Description) > ogmeta.MaxDescriptionLength:
+		return fmt.Sprintf("Preview description must be at most %d bytes", ogmeta.MaxDescriptionLength)
+	case p.Image != "" && (len(p.Image) > ogmeta.MaxImageURLLength || !isImageURL(p.Image)):
+		return "Invalid preview image URL"
+	}
+	return ""
+}
+
+// renderPreview serves the link's Open Graph card to a preview crawler. No
+// click is recorded. Password-protected links only carry a card their owner
+// wrote, and the destination is left out so the card cannot reveal it.
+func (lh *LinkHandler) renderPreview(w http.ResponseWriter, r *http.Request, rl model.ResolvedLink) {
+	data := struct {
+		model.LinkPreview
+		URL         string
+		Destination string
+	}{LinkPreview: rl.Preview}
+	data.URL, _ = lh.shortURL(rl.ShortCode, "")
+	if !rl.PasswordProtected {
+		data.Destination = rl.Destination
+	}
+	renderPage(w, http.StatusOK, "preview.html", data)
+}
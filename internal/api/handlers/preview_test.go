@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"testing"
+
+	"redo.ai/internal/model"
+)
+
+func TestValidatePreviewImage(t *testing.T) {
+	for _, image := range []string{
+		"javascript:alert(1)",
+		"data:image/png;base64,AAAA",
+		"ftp://example.com/a.png",
+		"/relative.png",
+	} {
+		if msg := validatePreview(&model.LinkPreview{Image: image}); msg == "" {
+			t.Errorf("validatePreview accepted image %q", image)
+		}
+	}
+	p := &model.LinkPreview{Title: " Title ", Image: " https://cdn.example/a.png "}
+	if msg := validatePreview(p); msg != "" {
+		t.Errorf("validatePreview rejected a valid preview: %s", msg)
+	}
+	if p.Title != "Title" || p.Image != "https://cdn.example/a.png" {
+		t.Errorf("validatePreview did not trim: %+v", p)
+	}
+}
@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"bytes"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"redo.ai/internal/model"
+	"redo.ai/internal/pkg/qrcode"
+	"redo.ai/internal/service/link"
+	"redo.ai/internal/utils"
+	"redo.ai/logger"
+)
+
+const (
+	defaultQRSize = 512
+	minQRSize     = 64
+	maxQRSize     = 4096
+	maxQRMargin   = 16
+	maxLogoBytes  = 512 << 10
+	maxLogoPixels = 1024
+)
+
+// LinkQRHandler renders a QR code for a link's short URL. GET takes the
+// options as query parameters; POST additionally accepts a multipart "logo"
+// image for the centre of the code.
+//
+//	format  png (default) or svg
+//	size    output width in pixels, 64 to 4096 (default 512)
+//	margin  quiet zone in modules, 0 to 16 (default 4)
+//	level   error correction: L, M (default), Q or H; a logo forces H
+//	fg, bg  colours as hex, default 000000 on ffffff
+//
+// The encoded URL carries ?src=qr so scans are attributed separately.
+func (lh *LinkHandler) LinkQRHandler(w http.ResponseWriter, r *http.Request, userID, linkID string) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		utils.WriteJSONError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+		return
+	}
+	params := r.URL.Query()
+	format := params.Get("format")
+	if format == "" {
+		format = "png"
+	}
+	if format != "png" && format != "svg" {
+		utils.WriteJSONError(w, http.StatusBadRequest, "format must be png or svg")
+		return
+	}
+
+	opts := qrcode.Options{Size: defaultQRSize, Margin: 4}
+	for name, dst := range map[string]*int{"size": &opts.Size, "margin": &opts.Margin} {
+		if raw := params.Get(name); raw != "" {
+			n, err := strconv.Atoi(raw)
+			if err != nil {
+				utils.WriteJSONError(w, http.StatusBadRequest, "Invalid "+name)
+				return
+			}
+			*dst = n
+		}
+	}
+	if opts.Size < minQRSize || opts.Size > maxQRSize {
+		utils.WriteJSONError(w, http.StatusBadRequest, "size must be between 64 and 4096")
+		return
+	}
+	if opts.Margin < 0 || opts.Margin > maxQRMargin {
+		utils.WriteJSONError(w, http.StatusBadRequest, "margin must be between 0 and 16")
+		return
+	}
+	level := qrcode.Medium
+	if raw := params.Get("level"); raw != "" {
+		var err error
+		if level, err = qrcode.ParseLevel(raw); err != nil {
+			utils.WriteJSONError(w, http.StatusBadRequest, "level must be L, M, Q or H")
+			return
+		}
+	}
+	if raw := params.Get("fg"); raw != "" {
+		c, err := qrcode.ParseColor(raw)
+		if err != nil {
+			utils.WriteJSONError(w, http.StatusBadRequest, "Invalid fg color")
+			return
+		}
+		opts.Foreground = c
+	}
+	if raw := params.Get("bg"); raw != "" {
+		c, err := qrcode.ParseColor(raw)
+		if err != nil {
+			utils.WriteJSONError(w, http.StatusBadRequest, "Invalid bg color")
+			return
+		}
+		opts.Background = c
+	}
+	if r.Method == http.MethodPost {
+		logo, msg := readLogo(w, r)
+		if msg != "" {
+			utils.WriteJSONError(w, http.StatusBadRequest, msg)
+			return
+		}
+		opts.Logo = logo
+		level = qrcode.High
+	}
+
+	lk, err := lh.LinkService.GetLink(r.Context(), userID, linkID)
+	if err == link.ErrLinkNotFound {
+		utils.WriteJSONError(w, http.StatusNotFound, "Link not found")
+		return
+	} else if err != nil {
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to retrieve link")
+		return
+	}
+
+	target, ok := lh.shortURL(lk.ShortCode, model.ClickSourceQR)
+	if !ok {
+		utils.WriteJSONError(w, http.StatusServiceUnavailable, "QR codes are not configured")
+		return
+	}
+	code, err := qrcode.Encode([]byte(target), level)
+	if err != nil {
+		logger.Error("LinkQRHandler: encode failed for link %s: %v", linkID, err)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to generate QR code")
+		return
+	}
+	var buf bytes.Buffer
+	contentType := "image/png"
+	if format == "svg" {
+		contentType = "image/svg+xml"
+		err = code.SVG(&buf, opts)
+	} else {
+		err = code.PNG(&buf, opts)
+	}
+	if err != nil {
+		logger.Error("LinkQRHandler: render failed for link %s: %v", linkID, err)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to generate QR code")
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", `inline; filename="`+lk.ShortCode+`.`+format+`"`)
+	w.Header().Set("Cache-Control", "private, max-age=300")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(buf.Bytes())
+}
+
+// readLogo decodes the "logo" form file, refusing large uploads and images
+// whose dimensions would be expensive to decode. It returns a client-facing
+// message on failure.
+func readLogo(w http.ResponseWriter, r *http.Request) (image.Image, string) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxLogoBytes+64<<10)
+	file, _, err := r.FormFile("logo")
+	if err != nil {
+		return nil, "Missing or oversized logo file"
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, maxLogoBytes+1))
+	if err != nil || len(data) > maxLogoBytes {
+		return nil, "Logo must be at most 512 KB"
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "Logo must be a PNG, JPEG or GIF image"
+	}
+	if cfg.Width > maxLogoPixels || cfg.Height > maxLogoPixels {
+		return nil, "Logo must be at most 1024x1024 pixels"
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "Logo must be a PNG, JPEG or GIF image"
+	}
+	return img, ""
+}
+
+// shortURL builds the public URL for a short code, tagged with a click
+// source when src is set. It reports false when ShortURLBase is not
+// configured; the request's Host header is never used, since a client could
+// point it anywhere.
+func (lh *LinkHandler) shortURL(shortCode, src string) (string, bool) {
+	if lh.ShortURLBase == "" {
+		return "", false
+	}
+	u := lh.ShortURLBase + "/go/" + url.PathEscape(shortCode)
+	if src != "" {
+		u += "?src=" + url.QueryEscape(src)
+	}
+	return u, true
+}
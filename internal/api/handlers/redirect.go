@@ -1,11 +1,21 @@
 package handlers
 
 import (
+	"context"
+	"net"
 	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/google/uuid"
+	"redo.ai/internal/model"
+	"redo.ai/internal/pkg/platform"
 	"redo.ai/internal/service/link"
 	"redo.ai/internal/utils"
+	"redo.ai/logger"
 )
 
 // func (lh *LinkHandler) RedirectHandler() http.HandlerFunc {
@@ -55,25 +65,247 @@ import (
 
 func (lh *LinkHandler) RedirectHandler() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		shortCode := strings.TrimPrefix(r.URL.Path, "/r/")
+		shortCode := strings.TrimPrefix(r.URL.Path, "/go/")
 		if shortCode == "" {
 			utils.WriteJSONError(w, http.StatusBadRequest, "Missing short code")
 			return
 		}
-		go func() {
-			ip := r.RemoteAddr
-			ref := r.Referer()
-			ua := r.UserAgent()
-			_ = lh.LinkService.TrackClick(r.Context(), shortCode, ip, ref, ua)
-		}()
-		destination, _, err := lh.LinkService.ResolveLink(r.Context(), shortCode)
+		rl, err := lh.LinkService.ResolveLink(r.Context(), shortCode)
 		if err == link.ErrLinkNotFound {
 			utils.WriteJSONError(w, http.StatusNotFound, "Link not found")
 			return
+		} else if err == link.ErrLinkDisabled {
+			utils.WriteJSONError(w, http.StatusGone, "Link disabled")
+			return
 		} else if err != nil {
 			utils.WriteJSONError(w, http.StatusInternalServerError, "Could not resolve link")
 			return
 		}
-		http.Redirect(w, r, destination, http.StatusFound)
+
+		if rl.ActivatesAt != nil {
+			renderComingSoon(w, *rl.ActivatesAt)
+			return
+		}
+		if !rl.Preview.IsZero() && isPreviewCrawler(r) {
+			lh.renderPreview(w, r, rl)
+			return
+		}
+		if rl.PasswordProtected && !lh.hasLinkAccess(r, rl) {
+			if r.Method == http.MethodPost {
+				lh.unlockLink(w, r, rl)
+			} else {
+				renderPasswordPrompt(w, r, http.StatusOK, "")
+			}
+			return
+		}
+
+		lh.visit(w, r, rl, http.StatusFound)
+	}
+}
+
+// visit sends the visitor on to the first matching routing rule, their A/B
+// variant or the link's destination, and records the click. Mobile visitors
+// are sent into an app when one can take the link.
+func (lh *LinkHandler) visit(w http.ResponseWriter, r *http.Request, rl model.ResolvedLink, status int) {
+	destination := rl.Destination
+	var variantID string
+	if d, ok := lh.matchRule(r, rl); ok {
+		destination = d
+	} else if v, ok := pickVariant(w, r, rl); ok {
+		destination, variantID = v.Destination, v.ID
+	}
+	ev := lh.clickEvent(r, rl, variantID)
+	if rl.AppendClickID && lh.ClickSigner != nil {
+		// The destination may report a conversion as soon as it loads, so
+		// the click has to exist before the visitor gets there.
+		ev.ID = uuid.NewString()
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), clickTrackTimeout)
+		err := lh.LinkService.TrackClick(ctx, ev)
+		cancel()
+		if err == nil {
+			destination = withClickID(destination, signClickID(lh.ClickSigner, ev.ID))
+		}
+	} else {
+		lh.trackClick(ev)
+	}
+	http.Redirect(w, r, lh.appDestination(r, destination), status)
+}
+
+// appDestination returns where a mobile visitor should go to open the link
+// in an app, with destination as the web fallback. An Android app registered
+// for the custom domain the link was opened on gets the short link itself,
+// as the App Link the OS did not open; otherwise destinations the deep-link
+// registry knows become the service's deep link. iOS apps of the domain get
+// nothing here: iOS never hands a redirect within the same domain to the
+// app, so the universal link has already been passed over.
+func (lh *LinkHandler) appDestination(r *http.Request, destination string) string {
+	if lh.Platform == nil {
+		return destination
+	}
+	p := lh.Platform.DetectOs(r.UserAgent())
+	if p == platform.PlatformWeb {
+		return destination
+	}
+	if p == platform.PlatformAndroid {
+		if pkg := lh.domainApp(r, model.AppPlatformAndroid); pkg != "" {
+			return androidAppLink(r, pkg, destination)
+		}
+	}
+	return lh.Platform.GenerateDeepLink(p, lh.Platform.GetService(destination), destination)
+}
+
+// domainApp returns the ID of the first app registered for the request's
+// custom domain on a platform, or "" when there is none. Lookup failures
+// only cost the visitor the app.
+func (lh *LinkHandler) domainApp(r *http.Request, appPlatform string) string {
+	if lh.AppLinks == nil {
+		return ""
+	}
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.TrimSuffix(host, ".")
+	if u, err := url.Parse(lh.ShortURLBase); err == nil && strings.EqualFold(u.Hostname(), host) {
+		return ""
+	}
+	apps, err := lh.AppLinks.AppsForHost(r.Context(), host)
+	if err != nil {
+		logger.Error("domainApp: failed to load apps for %s: %v", host, err)
+		return ""
+	}
+	for _, a := range apps {
+		if a.Platform == appPlatform {
+			return a.AppID
+		}
+	}
+	return ""
+}
+
+// androidAppLink builds an intent that opens the short link in the Android
+// app pkg, or sends the browser to destination when it is not installed.
+func androidAppLink(r *http.Request, pkg, destination string) string {
+	return "intent://" + r.Host + r.URL.RequestURI() +
+		"#Intent;scheme=https;package=" + pkg +
+		";S.browser_fallback_url=" + url.QueryEscape(destination) + ";end"
+}
+
+// clickTrackTimeout bounds how long a redirect waits for its click to be
+// recorded.
+const clickTrackTimeout = 2 * time.Second
+
+func (lh *LinkHandler) clickEvent(r *http.Request, rl model.ResolvedLink, variantID string) model.ClickEvent {
+	return model.ClickEvent{
+		ShortCode: rl.ShortCode,
+		VariantID: variantID,
+		IP:        r.RemoteAddr,
+		Referrer:  r.Referer(),
+		UserAgent: r.UserAgent(),
+		Source:    clickSource(r),
+	}
+}
+
+// trackClick records the visit in the background. The request context is
+// cancelled once the redirect is written, so tracking runs on its own.
+func (lh *LinkHandler) trackClick(ev model.ClickEvent) {
+	go func() {
+		_ = lh.LinkService.TrackClick(context.Background(), ev)
+	}()
+}
+
+// clickSource returns the ?src marker when it is one we attribute, so
+// arbitrary values cannot pollute the source breakdown.
+func clickSource(r *http.Request) string {
+	switch src := r.URL.Query().Get("src"); src {
+	case model.ClickSourceQR, model.ClickSourceBio:
+		return src
+	}
+	return ""
+}
+
+// unlockLink checks a submitted link password. Failures are throttled per
+// link and visitor IP, and slowed down per link and source network. The
+// visitor IP only comes from X-Forwarded-For behind a trusted proxy, so it
+// cannot be rotated through the header.
+func (lh *LinkHandler) unlockLink(w http.ResponseWriter, r *http.Request, rl model.ResolvedLink) {
+	now := time.Now()
+	ip := utils.ClientIP(r)
+	visitorKey := rl.ID + "|" + ip
+	networkKey := rl.ID + "|" + sourceNetwork(ip)
+	wait, blocked := lh.PasswordAttempts.Blocked(visitorKey, now)
+	if !blocked {
+		wait, blocked = lh.PasswordBackoff.Wait(networkKey, now)
+	}
+	if blocked {
+		w.Header().Set("Retry-After", strconv.Itoa(int(wait.Seconds())+1))
+		renderPasswordPrompt(w, r, http.StatusTooManyRequests, "Too many attempts. Try again later.")
+		return
+	}
+
+	err := lh.LinkService.CheckLinkPassword(r.Context(), rl.ID, r.PostFormValue("password"))
+	if err == link.ErrWrongPassword {
+		lh.PasswordAttempts.Fail(visitorKey, now)
+		lh.PasswordBackoff.Fail(networkKey, now)
+		logger.Warn("unlockLink: wrong password for link %s from %s", rl.ID, ip)
+		renderPasswordPrompt(w, r, http.StatusUnauthorized, "Incorrect password.")
+		return
+	} else if err != nil {
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Could not verify password")
+		return
 	}
+	lh.PasswordAttempts.Reset(visitorKey)
+	lh.PasswordBackoff.Reset(networkKey)
+
+	if lh.AccessSigner != nil {
+		expires := now.Add(linkAccessTTL)
+		http.SetCookie(w, &http.Cookie{
+			Name:     linkAccessCookie(rl),
+			Value:    lh.AccessSigner.SignExpiring(linkAccessPayload(rl), expires),
+			Path:     "/go/" + rl.ShortCode,
+			Expires:  expires,
+			HttpOnly: true,
+			Secure:   r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https",
+			SameSite: http.SameSiteLaxMode,
+		})
+	}
+	lh.visit(w, r, rl, http.StatusSeeOther)
+}
+
+// sourceNetwork groups an address with its neighbours: the /24 for IPv4 and
+// the /64 for IPv6, which one subscriber usually controls entirely.
+func sourceNetwork(ip string) string {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ip
+	}
+	addr = addr.Unmap()
+	bits := 64
+	if addr.Is4() {
+		bits = 24
+	}
+	prefix, _ := addr.Prefix(bits)
+	return prefix.String()
+}
+
+// linkAccessTTL is how long a correct password is remembered.
+const linkAccessTTL = time.Hour
+
+func (lh *LinkHandler) hasLinkAccess(r *http.Request, rl model.ResolvedLink) bool {
+	if lh.AccessSigner == nil {
+		return false
+	}
+	c, err := r.Cookie(linkAccessCookie(rl))
+	if err != nil {
+		return false
+	}
+	return lh.AccessSigner.VerifyExpiring(linkAccessPayload(rl), c.Value, time.Now())
+}
+
+func linkAccessCookie(rl model.ResolvedLink) string {
+	return "redo_access_" + rl.ShortCode
+}
+
+// linkAccessPayload ties the cookie to the link and its current password.
+func linkAccessPayload(rl model.ResolvedLink) string {
+	return "link-access|" + rl.ID + "|" + strconv.FormatInt(rl.PasswordUpdatedAt.UnixNano(), 10)
 }
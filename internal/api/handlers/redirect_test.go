@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"redo.ai/internal/model"
+	"redo.ai/internal/pkg/platform"
+	"redo.ai/internal/service/applink"
+	"redo.ai/internal/service/link"
+)
+
+// redirectLinks resolves every short code to one link.
+type redirectLinks struct {
+	link.LinkService
+	rl model.ResolvedLink
+}
+
+func (f redirectLinks) ResolveLink(context.Context, string) (model.ResolvedLink, error) {
+	return f.rl, nil
+}
+
+func (f redirectLinks) TrackClick(context.Context, model.ClickEvent) error { return nil }
+
+// domainApps serves AppsForHost from a map keyed by host.
+type domainApps struct {
+	applink.AppLinkService
+	apps map[string][]model.AppAssociation
+}
+
+func (f domainApps) AppsForHost(_ context.Context, host string) ([]model.AppAssociation, error) {
+	return f.apps[host], nil
+}
+
+func TestRedirectHandlerOpensApps(t *testing.T) {
+	const (
+		android = "Mozilla/5.0 (Linux; Android 14; Pixel 8) Chrome/126.0 Mobile Safari/537.36"
+		iphone  = "Mozilla/5.0 (iPhone; CPU iPhone OS 17_5 like Mac OS X) Mobile/15E148 Safari/604.1"
+		desktop = "Mozilla/5.0 (X11; Linux x86_64) Chrome/126.0 Safari/537.36"
+		track   = "https://open.spotify.com/track/4uLU6hMCjMI75M1A2tKUQC"
+		shop    = "https://shop.example/sale"
+	)
+	apps := domainApps{apps: map[string][]model.AppAssociation{
+		"links.example.com": {
+			{Platform: model.AppPlatformIOS, AppID: "ABCDE12345.com.example.app"},
+			{Platform: model.AppPlatformAndroid, AppID: "com.example.app"},
+		},
+		// The short URL host is never looked up, so this cannot apply.
+		"redo.ai": {{Platform: model.AppPlatformAndroid, AppID: "com.example.stale"}},
+	}}
+	tests := []struct {
+		name, host, ua, dest, want string
+	}{
+		{"android app on custom domain", "links.example.com", android, shop,
+			"intent://links.example.com/go/sale?src=qr#Intent;scheme=https;package=com.example.app;S.browser_fallback_url=https%3A%2F%2Fshop.example%2Fsale;end"},
+		{"custom domain port ignored", "links.example.com:443", android, shop,
+			"intent://links.example.com:443/go/sale?src=qr#Intent;scheme=https;package=com.example.app;S.browser_fallback_url=https%3A%2F%2Fshop.example%2Fsale;end"},
+		{"android service deep link", "redo.ai", android, track,
+			"intent://track/4uLU6hMCjMI75M1A2tKUQC#Intent;scheme=spotify;package=com.spotify.music;S.browser_fallback_url=https%3A%2F%2Fopen.spotify.com%2Ftrack%2F4uLU6hMCjMI75M1A2tKUQC;end"},
+		{"ios service deep link", "links.example.com", iphone, track, "spotify:track:4uLU6hMCjMI75M1A2tKUQC"},
+		{"ios custom domain falls back to web", "links.example.com", iphone, shop, shop},
+		{"android unknown destination", "redo.ai", android, shop, shop},
+		{"desktop", "links.example.com", desktop, track, track},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lh := &LinkHandler{
+				LinkService:  redirectLinks{rl: model.ResolvedLink{ID: "l1", ShortCode: "sale", Destination: tt.dest}},
+				Platform:     &platform.DefaultPlatformDetector{},
+				AppLinks:     apps,
+				ShortURLBase: "https://redo.ai",
+			}
+			req := httptest.NewRequest(http.MethodGet, "/go/sale?src=qr", nil)
+			req.Host = tt.host
+			req.Header.Set("User-Agent", tt.ua)
+			rec := httptest.NewRecorder()
+			lh.RedirectHandler()(rec, req)
+
+			if rec.Code != http.StatusFound {
+				t.Fatalf("status = %d, want 302", rec.Code)
+			}
+			if got := rec.Header().Get("Location"); got != tt.want {
+				t.Errorf("Location = %q\nwant       %q", got, tt.want)
+			}
+		})
+	}
+}
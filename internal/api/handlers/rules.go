@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"redo.ai/internal/model"
+	"redo.ai/internal/pkg/platform"
+	"redo.ai/internal/pkg/rules"
+	"redo.ai/internal/service/audit"
+	"redo.ai/internal/service/link"
+	"redo.ai/internal/utils"
+)
+
+// defaultCountryHeader is set by Cloudflare; other proxies can be configured
+// through LinkHandler.CountryHeader.
+const defaultCountryHeader = "CF-IPCountry"
+
+// LinkRulesHandler serves GET and PUT /api/links/{id}/rules. PUT replaces
+// the ordered rule list; an empty list removes conditional routing.
+func (lh *LinkHandler) LinkRulesHandler(w http.ResponseWriter, r *http.Request, userID, linkID string) {
+	switch r.Method {
+	case http.MethodGet:
+		list, err := lh.LinkService.GetRules(r.Context(), userID, linkID)
+		if writeRulesError(w, err) {
+			return
+		}
+		utils.WriteJSON(w, http.StatusOK, list)
+	case http.MethodPut:
+		var req model.SetRulesRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			utils.WriteJSONError(w, http.StatusBadRequest, "Invalid request payload")
+			return
+		}
+		if err := rules.Validate(req.Rules); err != nil {
+			writeErrorMessage(w, http.StatusBadRequest, "Invalid rules: "+err.Error())
+			return
+		}
+		for i, rule := range req.Rules {
+			if !utils.IsValidURL(rule.Destination) {
+				utils.WriteJSONError(w, http.StatusBadRequest, fmt.Sprintf("Invalid rules: rule %d: invalid destination", i+1))
+				return
+			}
+		}
+		before, err := lh.LinkService.GetRules(r.Context(), userID, linkID)
+		if writeRulesError(w, err) {
+			return
+		}
+		saved, err := lh.LinkService.SetRules(r.Context(), userID, linkID, req.Rules)
+		if writeDestinationError(w, err) || writeRulesError(w, err) {
+			return
+		}
+		recordAudit(r, lh.Audit, userID, audit.ActionLinkRules, audit.TargetLink, linkID, before, saved)
+		utils.WriteJSON(w, http.StatusOK, saved)
+	default:
+		utils.WriteJSONError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+	}
+}
+
+func writeRulesError(w http.ResponseWriter, err error) bool {
+	switch err {
+	case nil:
+		return false
+	case link.ErrLinkNotFound:
+		utils.WriteJSONError(w, http.StatusNotFound, "Link not found")
+	default:
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to process rules")
+	}
+	return true
+}
+
+// matchRule returns the destination of the first rule matching the request.
+func (lh *LinkHandler) matchRule(r *http.Request, rl model.ResolvedLink) (string, bool) {
+	if len(rl.Rules) == 0 {
+		return "", false
+	}
+	header := lh.CountryHeader
+	if header == "" {
+		header = defaultCountryHeader
+	}
+	var detector platform.PlatformDetector = &platform.DefaultPlatformDetector{}
+	if lh.Platform != nil {
+		detector = lh.Platform
+	}
+	rule, _, ok := rules.Match(rl.Rules, rules.Visit{
+		Country:   strings.TrimSpace(r.Header.Get(header)),
+		Languages: rules.ParseAcceptLanguage(r.Header.Get("Accept-Language")),
+		Device:    string(detector.DetectOs(r.UserAgent())),
+		Referrer:  r.Referer(),
+		Query:     r.URL.Query(),
+		Time:      time.Now(),
+	})
+	return rule.Destination, ok
+}
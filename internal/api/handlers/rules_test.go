@@ -0,0 +1,22 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLinkRulesHandlerEncodesValidationErrors(t *testing.T) {
+	body := `{"rules":[{"destination":"https://a.example","countries":["1X"]}]}`
+	req := httptest.NewRequest(http.MethodPut, "/api/links/l1/rules", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	(&LinkHandler{}).LinkRulesHandler(rec, req, "u1", "l1")
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", rec.Code)
+	}
+	if msg := decodeErrorBody(t, rec.Body.Bytes()); msg != `Invalid rules: rule 1: invalid country "1X"` {
+		t.Errorf("message = %q", msg)
+	}
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"redo.ai/internal/model"
+	"redo.ai/internal/service/audit"
+	"redo.ai/internal/service/link"
+	"redo.ai/internal/utils"
+)
+
+// LinkSchedulesHandler serves /api/links/{id}/schedules. GET lists the
+// link's destination changes, POST queues one and DELETE with
+// ?schedule_id= cancels a pending one.
+func (lh *LinkHandler) LinkSchedulesHandler(w http.ResponseWriter, r *http.Request, userID, linkID string) {
+	switch r.Method {
+	case http.MethodGet:
+		list, err := lh.LinkService.ListSchedules(r.Context(), userID, linkID)
+		if writeScheduleError(w, err) {
+			return
+		}
+		utils.WriteJSON(w, http.StatusOK, list)
+	case http.MethodPost:
+		var req model.CreateScheduleRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			utils.WriteJSONError(w, http.StatusBadRequest, "Invalid request payload")
+			return
+		}
+		if !utils.IsValidURL(req.Destination) {
+			utils.WriteJSONError(w, http.StatusBadRequest, "Invalid destination")
+			return
+		}
+		if !req.RunAt.After(time.Now()) {
+			utils.WriteJSONError(w, http.StatusBadRequest, "run_at must be in the future")
+			return
+		}
+		sc, err := lh.LinkService.CreateSchedule(r.Context(), userID, linkID, req)
+		if writeDestinationError(w, err) || writeScheduleError(w, err) {
+			return
+		}
+		recordAudit(r, lh.Audit, userID, audit.ActionLinkSchedule, audit.TargetLink, linkID, nil, sc)
+		utils.WriteJSON(w, http.StatusCreated, sc)
+	case http.MethodDelete:
+		scheduleID := r.URL.Query().Get("schedule_id")
+		if !IsValidUUID(scheduleID) {
+			utils.WriteJSONError(w, http.StatusBadRequest, "Invalid schedule_id")
+			return
+		}
+		sc, err := lh.LinkService.CancelSchedule(r.Context(), userID, linkID, scheduleID)
+		if writeScheduleError(w, err) {
+			return
+		}
+		before := sc
+		before.Status = model.SchedulePending
+		recordAudit(r, lh.Audit, userID, audit.ActionLinkSchedule, audit.TargetLink, linkID, before, sc)
+		utils.WriteJSON(w, http.StatusOK, sc)
+	default:
+		utils.WriteJSONError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+	}
+}
+
+func writeScheduleError(w http.ResponseWriter, err error) bool {
+	switch err {
+	case nil:
+		return false
+	case link.ErrLinkNotFound:
+		utils.WriteJSONError(w, http.StatusNotFound, "Link not found")
+	case link.ErrScheduleNotFound:
+		utils.WriteJSONError(w, http.StatusNotFound, "Pending schedule not found")
+	case link.ErrTooManySchedules:
+		utils.WriteJSONError(w, http.StatusConflict, fmt.Sprintf("A link can have at most %d pending schedules", link.MaxPendingSchedules))
+	default:
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to process schedules")
+	}
+	return true
+}
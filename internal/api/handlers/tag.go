@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"redo.ai/internal/model"
+	"redo.ai/internal/service/tag"
+	"redo.ai/internal/service/user"
+	"redo.ai/internal/utils"
+)
+
+type TagHandler struct {
+	TagService  tag.TagService
+	UserService user.UserService
+}
+
+func NewTagHandler(tagService tag.TagService, userService user.UserService) *TagHandler {
+	return &TagHandler{
+		TagService:  tagService,
+		UserService: userService,
+	}
+}
+
+// TagsRouter serves /api/tags: GET lists, POST creates, PUT ?id= renames and
+// DELETE ?id= removes a tag from every link.
+func (th *TagHandler) TagsRouter() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := authorizeUser(w, r, th.UserService)
+		if !ok {
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			tags, err := th.TagService.ListTags(r.Context(), userID)
+			if err != nil {
+				utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to fetch tags")
+				return
+			}
+			utils.WriteJSON(w, http.StatusOK, tags)
+		case http.MethodPost:
+			var req model.TagRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				utils.WriteJSONError(w, http.StatusBadRequest, "Invalid request payload")
+				return
+			}
+			t, err := th.TagService.CreateTag(r.Context(), userID, req.Name)
+			if writeTagError(w, err) {
+				return
+			}
+			utils.WriteJSON(w, http.StatusCreated, t)
+		case http.MethodPut:
+			tagID := r.URL.Query().Get("id")
+			if !IsValidUUID(tagID) {
+				utils.WriteJSONError(w, http.StatusBadRequest, "Invalid or missing tag ID")
+				return
+			}
+			var req model.TagRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				utils.WriteJSONError(w, http.StatusBadRequest, "Invalid request payload")
+				return
+			}
+			t, err := th.TagService.RenameTag(r.Context(), userID, tagID, req.Name)
+			if writeTagError(w, err) {
+				return
+			}
+			utils.WriteJSON(w, http.StatusOK, t)
+		case http.MethodDelete:
+			tagID := r.URL.Query().Get("id")
+			if !IsValidUUID(tagID) {
+				utils.WriteJSONError(w, http.StatusBadRequest, "Invalid or missing tag ID")
+				return
+			}
+			if writeTagError(w, th.TagService.DeleteTag(r.Context(), userID, tagID)) {
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			utils.WriteJSONError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+		}
+	}
+}
+
+func writeTagError(w http.ResponseWriter, err error) bool {
+	switch err {
+	case nil:
+		return false
+	case tag.ErrInvalidTag:
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid tag name")
+	case tag.ErrTagExists:
+		utils.WriteJSONError(w, http.StatusConflict, "Tag already exists")
+	case tag.ErrTagNotFound:
+		utils.WriteJSONError(w, http.StatusNotFound, "Tag not found")
+	default:
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to save tag")
+	}
+	return true
+}
@@ -1,6 +1,10 @@
 package handlers
 
-import "time"
+import (
+	"time"
+
+	"redo.ai/internal/model"
+)
 
 // SignUpHandler - creates a new user with provided business name and associates to Auth0 sub (email as identifier).
 type SignUpRequest struct {
@@ -13,10 +17,28 @@ type LoginRequest struct {
 	Email string `json:"email"`
 }
 
+type ChangeEmailRequest struct {
+	Email string `json:"email"`
+}
+
 type UserResponse struct {
-	ID           string    `json:"id"`
-	Email        string    `json:"email"`
-	Name         string    `json:"name"`
-	BusinessName string    `json:"business_name"`
-	CreatedAt    time.Time `json:"created_at"`
+	ID                   string     `json:"id"`
+	Email                string     `json:"email"`
+	Name                 string     `json:"name"`
+	BusinessName         string     `json:"business_name"`
+	Role                 string     `json:"role"`
+	CreatedAt            time.Time  `json:"created_at"`
+	DeletionScheduledFor *time.Time `json:"deletion_scheduled_for,omitempty"`
+}
+
+func newUserResponse(u *model.User) UserResponse {
+	return UserResponse{
+		ID:                   u.UserID,
+		Email:                u.Email,
+		Name:                 u.Name,
+		BusinessName:         u.BusinessName,
+		Role:                 u.Role,
+		CreatedAt:            u.CreatedAt,
+		DeletionScheduledFor: u.DeletionScheduledFor,
+	}
 }
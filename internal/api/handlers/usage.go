@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"net/http"
+
+	"redo.ai/internal/service/usage"
+	"redo.ai/internal/service/user"
+	"redo.ai/internal/utils"
+	"redo.ai/logger"
+)
+
+type UsageHandler struct {
+	UsageService usage.UsageService
+	UserService  user.UserService
+}
+
+func NewUsageHandler(us usage.UsageService, userService user.UserService) *UsageHandler {
+	return &UsageHandler{
+		UsageService: us,
+		UserService:  userService,
+	}
+}
+
+// UsageRouter reports the caller's consumption for the current billing period.
+func (h *UsageHandler) UsageRouter() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !validateMethod(w, r, http.MethodGet) {
+			return
+		}
+		userID, ok := authorizeUser(w, r, h.UserService)
+		if !ok {
+			return
+		}
+
+		current, err := h.UsageService.Current(r.Context(), userID)
+		if err != nil {
+			logger.Error("UsageRouter: failed to load usage: %v", err)
+			utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to load usage")
+			return
+		}
+		utils.WriteJSON(w, http.StatusOK, current)
+	}
+}
@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+	"redo.ai/internal/model"
+	"redo.ai/internal/service/clicks"
+	"redo.ai/internal/service/link"
+	"redo.ai/internal/service/user"
+	"redo.ai/internal/utils"
+	"redo.ai/logger"
+)
+
+type UserHandler struct {
+	Auth         *AuthHandler
+	UserService  user.UserService
+	LinkService  link.LinkService
+	ClickService clicks.ClickService
+	Cache        *lru.Cache
+}
+
+func NewUserHandler(auth *AuthHandler, userService user.UserService, linkService link.LinkService, clickService clicks.ClickService, cache *lru.Cache) *UserHandler {
+	return &UserHandler{
+		Auth:         auth,
+		UserService:  userService,
+		LinkService:  linkService,
+		ClickService: clickService,
+		Cache:        cache,
+	}
+}
+
+// UserRouter serves /api/user and its sub-resources:
+//
+//	POST   /api/user          login, signing up on first visit
+//	GET    /api/user          profile
+//	PATCH  /api/user          update name / business name
+//	DELETE /api/user          schedule account deletion
+//	PUT    /api/user/email    change email
+//	POST   /api/user/restore  cancel a scheduled deletion
+//	GET    /api/user/export   download all links and clicks
+func (uh *UserHandler) UserRouter() http.HandlerFunc {
+	login := uh.Auth.LoginHandler()
+	return func(w http.ResponseWriter, r *http.Request) {
+		action := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/user"), "/")
+		if action == "" && r.Method == http.MethodPost {
+			login(w, r)
+			return
+		}
+
+		sub, ok := verifySubFromContext(w, r)
+		if !ok {
+			return
+		}
+		usr, err := uh.UserService.GetByID(r.Context(), sub)
+		if err == sql.ErrNoRows {
+			utils.WriteJSONError(w, http.StatusNotFound, "User not found")
+			return
+		} else if err != nil {
+			logger.Error("UserRouter: failed to fetch user for sub %s: %v", sub, err)
+			utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to load user")
+			return
+		}
+
+		switch action {
+		case "":
+			switch r.Method {
+			case http.MethodGet:
+				utils.WriteJSON(w, http.StatusOK, newUserResponse(usr))
+			case http.MethodPatch:
+				uh.updateProfile(w, r, sub, usr)
+			case http.MethodDelete:
+				uh.scheduleDeletion(w, r, sub, usr)
+			default:
+				utils.WriteJSONError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+			}
+		case "email":
+			if validateMethod(w, r, http.MethodPut) {
+				uh.changeEmail(w, r, sub, usr)
+			}
+		case "restore":
+			if validateMethod(w, r, http.MethodPost) {
+				uh.cancelDeletion(w, r, sub, usr)
+			}
+		case "export":
+			if validateMethod(w, r, http.MethodGet) {
+				uh.exportAccount(w, r, usr)
+			}
+		default:
+			utils.WriteJSONError(w, http.StatusNotFound, "Not Found")
+		}
+	}
+}
+
+func (uh *UserHandler) updateProfile(w http.ResponseWriter, r *http.Request, sub string, usr *model.User) {
+	var req model.ProfileUpdate
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	updated, err := uh.UserService.UpdateProfile(r.Context(), usr.UserID, req)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to update profile")
+		return
+	}
+	uh.Cache.Remove(sub)
+	utils.WriteJSON(w, http.StatusOK, newUserResponse(updated))
+}
+
+func (uh *UserHandler) changeEmail(w http.ResponseWriter, r *http.Request, sub string, usr *model.User) {
+	var req ChangeEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	email := strings.TrimSpace(req.Email)
+	if !utils.IsValidEmail(email) {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid email")
+		return
+	}
+	updated, err := uh.UserService.ChangeEmail(r.Context(), usr.UserID, email)
+	if err == user.ErrEmailTaken {
+		utils.WriteJSONError(w, http.StatusConflict, "Email already in use")
+		return
+	} else if err != nil {
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to change email")
+		return
+	}
+	uh.Cache.Remove(sub)
+	utils.WriteJSON(w, http.StatusOK, newUserResponse(updated))
+}
+
+func (uh *UserHandler) scheduleDeletion(w http.ResponseWriter, r *http.Request, sub string, usr *model.User) {
+	updated, err := uh.UserService.ScheduleDeletion(r.Context(), usr.UserID)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to delete account")
+		return
+	}
+	uh.Cache.Remove(sub)
+	logger.Info("account %s scheduled for deletion at %v", usr.UserID, updated.DeletionScheduledFor)
+	utils.WriteJSON(w, http.StatusAccepted, newUserResponse(updated))
+}
+
+func (uh *UserHandler) cancelDeletion(w http.ResponseWriter, r *http.Request, sub string, usr *model.User) {
+	if usr.DeletionScheduledFor == nil {
+		utils.WriteJSONError(w, http.StatusConflict, "Account is not scheduled for deletion")
+		return
+	}
+	updated, err := uh.UserService.CancelDeletion(r.Context(), usr.UserID)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to restore account")
+		return
+	}
+	uh.Cache.Remove(sub)
+	utils.WriteJSON(w, http.StatusOK, newUserResponse(updated))
+}
+
+// exportAccount streams the account as one JSON document:
+//
+//	{"user": ..., "exported_at": ..., "links": [...], "clicks": [...]}
+//
+// Links and clicks are written as they are read, so the export never holds
+// the account in memory. Clicks carry their link_id. Once the body has
+// started a failure can only truncate it, which leaves the document invalid
+// rather than silently incomplete.
+func (uh *UserHandler) exportAccount(w http.ResponseWriter, r *http.Request, usr *model.User) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", `attachment; filename="redo-export.json"`)
+	w.WriteHeader(http.StatusOK)
+
+	enc := json.NewEncoder(w)
+	written := 0
+	item := func(v any) error {
+		if written > 0 {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		written++
+		if written%exportFlushEvery == 0 {
+			if f, ok := w.(http.Flusher); ok {
+				f.Flush()
+			}
+		}
+		return enc.Encode(v)
+	}
+
+	err := func() error {
+		if _, err := io.WriteString(w, `{"user":`); err != nil {
+			return err
+		}
+		if err := enc.Encode(usr); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, `,"exported_at":`); err != nil {
+			return err
+		}
+		if err := enc.Encode(time.Now().UTC()); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, `,"links":[`); err != nil {
+			return err
+		}
+		if err := uh.LinkService.StreamLinks(r.Context(), usr.UserID, func(lk model.Link) error { return item(lk) }); err != nil {
+			return err
+		}
+		written = 0
+		if _, err := io.WriteString(w, `],"clicks":[`); err != nil {
+			return err
+		}
+		filter := model.ClickFilter{UserID: usr.UserID}
+		if err := uh.ClickService.StreamClicks(r.Context(), filter, func(c model.Click) error { return item(c) }); err != nil {
+			return err
+		}
+		_, err := io.WriteString(w, "]}\n")
+		return err
+	}()
+	if err != nil {
+		logger.Error("exportAccount: export for %s aborted: %v", usr.UserID, err)
+	}
+}
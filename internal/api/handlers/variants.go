@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"time"
+
+	"redo.ai/internal/model"
+	"redo.ai/internal/pkg/entitlements"
+	"redo.ai/internal/service/audit"
+	"redo.ai/internal/service/link"
+	"redo.ai/internal/utils"
+	"redo.ai/logger"
+)
+
+const maxVariantLabel = 64
+
+// LinkVariantsHandler serves GET and PUT /api/links/{id}/variants. PUT
+// replaces the whole set; an empty list turns the A/B split off.
+func (lh *LinkHandler) LinkVariantsHandler(w http.ResponseWriter, r *http.Request, userID, linkID string) {
+	switch r.Method {
+	case http.MethodGet:
+		variants, err := lh.LinkService.ListVariants(r.Context(), userID, linkID)
+		if writeVariantError(w, err) {
+			return
+		}
+		utils.WriteJSON(w, http.StatusOK, variants)
+	case http.MethodPut:
+		var req model.SetVariantsRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			utils.WriteJSONError(w, http.StatusBadRequest, "Invalid request payload")
+			return
+		}
+		if msg := validateVariants(req.Variants); msg != "" {
+			utils.WriteJSONError(w, http.StatusBadRequest, msg)
+			return
+		}
+		before, err := lh.LinkService.ListVariants(r.Context(), userID, linkID)
+		if writeVariantError(w, err) {
+			return
+		}
+		variants, err := lh.LinkService.SetVariants(r.Context(), userID, linkID, req.Variants)
+		if writeDestinationError(w, err) || writeVariantError(w, err) {
+			return
+		}
+		recordAudit(r, lh.Audit, userID, audit.ActionLinkVariants, audit.TargetLink, linkID, before, variants)
+		utils.WriteJSON(w, http.StatusOK, variants)
+	default:
+		utils.WriteJSONError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+	}
+}
+
+// LinkVariantStatsHandler serves GET /api/links/{id}/variants/stats, the
+// clicks and conversions of each variant within the plan's analytics window.
+func (lh *LinkHandler) LinkVariantStatsHandler(w http.ResponseWriter, r *http.Request, userID, linkID string) {
+	usr, err := lh.UserService.GetByUserID(r.Context(), userID)
+	if err != nil {
+		logger.Error("variant stats: failed to fetch user: %v", err)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to verify user")
+		return
+	}
+	ent := entitlements.For(usr.Role)
+	if writeEntitlementError(w, ent.RequireFeature(entitlements.FeatureAnalytics)) {
+		return
+	}
+	stats, err := lh.LinkService.VariantStats(r.Context(), userID, linkID, ent.AnalyticsSince(time.Now().UTC()))
+	if writeVariantError(w, err) {
+		return
+	}
+	utils.WriteJSON(w, http.StatusOK, stats)
+}
+
+func validateVariants(variants []model.LinkVariantInput) string {
+	if len(variants) > link.MaxVariants {
+		return fmt.Sprintf("A link can have at most %d variants", link.MaxVariants)
+	}
+	seen := make(map[string]bool, len(variants))
+	for _, v := range variants {
+		switch {
+		case v.ID != "" && !IsValidUUID(v.ID):
+			return "Invalid variant id"
+		case v.ID != "" && seen[v.ID]:
+			return "Duplicate variant id"
+		case !utils.IsValidURL(v.Destination):
+			return "Invalid variant destination"
+		case v.Weight < 1 || v.Weight > link.MaxVariantWeight:
+			return fmt.Sprintf("Variant weight must be between 1 and %d", link.MaxVariantWeight)
+		case len(v.Label) > maxVariantLabel:
+			return "Variant label is too long"
+		}
+		seen[v.ID] = true
+	}
+	return ""
+}
+
+func writeVariantError(w http.ResponseWriter, err error) bool {
+	switch err {
+	case nil:
+		return false
+	case link.ErrLinkNotFound:
+		utils.WriteJSONError(w, http.StatusNotFound, "Link not found")
+	case link.ErrVariantNotFound:
+		utils.WriteJSONError(w, http.StatusNotFound, "Variant not found")
+	default:
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to process variants")
+	}
+	return true
+}
+
+// variantCookieTTL keeps a visitor on the same variant for repeat visits.
+const variantCookieTTL = 30 * 24 * time.Hour
+
+// pickVariant returns the visitor's variant of an A/B split link: the one
+// named by their cookie if it still exists, otherwise a weighted random
+// choice that is then stored in the cookie.
+func pickVariant(w http.ResponseWriter, r *http.Request, rl model.ResolvedLink) (model.ResolvedVariant, bool) {
+	if len(rl.Variants) == 0 {
+		return model.ResolvedVariant{}, false
+	}
+	name := "redo_variant_" + rl.ShortCode
+	if c, err := r.Cookie(name); err == nil {
+		for _, v := range rl.Variants {
+			if v.ID == c.Value {
+				return v, true
+			}
+		}
+	}
+
+	total := 0
+	for _, v := range rl.Variants {
+		total += v.Weight
+	}
+	chosen := weightedVariant(rl.Variants, rand.IntN(total))
+	http.SetCookie(w, &http.Cookie{
+		Name:     name,
+		Value:    chosen.ID,
+		Path:     "/go/" + rl.ShortCode,
+		MaxAge:   int(variantCookieTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https",
+		SameSite: http.SameSiteLaxMode,
+	})
+	return chosen, true
+}
+
+// weightedVariant returns the variant whose share of the total weight holds
+// n, for n in [0, total weight).
+func weightedVariant(variants []model.ResolvedVariant, n int) model.ResolvedVariant {
+	for _, v := range variants {
+		if n < v.Weight {
+			return v
+		}
+		n -= v.Weight
+	}
+	return variants[len(variants)-1]
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"redo.ai/internal/model"
+	"redo.ai/internal/service/link"
+)
+
+func testVariants() []model.ResolvedVariant {
+	return []model.ResolvedVariant{
+		{ID: "a", Destination: "https://a.example", Weight: 1},
+		{ID: "b", Destination: "https://b.example", Weight: 3},
+		{ID: "c", Destination: "https://c.example", Weight: 6},
+	}
+}
+
+func TestWeightedVariant(t *testing.T) {
+	variants := testVariants()
+	counts := map[string]int{}
+	for n := 0; n < 10; n++ {
+		counts[weightedVariant(variants, n).ID]++
+	}
+	if counts["a"] != 1 || counts["b"] != 3 || counts["c"] != 6 {
+		t.Errorf("counts = %v, want each variant's weight", counts)
+	}
+	if got := weightedVariant(variants, 0).ID; got != "a" {
+		t.Errorf("n=0 picked %s, want a", got)
+	}
+	if got := weightedVariant(variants, 3).ID; got != "b" {
+		t.Errorf("n=3 picked %s, want b", got)
+	}
+	if got := weightedVariant(variants, 4).ID; got != "c" {
+		t.Errorf("n=4 picked %s, want c", got)
+	}
+}
+
+func TestPickVariantCookie(t *testing.T) {
+	rl := model.ResolvedLink{ShortCode: "abc1234", Variants: testVariants()}
+
+	// A first visit picks a variant and remembers it.
+	w := httptest.NewRecorder()
+	v, ok := pickVariant(w, httptest.NewRequest(http.MethodGet, "/go/abc1234", nil), rl)
+	if !ok {
+		t.Fatal("no variant picked")
+	}
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("got %d cookies, want 1", len(cookies))
+	}
+	c := cookies[0]
+	if c.Name != "redo_variant_abc1234" || c.Value != v.ID || c.Path != "/go/abc1234" || !c.HttpOnly {
+		t.Errorf("unexpected cookie %+v for variant %s", c, v.ID)
+	}
+
+	// A returning visitor keeps their variant without a new cookie.
+	for _, want := range []string{"a", "b", "c"} {
+		r := httptest.NewRequest(http.MethodGet, "/go/abc1234", nil)
+		r.AddCookie(&http.Cookie{Name: "redo_variant_abc1234", Value: want})
+		w := httptest.NewRecorder()
+		v, _ := pickVariant(w, r, rl)
+		if v.ID != want {
+			t.Errorf("sticky cookie %s picked %s", want, v.ID)
+		}
+		if len(w.Result().Cookies()) != 0 {
+			t.Errorf("sticky cookie %s was rewritten", want)
+		}
+	}
+
+	// A cookie naming a removed variant is replaced.
+	r := httptest.NewRequest(http.MethodGet, "/go/abc1234", nil)
+	r.AddCookie(&http.Cookie{Name: "redo_variant_abc1234", Value: "gone"})
+	w = httptest.NewRecorder()
+	v, _ = pickVariant(w, r, rl)
+	if v.ID == "gone" || len(w.Result().Cookies()) != 1 {
+		t.Errorf("stale cookie not replaced: picked %s", v.ID)
+	}
+
+	if _, ok := pickVariant(httptest.NewRecorder(), r, model.ResolvedLink{ShortCode: "x"}); ok {
+		t.Error("picked a variant for a link without variants")
+	}
+}
+
+func TestValidateVariantsMessages(t *testing.T) {
+	many := make([]model.LinkVariantInput, link.MaxVariants+1)
+	if msg := validateVariants(many); !strings.Contains(msg, strconv.Itoa(link.MaxVariants)) {
+		t.Errorf("too many variants: %q", msg)
+	}
+	heavy := []model.LinkVariantInput{{Destination: "https://a.example", Weight: link.MaxVariantWeight + 1}}
+	if msg := validateVariants(heavy); !strings.Contains(msg, strconv.Itoa(link.MaxVariantWeight)) {
+		t.Errorf("too heavy: %q", msg)
+	}
+}
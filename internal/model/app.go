@@ -0,0 +1,47 @@
+package model
+
+import "time"
+
+// App association platforms.
+const (
+	AppPlatformIOS     = "ios"
+	AppPlatformAndroid = "android"
+)
+
+// AppAssociation lets a mobile app open short links on a custom domain.
+// AppID is "TEAMID.bundle.id" on iOS and the package name on Android. An
+// empty Domain applies to all of the user's domains.
+type AppAssociation struct {
+	ID           string    `json:"id"`
+	Platform     string    `json:"platform"`
+	AppID        string    `json:"app_id"`
+	Domain       string    `json:"domain,omitempty"`
+	Paths        []string  `json:"paths,omitempty"`
+	Fingerprints []string  `json:"sha256_cert_fingerprints,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type CreateAppRequest struct {
+	Platform     string   `json:"platform"`
+	AppID        string   `json:"app_id"`
+	Domain       string   `json:"domain,omitempty"`
+	Paths        []string `json:"paths,omitempty"`
+	Fingerprints []string `json:"sha256_cert_fingerprints,omitempty"`
+}
+
+// CustomDomain is a domain a user serves short links from. It gets app
+// association files only once verified: the TXT record named
+// VerificationRecord must hold VerificationValue.
+type CustomDomain struct {
+	ID                 string     `json:"id"`
+	Domain             string     `json:"domain"`
+	Verified           bool       `json:"verified"`
+	VerificationRecord string     `json:"verification_record"`
+	VerificationValue  string     `json:"verification_value"`
+	VerifiedAt         *time.Time `json:"verified_at,omitempty"`
+	CreatedAt          time.Time  `json:"created_at"`
+}
+
+type CreateDomainRequest struct {
+	Domain string `json:"domain"`
+}
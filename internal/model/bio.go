@@ -0,0 +1,35 @@
+package model
+
+import "time"
+
+// BioPage is a user's link-in-bio page, served at /@Handle.
+type BioPage struct {
+	ID          string    `json:"id"`
+	Handle      string    `json:"handle"`
+	Title       string    `json:"title,omitempty"`
+	Description string    `json:"description,omitempty"`
+	AvatarURL   string    `json:"avatar_url,omitempty"`
+	Theme       string    `json:"theme"`
+	Items       []BioItem `json:"items"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// BioItem is one link on a bio page, in display order. Title falls back to
+// the link's own title when empty; Icon is an image URL.
+type BioItem struct {
+	LinkID    string `json:"link_id"`
+	ShortCode string `json:"short_code,omitempty"`
+	Title     string `json:"title,omitempty"`
+	Icon      string `json:"icon,omitempty"`
+}
+
+// SaveBioPageRequest replaces the page and its items.
+type SaveBioPageRequest struct {
+	Handle      string    `json:"handle"`
+	Title       string    `json:"title,omitempty"`
+	Description string    `json:"description,omitempty"`
+	AvatarURL   string    `json:"avatar_url,omitempty"`
+	Theme       string    `json:"theme,omitempty"`
+	Items       []BioItem `json:"items"`
+}
@@ -0,0 +1,69 @@
+package model
+
+import "time"
+
+// BulkCreateItem is one row of a bulk create. Row is the 1-based position in
+// the uploaded array or CSV file and is echoed back in BulkRowResult.
+type BulkCreateItem struct {
+	Row int `json:"-"`
+	CreateLinkRequest
+}
+
+// BulkUpdateItem is one row of a bulk update; nil fields are left unchanged.
+type BulkUpdateItem struct {
+	Row int    `json:"-"`
+	ID  string `json:"id"`
+	UpdateLinkRequest
+}
+
+// BulkDeleteItem is one row of a bulk delete.
+type BulkDeleteItem struct {
+	Row int    `json:"-"`
+	ID  string `json:"id"`
+}
+
+// Row statuses reported in BulkRowResult.
+const (
+	BulkRowOK         = "ok"
+	BulkRowFailed     = "failed"
+	BulkRowRolledBack = "rolled_back"
+)
+
+type BulkRowResult struct {
+	Row    int    `json:"row"`
+	LinkID string `json:"id,omitempty"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// BulkResult summarises a bulk operation. In atomic mode a single failed row
+// rolls back the whole batch and Committed is false.
+type BulkResult struct {
+	Total     int             `json:"total"`
+	Succeeded int             `json:"succeeded"`
+	Failed    int             `json:"failed"`
+	Committed bool            `json:"committed"`
+	Rows      []BulkRowResult `json:"rows"`
+}
+
+// Bulk job statuses.
+const (
+	BulkJobQueued    = "queued"
+	BulkJobRunning   = "running"
+	BulkJobCompleted = "completed"
+	BulkJobFailed    = "failed"
+)
+
+// BulkJob is a bulk operation running in the background.
+type BulkJob struct {
+	ID         string      `json:"id"`
+	Operation  string      `json:"operation"`
+	Status     string      `json:"status"`
+	Atomic     bool        `json:"atomic"`
+	Total      int         `json:"total"`
+	Result     *BulkResult `json:"result,omitempty"`
+	Error      string      `json:"error,omitempty"`
+	CreatedAt  time.Time   `json:"created_at"`
+	StartedAt  *time.Time  `json:"started_at,omitempty"`
+	FinishedAt *time.Time  `json:"finished_at,omitempty"`
+}
@@ -22,5 +22,70 @@ type Click struct {
 	Country     string    `json:"country"`
 	Conversion  bool      `json:"conversion"`
 	IsHighValue bool      `json:"is_high_value"`
+	Source      string    `json:"source,omitempty"`
+	VariantID   string    `json:"variant_id,omitempty"`
 	CreatedAt   time.Time `json:"created_at"`
+	// RevenueCents is the value reported with the conversion, if any.
+	RevenueCents *int64     `json:"revenue_cents,omitempty"`
+	ConvertedAt  *time.Time `json:"converted_at,omitempty"`
+}
+
+// Click sources carried as ?src= on the short URL.
+const (
+	// ClickSourceQR marks clicks from scans of a link's QR code.
+	ClickSourceQR = "qr"
+	// ClickSourceBio marks clicks from a link-in-bio page.
+	ClickSourceBio = "bio"
+)
+
+// ClickEvent is a visit to a short URL as seen by the redirect handler.
+type ClickEvent struct {
+	ShortCode string
+	IP        string
+	Referrer  string
+	UserAgent string
+	// Source is empty for direct clicks, otherwise a ClickSource* value.
+	Source string
+	// VariantID is the A/B variant the visitor was sent to, if any.
+	VariantID string
+	// ID is the click's ID when the redirect already handed it out;
+	// empty lets the database pick one.
+	ID string
+}
+
+// ClickFilter narrows a click stream. Empty fields and zero times do not
+// filter; To is exclusive.
+type ClickFilter struct {
+	UserID string
+	LinkID string
+	From   time.Time
+	To     time.Time
+}
+
+// Conversion is the outcome of reporting a conversion for a click.
+type Conversion struct {
+	ClickID string `json:"click_id"`
+	LinkID  string `json:"link_id"`
+	// AlreadyConverted is true when the click had been converted before;
+	// the first report wins.
+	AlreadyConverted bool `json:"already_converted"`
+}
+
+// LinkConversions is the conversion funnel of one link.
+type LinkConversions struct {
+	LinkID         string  `json:"link_id"`
+	Label          string  `json:"label"`
+	Clicks         int     `json:"clicks"`
+	Conversions    int     `json:"conversions"`
+	ConversionRate float64 `json:"conversion_rate"`
+	RevenueCents   int64   `json:"revenue_cents"`
+}
+
+// ConversionsByDay is the conversion funnel of one day.
+type ConversionsByDay struct {
+	DateLabel      string  `json:"date"`
+	Clicks         int     `json:"clicks"`
+	Conversions    int     `json:"conversions"`
+	ConversionRate float64 `json:"conversion_rate"`
+	RevenueCents   int64   `json:"revenue_cents"`
 }
@@ -0,0 +1,38 @@
+package model
+
+// ImportItem is one link parsed from another shortener's export.
+// OriginalShortCode is kept when it is free; otherwise a new code is issued.
+type ImportItem struct {
+	Row int
+	CreateLinkRequest
+	OriginalShortCode string
+}
+
+// ImportRowResult reports what happened, or would happen on a dry run, to
+// one imported row.
+type ImportRowResult struct {
+	Row               int    `json:"row"`
+	Status            string `json:"status"`
+	LinkID            string `json:"id,omitempty"`
+	Destination       string `json:"destination"`
+	Slug              string `json:"slug,omitempty"`
+	OriginalShortCode string `json:"original_short_code,omitempty"`
+	ShortCode         string `json:"short_code,omitempty"`
+	// ShortCodePreserved is false when the original code was taken or
+	// invalid and a new one was issued.
+	ShortCodePreserved bool   `json:"short_code_preserved"`
+	Error              string `json:"error,omitempty"`
+}
+
+// ImportReport summarises an import. On a dry run nothing is committed but
+// every row is attempted, so the report matches what a real run would do.
+type ImportReport struct {
+	Format              string            `json:"format"`
+	DryRun              bool              `json:"dry_run"`
+	Total               int               `json:"total"`
+	Imported            int               `json:"imported"`
+	Failed              int               `json:"failed"`
+	ShortCodesPreserved int               `json:"short_codes_preserved"`
+	ShortCodesReissued  int               `json:"short_codes_reissued"`
+	Rows                []ImportRowResult `json:"rows"`
+}
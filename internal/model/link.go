@@ -1,16 +1,172 @@
 package model
 
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+)
+
 type CreateLinkRequest struct {
-	Slug        string `json:"slug"`
-	Destination string `json:"destination"`
+	Slug        string   `json:"slug"`
+	Destination string   `json:"destination"`
+	Title       string   `json:"title,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	CampaignID  string   `json:"campaign_id,omitempty"`
+	// Password, when set, makes visitors enter it before being redirected.
+	Password string `json:"password,omitempty"`
+	// AppendClickID adds a signed click ID to the destination for
+	// conversion tracking.
+	AppendClickID bool `json:"append_click_id,omitempty"`
+	// ActivatesAt, when in the future, serves a "coming soon" page until
+	// then instead of redirecting.
+	ActivatesAt *time.Time `json:"activates_at,omitempty"`
+	// Preview is shown when the link is shared on social sites. Fields left
+	// empty are filled from the destination's Open Graph tags.
+	Preview *LinkPreview `json:"preview,omitempty"`
+	// PreviewFetched records that Preview was filled from the destination
+	// rather than written by the owner.
+	PreviewFetched bool `json:"-"`
+	// ShortCode requests a specific short code. It is not accepted from API
+	// clients; importers use it to keep codes from other shorteners.
+	ShortCode string `json:"-"`
+}
+
+// UpdateLinkRequest is a partial update; nil fields are left unchanged.
+type UpdateLinkRequest struct {
+	Slug        *string   `json:"slug"`
+	Destination *string   `json:"destination"`
+	Title       *string   `json:"title"`
+	IsActive    *bool     `json:"is_active"`
+	Tags        *[]string `json:"tags"`
+	// CampaignID moves the link into a campaign; an empty string removes it.
+	CampaignID *string `json:"campaign_id"`
+	// Password sets a new password; an empty string removes protection.
+	Password      *string `json:"password"`
+	AppendClickID *bool   `json:"append_click_id"`
+	// ActivatesAt reschedules the launch; a past time, null or an empty
+	// string makes the link live.
+	ActivatesAt OptionalTime `json:"activates_at,omitzero"`
+	// Preview replaces the share preview; an empty one removes it.
+	Preview *LinkPreview `json:"preview"`
+}
+
+// LinkQuery selects one page of a user's links.
+type LinkQuery struct {
+	Limit       int
+	Cursor      string
+	Active      *bool
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+	Domain      string
+	Tag         string
+	CampaignID  string
+	Search      string
+	Sort        string
+	Order       string
+}
+
+type LinkPage struct {
+	Links      []Link `json:"links"`
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 type Link struct {
-	LinkID      string `json:"id"`
-	Slug        string `json:"slug"`
-	ShortCode   string `json:"short_code"`
-	ClickCount  int    `json:"clicks"`
-	Is_active   bool   `json:"is_active"`
-	Destination string `json:"destination"`
-	CreatedAt   string `json:"created_at"`
+	LinkID     string `json:"id"`
+	Slug       string `json:"slug"`
+	ShortCode  string `json:"short_code"`
+	ClickCount int    `json:"clicks"`
+	Is_active  bool   `json:"is_active"`
+	// DisabledReason is set when moderation turned the link off.
+	DisabledReason string   `json:"disabled_reason,omitempty"`
+	Destination    string   `json:"destination"`
+	Title          string   `json:"title,omitempty"`
+	Tags           []string `json:"tags"`
+	CampaignID     string   `json:"campaign_id,omitempty"`
+	// PasswordProtected is true when visitors must enter a password.
+	PasswordProtected bool `json:"password_protected"`
+	// AppendClickID is true when redirects carry a signed click ID.
+	AppendClickID bool   `json:"append_click_id"`
+	CreatedAt     string `json:"created_at"`
+	// ActivatesAt is when the link goes live; before then visitors see a
+	// "coming soon" page.
+	ActivatesAt *time.Time   `json:"activates_at,omitempty"`
+	Preview     *LinkPreview `json:"preview,omitempty"`
+}
+
+// LinkPreview is the Open Graph card shown to social preview crawlers.
+type LinkPreview struct {
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	Image       string `json:"image,omitempty"`
+}
+
+// IsZero reports whether the preview has no content.
+func (p LinkPreview) IsZero() bool {
+	return p == LinkPreview{}
+}
+
+// ResolvedLink is what the redirect handler needs to serve a short code.
+type ResolvedLink struct {
+	ID          string
+	ShortCode   string
+	Destination string
+	// PasswordProtected links need a password or a valid access cookie.
+	// PasswordUpdatedAt is signed into the cookie so a password change
+	// revokes it.
+	PasswordProtected bool
+	PasswordUpdatedAt time.Time
+	// Variants, when present, replace Destination for A/B split links.
+	Variants []ResolvedVariant
+	// AppendClickID asks for the click ID on the destination URL.
+	AppendClickID bool
+	// Rules route matching visits elsewhere; the first match wins over
+	// Destination and Variants.
+	Rules []RoutingRule
+	// ActivatesAt is set while the link is scheduled but not yet live.
+	ActivatesAt *time.Time
+	// Preview is served to social preview crawlers instead of the redirect.
+	Preview LinkPreview
+}
+
+// LinkSearchResult is a link matched by full-text search. Highlights holds
+// HTML-escaped field values with matches wrapped in <mark>.
+type LinkSearchResult struct {
+	Link
+	Rank       float64           `json:"rank"`
+	Highlights map[string]string `json:"highlights"`
+}
+
+// SlugAvailability answers whether a vanity slug can be claimed. Reason is
+// set when it cannot; Suggestions then lists free alternatives.
+type SlugAvailability struct {
+	Slug        string   `json:"slug"`
+	Available   bool     `json:"available"`
+	Reason      string   `json:"reason,omitempty"`
+	MinLength   int      `json:"min_length"`
+	Suggestions []string `json:"suggestions"`
+}
+
+// OptionalTime is a timestamp in a partial update. Unlike *time.Time it
+// tells a field left out (Set false) from one cleared with null or an empty
+// string (Set true, Time nil).
+type OptionalTime struct {
+	Set  bool
+	Time *time.Time
+}
+
+func (o *OptionalTime) UnmarshalJSON(b []byte) error {
+	o.Set, o.Time = true, nil
+	if bytes.Equal(b, []byte("null")) || bytes.Equal(b, []byte(`""`)) {
+		return nil
+	}
+	var t time.Time
+	if err := json.Unmarshal(b, &t); err != nil {
+		return err
+	}
+	o.Time = &t
+	return nil
+}
+
+func (o OptionalTime) MarshalJSON() ([]byte, error) {
+	return json.Marshal(o.Time)
 }
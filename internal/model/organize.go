@@ -0,0 +1,37 @@
+package model
+
+import "time"
+
+type Tag struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	LinkCount int       `json:"link_count"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type TagRequest struct {
+	Name string `json:"name"`
+}
+
+type Campaign struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	LinkCount   int       `json:"link_count"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type CampaignRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type CampaignAnalytics struct {
+	CampaignID   string          `json:"campaign_id"`
+	TotalClicks  int             `json:"total_clicks"`
+	Conversions  int             `json:"conversions"`
+	ClicksPerDay []ClicksByDay   `json:"clicks_per_day"`
+	ByLink       []GroupedMetric `json:"by_link"`
+	ByCountry    []GroupedMetric `json:"by_country"`
+	ByDevice     []GroupedMetric `json:"by_device"`
+}
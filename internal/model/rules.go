@@ -0,0 +1,40 @@
+package model
+
+import "time"
+
+// RoutingRule sends visits matching all of its set conditions to Destination.
+// Within one condition any listed value matches; empty conditions are
+// ignored.
+type RoutingRule struct {
+	Name        string `json:"name,omitempty"`
+	Destination string `json:"destination"`
+
+	// Countries are ISO 3166-1 alpha-2 codes.
+	Countries []string `json:"countries,omitempty"`
+	// Languages are matched against the visitor's preferred language; "en"
+	// also matches "en-GB", while "en-GB" only matches itself.
+	Languages []string `json:"languages,omitempty"`
+	// Devices are ios, android or web.
+	Devices []string `json:"devices,omitempty"`
+	// ReferrerDomains match the referrer host and its subdomains.
+	ReferrerDomains []string `json:"referrer_domains,omitempty"`
+	// Query requires each parameter to be present; a non-empty value must
+	// also match exactly.
+	Query map[string]string `json:"query,omitempty"`
+
+	// Days are mon to sun. TimeFrom and TimeTo are HH:MM bounds of a daily
+	// window, which may wrap past midnight. Both use Timezone, UTC if empty.
+	Days     []string `json:"days,omitempty"`
+	TimeFrom string   `json:"time_from,omitempty"`
+	TimeTo   string   `json:"time_to,omitempty"`
+	Timezone string   `json:"timezone,omitempty"`
+	// StartsAt and EndsAt limit the rule to an absolute period.
+	StartsAt *time.Time `json:"starts_at,omitempty"`
+	EndsAt   *time.Time `json:"ends_at,omitempty"`
+}
+
+// SetRulesRequest replaces the routing rules of a link; an empty list
+// removes them.
+type SetRulesRequest struct {
+	Rules []RoutingRule `json:"rules"`
+}
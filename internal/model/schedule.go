@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// Link schedule statuses.
+const (
+	SchedulePending   = "pending"
+	ScheduleApplied   = "applied"
+	ScheduleCancelled = "cancelled"
+)
+
+// LinkSchedule is a destination change the scheduler applies at RunAt.
+type LinkSchedule struct {
+	ID          string     `json:"id"`
+	LinkID      string     `json:"link_id"`
+	Destination string     `json:"destination"`
+	RunAt       time.Time  `json:"run_at"`
+	Status      string     `json:"status"`
+	AppliedAt   *time.Time `json:"applied_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+type CreateScheduleRequest struct {
+	Destination string    `json:"destination"`
+	RunAt       time.Time `json:"run_at"`
+}
@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+type UsageMetric struct {
+	Metric  string  `json:"metric"`
+	Used    int     `json:"used"`
+	Allowed int     `json:"allowed"`
+	Percent float64 `json:"percent"`
+	Hard    bool    `json:"hard"`
+}
+
+type Usage struct {
+	Plan        string        `json:"plan"`
+	PeriodStart time.Time     `json:"period_start"`
+	PeriodEnd   time.Time     `json:"period_end"`
+	Metrics     []UsageMetric `json:"metrics"`
+}
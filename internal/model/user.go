@@ -1,10 +1,24 @@
 package model
 
+import "time"
+
 type SignUpRequest struct {
 	Email string `json:"email"`
 }
 
 type User struct {
-	UserID string `json:"id"`
-	Role   string `json:"role"`
+	UserID               string     `json:"id"`
+	Role                 string     `json:"role"`
+	Email                string     `json:"email"`
+	Name                 string     `json:"name"`
+	BusinessName         string     `json:"business_name"`
+	CreatedAt            time.Time  `json:"created_at"`
+	DeletionScheduledFor *time.Time `json:"deletion_scheduled_for,omitempty"`
+}
+
+// ProfileUpdate holds the optional fields of a profile PATCH; nil fields are
+// left unchanged.
+type ProfileUpdate struct {
+	Name         *string `json:"name"`
+	BusinessName *string `json:"business_name"`
 }
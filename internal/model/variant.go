@@ -0,0 +1,47 @@
+package model
+
+import "time"
+
+// LinkVariant is one destination of an A/B split link.
+type LinkVariant struct {
+	ID          string    `json:"id"`
+	LinkID      string    `json:"link_id"`
+	Label       string    `json:"label"`
+	Destination string    `json:"destination"`
+	Weight      int       `json:"weight"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// LinkVariantInput creates a variant, or updates the existing one when ID
+// is set.
+type LinkVariantInput struct {
+	ID          string `json:"id,omitempty"`
+	Label       string `json:"label"`
+	Destination string `json:"destination"`
+	Weight      int    `json:"weight"`
+}
+
+// SetVariantsRequest replaces all variants of a link; an empty list turns
+// the split off.
+type SetVariantsRequest struct {
+	Variants []LinkVariantInput `json:"variants"`
+}
+
+// VariantStats compares the variants of a link over the analytics window.
+type VariantStats struct {
+	VariantID      string  `json:"variant_id"`
+	Label          string  `json:"label"`
+	Destination    string  `json:"destination"`
+	Weight         int     `json:"weight"`
+	Clicks         int     `json:"clicks"`
+	Conversions    int     `json:"conversions"`
+	ConversionRate float64 `json:"conversion_rate"`
+	RevenueCents   int64   `json:"revenue_cents"`
+}
+
+// ResolvedVariant is the part of a variant the redirect handler needs.
+type ResolvedVariant struct {
+	ID          string `json:"id"`
+	Destination string `json:"destination"`
+	Weight      int    `json:"weight"`
+}
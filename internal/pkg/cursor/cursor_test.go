@@ -0,0 +1,44 @@
+package cursor
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+type page struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    string `json:"id"`
+}
+
+func TestRoundTrip(t *testing.T) {
+	in := page{Sort: "slug", Value: "a/b?c=d&e", ID: "1b4e28ba-2fa1-11d2-883f-0016d3cca427"}
+	token, err := Encode(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.ContainsAny(token, "+/=") {
+		t.Errorf("token %q is not URL-safe", token)
+	}
+	var out page
+	if err := Decode(token, &out); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if out != in {
+		t.Errorf("got %+v, want %+v", out, in)
+	}
+}
+
+func TestDecodeRejectsGarbage(t *testing.T) {
+	for _, token := range []string{
+		"!!!",
+		base64.RawURLEncoding.EncodeToString([]byte("not json")),
+		base64.StdEncoding.EncodeToString([]byte(`{"s":"sl"}`)), // padded
+	} {
+		var out page
+		if err := Decode(token, &out); err != ErrInvalidCursor {
+			t.Errorf("Decode(%q) = %v, want ErrInvalidCursor", token, err)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package entitlements
+
+import (
+	"fmt"
+	"time"
+)
+
+// Plan mirrors the user_role enum in the database.
+type Plan string
+
+const (
+	PlanFree       Plan = "free"
+	PlanPro        Plan = "pro"
+	PlanEnterprise Plan = "enterprise"
+	PlanAdmin      Plan = "admin"
+)
+
+// Feature is a capability that is either on or off for a plan.
+type Feature string
+
+const (
+	FeatureAnalytics     Feature = "analytics"
+	FeatureCustomDomains Feature = "custom_domains"
+	FeatureAPIKeys       Feature = "api_keys"
+	FeatureBulkImport    Feature = "bulk_import"
+)
+
+// Limit is a countable quota attached to a plan.
+type Limit string
+
+const (
+	LimitLinks         Limit = "max_links"
+	LimitCustomDomains Limit = "max_custom_domains"
+	LimitAPIKeys       Limit = "max_api_keys"
+
+	LimitMonthlyLinks  Limit = "monthly_links"
+	LimitMonthlyClicks Limit = "monthly_tracked_clicks"
+)
+
+// Unlimited marks a quota with no upper bound.
+const Unlimited = -1
+
+// Entitlements describes everything a plan is allowed to do.
+type Entitlements struct {
+	Plan                   Plan `json:"plan"`
+	MaxLinks               int  `json:"max_links"`
+	MaxCustomDomains       int  `json:"max_custom_domains"`
+	MaxAPIKeys             int  `json:"max_api_keys"`
+	AnalyticsRetentionDays int  `json:"analytics_retention_days"`
+	MonthlyLinks           int  `json:"monthly_links"`
+	MonthlyClicks          int  `json:"monthly_tracked_clicks"`
+	// MinSlugLength is the shortest vanity slug the plan may claim.
+	MinSlugLength int `json:"min_slug_length"`
+	// HardQuota blocks usage past the monthly allowance; soft quotas only
+	// report the overage.
+	HardQuota bool             `json:"hard_quota"`
+	Features  map[Feature]bool `json:"features"`
+}
+
+var plans = map[Plan]Entitlements{
+	PlanFree: {
+		Plan:                   PlanFree,
+		MaxLinks:               25,
+		MaxCustomDomains:       0,
+		MaxAPIKeys:             0,
+		AnalyticsRetentionDays: 7,
+		MonthlyLinks:           25,
+		MonthlyClicks:          1000,
+		MinSlugLength:          6,
+		HardQuota:              true,
+		Features:               map[Feature]bool{},
+	},
+	PlanPro: {
+		Plan:                   PlanPro,
+		MaxLinks:               5000,
+		MaxCustomDomains:       3,
+		MaxAPIKeys:             5,
+		AnalyticsRetentionDays: 365,
+		MonthlyLinks:           1000,
+		MonthlyClicks:          100000,
+		MinSlugLength:          3,
+		HardQuota:              false,
+		Features: map[Feature]bool{
+			FeatureAnalytics:     true,
+			FeatureCustomDomains: true,
+			FeatureAPIKeys:       true,
+			FeatureBulkImport:    true,
+		},
+	},
+	PlanEnterprise: {
+		Plan:                   PlanEnterprise,
+		MaxLinks:               Unlimited,
+		MaxCustomDomains:       50,
+		MaxAPIKeys:             50,
+		AnalyticsRetentionDays: 3 * 365,
+		MonthlyLinks:           Unlimited,
+		MonthlyClicks:          5000000,
+		MinSlugLength:          2,
+		HardQuota:              false,
+		Features: map[Feature]bool{
+			FeatureAnalytics:     true,
+			FeatureCustomDomains: true,
+			FeatureAPIKeys:       true,
+			FeatureBulkImport:    true,
+		},
+	},
+	PlanAdmin: {
+		Plan:                   PlanAdmin,
+		MaxLinks:               Unlimited,
+		MaxCustomDomains:       Unlimited,
+		MaxAPIKeys:             Unlimited,
+		AnalyticsRetentionDays: Unlimited,
+		MonthlyLinks:           Unlimited,
+		MonthlyClicks:          Unlimited,
+		MinSlugLength:          1,
+		HardQuota:              false,
+		Features: map[Feature]bool{
+			FeatureAnalytics:     true,
+			FeatureCustomDomains: true,
+			FeatureAPIKeys:       true,
+			FeatureBulkImport:    true,
+		},
+	},
+}
+
+// For returns the entitlements of a user_role value. Unknown roles fall back
+// to the free plan so a bad row never grants more than it should.
+func For(role string) Entitlements {
+	if e, ok := plans[Plan(role)]; ok {
+		return e
+	}
+	return plans[PlanFree]
+}
+
+// IsValidPlan reports whether role is a value of the user_role enum.
+func IsValidPlan(role string) bool {
+	_, ok := plans[Plan(role)]
+	return ok
+}
+
+// Has reports whether the plan includes the feature.
+func (e Entitlements) Has(f Feature) bool {
+	return e.Features[f]
+}
+
+// Quota returns the allowance for a limit, or Unlimited.
+func (e Entitlements) Quota(l Limit) int {
+	switch l {
+	case LimitLinks:
+		return e.MaxLinks
+	case LimitCustomDomains:
+		return e.MaxCustomDomains
+	case LimitAPIKeys:
+		return e.MaxAPIKeys
+	case LimitMonthlyLinks:
+		return e.MonthlyLinks
+	case LimitMonthlyClicks:
+		return e.MonthlyClicks
+	default:
+		return 0
+	}
+}
+
+// RequireFeature returns a *FeatureError when the plan lacks the feature.
+func (e Entitlements) RequireFeature(f Feature) error {
+	if e.Has(f) {
+		return nil
+	}
+	return &FeatureError{Plan: e.Plan, Feature: f}
+}
+
+// CheckQuota returns a *QuotaError when adding n more items on top of used
+// would exceed the plan allowance.
+func (e Entitlements) CheckQuota(l Limit, used, n int) error {
+	allowed := e.Quota(l)
+	if allowed == Unlimited || used+n <= allowed {
+		return nil
+	}
+	return &QuotaError{Plan: e.Plan, Limit: l, Allowed: allowed, Used: used}
+}
+
+// AnalyticsSince returns the oldest timestamp analytics may report on, or the
+// zero time when retention is unlimited.
+func (e Entitlements) AnalyticsSince(now time.Time) time.Time {
+	if e.AnalyticsRetentionDays == Unlimited {
+		return time.Time{}
+	}
+	return now.AddDate(0, 0, -e.AnalyticsRetentionDays)
+}
+
+// QuotaError is returned when a countable limit has been reached.
+type QuotaError struct {
+	Plan    Plan
+	Limit   Limit
+	Allowed int
+	Used    int
+}
+
+func (e *QuotaError) Error() string {
+	return fmt.Sprintf("%s plan limit reached: %s (%d of %d used)", e.Plan, e.Limit, e.Used, e.Allowed)
+}
+
+// FeatureError is returned when a plan does not include a feature.
+type FeatureError struct {
+	Plan    Plan
+	Feature Feature
+}
+
+func (e *FeatureError) Error() string {
+	return fmt.Sprintf("%s plan does not include %s", e.Plan, e.Feature)
+}
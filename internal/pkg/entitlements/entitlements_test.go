@@ -0,0 +1,81 @@
+package entitlements
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestForUnknownRoleFallsBackToFree(t *testing.T) {
+	for _, role := range []string{"", "superuser", "FREE"} {
+		if got := For(role); got.Plan != PlanFree {
+			t.Errorf("For(%q) = %s, want free", role, got.Plan)
+		}
+		if IsValidPlan(role) {
+			t.Errorf("IsValidPlan(%q) = true", role)
+		}
+	}
+	if got := For("pro"); got.Plan != PlanPro {
+		t.Errorf("For(pro) = %s", got.Plan)
+	}
+}
+
+func TestCheckQuota(t *testing.T) {
+	tests := []struct {
+		name    string
+		plan    Plan
+		limit   Limit
+		used, n int
+		wantErr bool
+	}{
+		{name: "Under the limit", plan: PlanFree, limit: LimitLinks, used: 10, n: 1},
+		{name: "Exactly at the limit", plan: PlanFree, limit: LimitLinks, used: 24, n: 1},
+		{name: "Over the limit", plan: PlanFree, limit: LimitLinks, used: 25, n: 1, wantErr: true},
+		{name: "Batch crossing the limit", plan: PlanFree, limit: LimitLinks, used: 20, n: 6, wantErr: true},
+		{name: "Zero allowance", plan: PlanFree, limit: LimitCustomDomains, used: 0, n: 1, wantErr: true},
+		{name: "Unlimited", plan: PlanEnterprise, limit: LimitLinks, used: 1 << 30, n: 1000},
+		{name: "Unknown limit allows nothing", plan: PlanAdmin, limit: Limit("bogus"), used: 0, n: 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := plans[tt.plan].CheckQuota(tt.limit, tt.used, tt.n)
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			var qe *QuotaError
+			if !errors.As(err, &qe) {
+				t.Fatalf("expected *QuotaError, got %v", err)
+			}
+			if qe.Plan != tt.plan || qe.Limit != tt.limit || qe.Used != tt.used {
+				t.Errorf("unexpected error fields: %+v", qe)
+			}
+		})
+	}
+}
+
+func TestRequireFeature(t *testing.T) {
+	for _, f := range []Feature{FeatureAnalytics, FeatureCustomDomains, FeatureAPIKeys, FeatureBulkImport} {
+		var fe *FeatureError
+		if err := For("free").RequireFeature(f); !errors.As(err, &fe) || fe.Feature != f || fe.Plan != PlanFree {
+			t.Errorf("free plan: expected FeatureError for %s, got %v", f, err)
+		}
+		for _, p := range []Plan{PlanPro, PlanEnterprise, PlanAdmin} {
+			if err := plans[p].RequireFeature(f); err != nil {
+				t.Errorf("%s plan: unexpected error for %s: %v", p, f, err)
+			}
+		}
+	}
+}
+
+func TestAnalyticsSince(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	if got, want := For("free").AnalyticsSince(now), now.AddDate(0, 0, -7); !got.Equal(want) {
+		t.Errorf("free: got %v, want %v", got, want)
+	}
+	if got := For("admin").AnalyticsSince(now); !got.IsZero() {
+		t.Errorf("admin: expected zero time, got %v", got)
+	}
+}
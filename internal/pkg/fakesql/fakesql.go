@@ -0,0 +1,169 @@
+// Package fakesql is a scripted database/sql driver for exercising service
+// logic in tests without Postgres.
+package fakesql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// DB answers each statement with the first rule whose match is a substring
+// of it; statements without a rule fail the test.
+type DB struct {
+	t     *testing.T
+	mu    sync.Mutex
+	rules []rule
+	calls []Call
+}
+
+type rule struct {
+	match string
+	fn    func(args []driver.Value) Result
+}
+
+// Result answers a statement: Rows for queries, or Err. Execs report
+// len(Rows) rows affected.
+type Result struct {
+	Columns []string
+	Rows    [][]driver.Value
+	Err     error
+}
+
+// Call is a statement the DB received.
+type Call struct {
+	Query string
+	Args  []driver.Value
+}
+
+func New(t *testing.T) (*DB, *sql.DB) {
+	f := &DB{t: t}
+	db := sql.OpenDB(f)
+	t.Cleanup(func() { db.Close() })
+	return f, db
+}
+
+// On answers statements containing match.
+func (f *DB) On(match string, fn func(args []driver.Value) Result) {
+	f.rules = append(f.rules, rule{match: match, fn: fn})
+}
+
+// Log returns the statements run so far, including BEGIN, COMMIT and
+// ROLLBACK.
+func (f *DB) Log() []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Call(nil), f.calls...)
+}
+
+// Ran returns the calls whose statement contains match.
+func (f *DB) Ran(match string) []Call {
+	var out []Call
+	for _, c := range f.Log() {
+		if strings.Contains(c.Query, match) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func (f *DB) answer(query string, named []driver.NamedValue) Result {
+	args := make([]driver.Value, len(named))
+	for i, nv := range named {
+		args[i] = nv.Value
+	}
+	f.mu.Lock()
+	f.calls = append(f.calls, Call{Query: query, Args: args})
+	f.mu.Unlock()
+	for _, r := range f.rules {
+		if strings.Contains(query, r.match) {
+			return r.fn(args)
+		}
+	}
+	f.t.Errorf("unexpected statement:\n%s", query)
+	return Result{Err: errors.New("unexpected statement")}
+}
+
+func (f *DB) record(query string) {
+	f.mu.Lock()
+	f.calls = append(f.calls, Call{Query: query})
+	f.mu.Unlock()
+}
+
+func (f *DB) Connect(context.Context) (driver.Conn, error) { return conn{f}, nil }
+func (f *DB) Driver() driver.Driver                        { return nil }
+
+type conn struct{ db *DB }
+
+func (c conn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("DB: prepared statements are not supported")
+}
+func (c conn) Close() error { return nil }
+func (c conn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c conn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	c.db.record("BEGIN")
+	return tx{c.db}, nil
+}
+
+func (c conn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	res := c.db.answer(query, args)
+	if res.Err != nil {
+		return nil, res.Err
+	}
+	return driver.RowsAffected(len(res.Rows)), nil
+}
+
+func (c conn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	res := c.db.answer(query, args)
+	if res.Err != nil {
+		return nil, res.Err
+	}
+	return &rows{columns: res.Columns, rows: res.Rows}, nil
+}
+
+// CheckNamedValue lets driver.Valuer arguments such as sql.NullTime through
+// the default conversion.
+func (c conn) CheckNamedValue(nv *driver.NamedValue) error {
+	if v, ok := nv.Value.(driver.Valuer); ok {
+		val, err := v.Value()
+		nv.Value = val
+		return err
+	}
+	val, err := driver.DefaultParameterConverter.ConvertValue(nv.Value)
+	nv.Value = val
+	return err
+}
+
+type tx struct{ db *DB }
+
+func (t tx) Commit() error   { t.db.record("COMMIT"); return nil }
+func (t tx) Rollback() error { t.db.record("ROLLBACK"); return nil }
+
+type rows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *rows) Columns() []string { return r.columns }
+func (r *rows) Close() error      { return nil }
+func (r *rows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+// Row is shorthand for a single-row result.
+func Row(columns []string, values ...driver.Value) Result {
+	return Result{Columns: columns, Rows: [][]driver.Value{values}}
+}
@@ -0,0 +1,179 @@
+// Package importer parses link exports from other shorteners into
+// CreateLinkRequest rows.
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"redo.ai/internal/model"
+)
+
+// Supported export formats.
+const (
+	FormatBitly     = "bitly"
+	FormatRebrandly = "rebrandly"
+	FormatCSV       = "csv"
+)
+
+var ErrUnknownFormat = errors.New("unknown import format")
+
+// columns maps our fields to the header names each format uses, in order of
+// preference. Headers are matched case-insensitively.
+type columns struct {
+	destination []string
+	slug        []string
+	shortCode   []string
+	title       []string
+	tags        []string
+	campaignID  []string
+}
+
+var formats = map[string]columns{
+	// Bitly's link export: the bitlink carries the short code, a custom
+	// back-half becomes the slug.
+	FormatBitly: {
+		destination: []string{"long_url", "original url", "long url", "destination"},
+		slug:        []string{"custom_bitlinks", "custom bitlinks", "custom bitlink", "custom_bitlink"},
+		shortCode:   []string{"link", "bitlink", "short url", "short_url", "id"},
+		title:       []string{"title"},
+		tags:        []string{"tags"},
+	},
+	// Rebrandly's export: the slashtag is both the short code and the slug.
+	FormatRebrandly: {
+		destination: []string{"destination", "destination url", "long url"},
+		slug:        []string{"slashtag"},
+		shortCode:   []string{"slashtag", "shorturl", "short url"},
+		title:       []string{"title"},
+		tags:        []string{"tags"},
+	},
+	FormatCSV: {
+		destination: []string{"destination", "url", "long_url"},
+		slug:        []string{"slug"},
+		shortCode:   []string{"short_code"},
+		title:       []string{"title"},
+		tags:        []string{"tags"},
+		campaignID:  []string{"campaign_id"},
+	},
+}
+
+// IsValidFormat reports whether format names a supported export format.
+func IsValidFormat(format string) bool {
+	_, ok := formats[format]
+	return ok
+}
+
+// Parse reads a CSV export with a header row. Rows are numbered from 1,
+// excluding the header. At most maxRows rows are accepted.
+func Parse(format string, r io.Reader, maxRows int) ([]model.ImportItem, error) {
+	cols, ok := formats[format]
+	if !ok {
+		return nil, ErrUnknownFormat
+	}
+
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.LazyQuotes = true
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("invalid CSV header: %w", err)
+	}
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if _, dup := index[name]; !dup {
+			index[name] = i
+		}
+	}
+	if _, ok := lookup(index, cols.destination); !ok {
+		return nil, fmt.Errorf("missing destination column (expected one of %s)", strings.Join(cols.destination, ", "))
+	}
+	reader.FieldsPerRecord = len(header)
+
+	var items []model.ImportItem
+	for {
+		fields, err := reader.Read()
+		if err == io.EOF {
+			return items, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %w", err)
+		}
+		if len(items) == maxRows {
+			return nil, fmt.Errorf("more than %d rows", maxRows)
+		}
+		get := func(names []string) string {
+			if i, ok := lookup(index, names); ok {
+				return strings.TrimSpace(fields[i])
+			}
+			return ""
+		}
+		items = append(items, model.ImportItem{
+			Row: len(items) + 1,
+			CreateLinkRequest: model.CreateLinkRequest{
+				Destination: get(cols.destination),
+				Slug:        lastSegment(firstValue(get(cols.slug))),
+				Title:       get(cols.title),
+				Tags:        splitList(get(cols.tags)),
+				CampaignID:  get(cols.campaignID),
+			},
+			OriginalShortCode: lastSegment(get(cols.shortCode)),
+		})
+	}
+}
+
+func lookup(index map[string]int, names []string) (int, bool) {
+	for _, n := range names {
+		if i, ok := index[n]; ok {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// lastSegment reduces a short URL such as "https://bit.ly/3abcDEF" to its
+// code; bare codes are returned unchanged.
+func lastSegment(s string) string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return ""
+	}
+	if strings.Contains(s, "/") {
+		if !strings.Contains(s, "://") {
+			s = "https://" + s
+		}
+		if u, err := url.Parse(s); err == nil {
+			s = u.Path
+		}
+	}
+	s = strings.Trim(s, "/")
+	if i := strings.LastIndex(s, "/"); i >= 0 {
+		s = s[i+1:]
+	}
+	return s
+}
+
+// firstValue returns the first entry of a multi-valued cell.
+func firstValue(s string) string {
+	if list := splitList(s); len(list) > 0 {
+		return list[0]
+	}
+	return ""
+}
+
+// splitList splits cells like "a, b", "a|b" or "a;b".
+func splitList(s string) []string {
+	fields := strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == '|' || r == ';' })
+	out := make([]string, 0, len(fields))
+	for _, f := range fields {
+		if f = strings.TrimSpace(f); f != "" {
+			out = append(out, f)
+		}
+	}
+	return out
+}
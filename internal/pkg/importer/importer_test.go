@@ -0,0 +1,135 @@
+package importer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"redo.ai/internal/model"
+)
+
+func item(row int, dest, slug, title string, tags []string, code string) model.ImportItem {
+	return model.ImportItem{
+		Row: row,
+		CreateLinkRequest: model.CreateLinkRequest{
+			Destination: dest,
+			Slug:        slug,
+			Title:       title,
+			Tags:        tags,
+		},
+		OriginalShortCode: code,
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		csv    string
+		want   []model.ImportItem
+	}{
+		{
+			name:   "bitly api export",
+			format: FormatBitly,
+			csv: "id,link,custom_bitlinks,long_url,title,tags,created_at\n" +
+				"bit.ly/3abcDEF,https://bit.ly/3abcDEF,,https://example.com/a,Launch,\"news, q3\",2024-01-02T03:04:05+0000\n" +
+				"bit.ly/3xyzUVW,https://bit.ly/3xyzUVW,\"https://bit.ly/spring, https://bit.ly/spring24\",https://example.com/b,,,2024-01-03T03:04:05+0000\n",
+			want: []model.ImportItem{
+				item(1, "https://example.com/a", "", "Launch", []string{"news", "q3"}, "3abcDEF"),
+				item(2, "https://example.com/b", "spring", "", []string{}, "3xyzUVW"),
+			},
+		},
+		{
+			name:   "bitly dashboard export",
+			format: FormatBitly,
+			csv: "\ufeffCreated,Title,Bitlink,Custom Bitlinks,Long URL,Tags\n" +
+				"2024-01-02,Launch,bit.ly/3abcDEF,bit.ly/launch,https://example.com/a,news|q3\n",
+			want: []model.ImportItem{
+				item(1, "https://example.com/a", "launch", "Launch", []string{"news", "q3"}, "3abcDEF"),
+			},
+		},
+		{
+			name:   "rebrandly export",
+			format: FormatRebrandly,
+			csv: "Short URL,Destination URL,Slashtag,Title,Tags,Clicks\n" +
+				"rebrand.ly/promo,https://example.com/promo,promo,Promo,sale;spring,12\n",
+			want: []model.ImportItem{
+				item(1, "https://example.com/promo", "promo", "Promo", []string{"sale", "spring"}, "promo"),
+			},
+		},
+		{
+			name:   "generic csv",
+			format: FormatCSV,
+			csv: "url,slug,short_code,title,tags,campaign_id\n" +
+				"https://example.com/x,my-slug,abc123,X,a|b,3f1c2e4a-8b7d-4c6e-9a1b-2d3e4f5a6b7c\n",
+			want: []model.ImportItem{{
+				Row: 1,
+				CreateLinkRequest: model.CreateLinkRequest{
+					Destination: "https://example.com/x",
+					Slug:        "my-slug",
+					Title:       "X",
+					Tags:        []string{"a", "b"},
+					CampaignID:  "3f1c2e4a-8b7d-4c6e-9a1b-2d3e4f5a6b7c",
+				},
+				OriginalShortCode: "abc123",
+			}},
+		},
+		{
+			name:   "header only",
+			format: FormatCSV,
+			csv:    "destination\n",
+			want:   nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.format, strings.NewReader(tt.csv), 10)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got  %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		csv    string
+	}{
+		{name: "unknown format", format: "tinyurl", csv: "url\nhttps://example.com\n"},
+		{name: "missing destination", format: FormatRebrandly, csv: "slashtag,title\npromo,Promo\n"},
+		{name: "ragged row", format: FormatCSV, csv: "destination,slug\nhttps://example.com\n"},
+		{name: "too many rows", format: FormatCSV, csv: "destination\nhttps://a.example\nhttps://b.example\nhttps://c.example\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(tt.format, strings.NewReader(tt.csv), 2); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestLastSegment(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"", ""},
+		{"  3abcDEF ", "3abcDEF"},
+		{"https://bit.ly/3abcDEF", "3abcDEF"},
+		{"bit.ly/3abcDEF", "3abcDEF"},
+		{"http://rebrand.ly/promo/", "promo"},
+		{"https://bit.ly/3abcDEF?utm_source=x#frag", "3abcDEF"},
+		{"go.example.com/team/launch", "launch"},
+		{"/launch", "launch"},
+	}
+	for _, tt := range tests {
+		if got := lastSegment(tt.in); got != tt.want {
+			t.Errorf("lastSegment(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+// Package ogmeta reads Open Graph metadata from web pages to prefill link
+// previews.
+package ogmeta
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Limits on the metadata kept from a page.
+const (
+	MaxTitleLength       = 200
+	MaxDescriptionLength = 500
+	MaxImageURLLength    = 2048
+)
+
+// DefaultMaxBytes is how much of a page is read looking for metadata.
+const DefaultMaxBytes = 512 << 10
+
+// Metadata is the preview of a page.
+type Metadata struct {
+	Title       string
+	Description string
+	Image       string
+}
+
+// Fetcher retrieves the metadata of a URL.
+type Fetcher interface {
+	Fetch(ctx context.Context, rawURL string) (Metadata, error)
+}
+
+// ErrNotHTML is returned for pages that are not HTML.
+var ErrNotHTML = errors.New("ogmeta: not an HTML page")
+
+// HTTPFetcher fetches pages over HTTP. Client should refuse internal
+// addresses, as urlpolicy.PublicClient does.
+type HTTPFetcher struct {
+	Client    *http.Client
+	UserAgent string
+	// MaxBytes bounds how much of a page is read; DefaultMaxBytes if zero.
+	MaxBytes int64
+}
+
+func (f *HTTPFetcher) Fetch(ctx context.Context, rawURL string) (Metadata, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return Metadata{}, err
+	}
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+	if f.UserAgent != "" {
+		req.Header.Set("User-Agent", f.UserAgent)
+	}
+	client := f.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return Metadata{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Metadata{}, fmt.Errorf("ogmeta: %s returned %s", rawURL, resp.Status)
+	}
+	if mt, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mt != "text/html" && mt != "application/xhtml+xml" {
+		return Metadata{}, ErrNotHTML
+	}
+	limit := f.MaxBytes
+	if limit <= 0 {
+		limit = DefaultMaxBytes
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, limit))
+	if err != nil {
+		return Metadata{}, err
+	}
+	return Parse(string(body), resp.Request.URL), nil
+}
+
+var (
+	headEndRE = regexp.MustCompile(`(?i)</head\s*>|<body[\s>]`)
+	metaRE    = regexp.MustCompile(`(?is)<meta\s[^>]*>`)
+	attrRE    = regexp.MustCompile(`(?is)([a-z:_-]+)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+	titleRE   = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title\s*>`)
+	spaceRE   = regexp.MustCompile(`\s+`)
+)
+
+// Parse extracts the preview from an HTML document. Open Graph tags win
+// over Twitter card tags, which win over the <title> and description meta
+// tags. Relative image URLs are resolved against base.
+func Parse(doc string, base *url.URL) Metadata {
+	if loc := headEndRE.FindStringIndex(doc); loc != nil {
+		doc = doc[:loc[0]]
+	}
+	props := map[string]string{}
+	for _, tag := range metaRE.FindAllString(doc, -1) {
+		attrs := map[string]string{}
+		for _, m := range attrRE.FindAllStringSubmatch(tag, -1) {
+			attrs[strings.ToLower(m[1])] = m[2] + m[3] + m[4]
+		}
+		key := attrs["property"]
+		if key == "" {
+			key = attrs["name"]
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		if _, seen := props[key]; key != "" && !seen {
+			props[key] = clean(attrs["content"])
+		}
+	}
+	first := func(keys ...string) string {
+		for _, k := range keys {
+			if v := props[k]; v != "" {
+				return v
+			}
+		}
+		return ""
+	}
+
+	var md Metadata
+	md.Title = first("og:title", "twitter:title")
+	if md.Title == "" {
+		if m := titleRE.FindStringSubmatch(doc); m != nil {
+			md.Title = clean(m[1])
+		}
+	}
+	md.Description = first("og:description", "twitter:description", "description")
+	md.Image = resolveImage(first("og:image:secure_url", "og:image", "og:image:url", "twitter:image", "twitter:image:src"), base)
+	md.Title = truncate(md.Title, MaxTitleLength)
+	md.Description = truncate(md.Description, MaxDescriptionLength)
+	return md
+}
+
+func clean(s string) string {
+	return strings.TrimSpace(spaceRE.ReplaceAllString(html.UnescapeString(s), " "))
+}
+
+// truncate cuts s to at most n bytes on a rune boundary.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	s = s[:n]
+	for !utf8.ValidString(s) {
+		s = s[:len(s)-1]
+	}
+	return strings.TrimSpace(s)
+}
+
+// resolveImage returns an absolute http(s) image URL, or "".
+func resolveImage(ref string, base *url.URL) string {
+	if ref == "" {
+		return ""
+	}
+	u, err := url.Parse(ref)
+	if err != nil {
+		return ""
+	}
+	if base != nil {
+		u = base.ResolveReference(u)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ""
+	}
+	if s := u.String(); len(s) <= MaxImageURLLength {
+		return s
+	}
+	return ""
+}
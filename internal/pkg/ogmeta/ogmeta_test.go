@@ -0,0 +1,50 @@
+package ogmeta
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	base, _ := url.Parse("https://shop.example.com/sale/")
+	tests := []struct {
+		name string
+		doc  string
+		want Metadata
+	}{
+		{
+			name: "open graph",
+			doc: `<html><head><title>Ignored</title>
+				<meta property="og:title" content="Summer &amp; Sale">
+				<meta content='Up to 50% off' property='og:description'>
+				<meta property="og:image" content="/img/banner.png">
+				<meta property="og:title" content="Second title">
+				</head><body><meta property="og:description" content="in body"></body></html>`,
+			want: Metadata{Title: "Summer & Sale", Description: "Up to 50% off", Image: "https://shop.example.com/img/banner.png"},
+		},
+		{
+			name: "twitter and title fallbacks",
+			doc: `<head><title>
+				  Shop   Home
+				</title><meta name="description" content="Plain description">
+				<meta name="twitter:image" content="https://cdn.example.com/t.jpg"></head>`,
+			want: Metadata{Title: "Shop Home", Description: "Plain description", Image: "https://cdn.example.com/t.jpg"},
+		},
+		{
+			name: "unsafe image",
+			doc:  `<meta property="og:image" content="javascript:alert(1)"><meta property="og:title" content=x>`,
+			want: Metadata{Title: "x"},
+		},
+	}
+	for _, tt := range tests {
+		if got := Parse(tt.doc, base); got != tt.want {
+			t.Errorf("%s: Parse = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	if got := truncate("héllo", 2); got != "h" {
+		t.Errorf("truncate = %q, want %q", got, "h")
+	}
+}
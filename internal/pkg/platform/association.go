@@ -0,0 +1,132 @@
+package platform
+
+import (
+	"encoding/hex"
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// DefaultAppLinkPaths are the paths claimed for iOS apps that do not list
+// their own: every short link.
+var DefaultAppLinkPaths = []string{"/go/*"}
+
+// IOSApp is an app allowed to open Universal Links on a domain. AppID is
+// the Team ID and bundle ID, as in "ABCDE12345.com.example.app".
+type IOSApp struct {
+	AppID string
+	Paths []string
+}
+
+// AndroidApp is an app allowed to open App Links on a domain, identified by
+// its package name and the SHA-256 fingerprints of its signing certificates.
+type AndroidApp struct {
+	Package      string
+	Fingerprints []string
+}
+
+// AppleAppSiteAssociation is the /.well-known/apple-app-site-association
+// document. Details carry both the components format and the paths format
+// read by iOS 12 and earlier.
+type AppleAppSiteAssociation struct {
+	AppLinks AASAAppLinks `json:"applinks"`
+}
+
+type AASAAppLinks struct {
+	Apps    []string      `json:"apps"`
+	Details []AASADetails `json:"details"`
+}
+
+type AASADetails struct {
+	AppIDs     []string            `json:"appIDs"`
+	AppID      string              `json:"appID"`
+	Components []map[string]string `json:"components"`
+	Paths      []string            `json:"paths"`
+}
+
+// AssetStatement is one entry of /.well-known/assetlinks.json.
+type AssetStatement struct {
+	Relation []string    `json:"relation"`
+	Target   AssetTarget `json:"target"`
+}
+
+type AssetTarget struct {
+	Namespace    string   `json:"namespace"`
+	PackageName  string   `json:"package_name"`
+	Fingerprints []string `json:"sha256_cert_fingerprints"`
+}
+
+// BuildAASA returns the apple-app-site-association document for apps.
+func BuildAASA(apps []IOSApp) AppleAppSiteAssociation {
+	doc := AppleAppSiteAssociation{AppLinks: AASAAppLinks{Apps: []string{}, Details: []AASADetails{}}}
+	for _, app := range apps {
+		paths := app.Paths
+		if len(paths) == 0 {
+			paths = DefaultAppLinkPaths
+		}
+		components := make([]map[string]string, len(paths))
+		for i, p := range paths {
+			components[i] = map[string]string{"/": p}
+		}
+		doc.AppLinks.Details = append(doc.AppLinks.Details, AASADetails{
+			AppIDs:     []string{app.AppID},
+			AppID:      app.AppID,
+			Components: components,
+			Paths:      paths,
+		})
+	}
+	return doc
+}
+
+// BuildAssetLinks returns the assetlinks.json statements for apps.
+func BuildAssetLinks(apps []AndroidApp) []AssetStatement {
+	statements := make([]AssetStatement, 0, len(apps))
+	for _, app := range apps {
+		statements = append(statements, AssetStatement{
+			Relation: []string{"delegate_permission/common.handle_all_urls"},
+			Target: AssetTarget{
+				Namespace:    "android_app",
+				PackageName:  app.Package,
+				Fingerprints: app.Fingerprints,
+			},
+		})
+	}
+	return statements
+}
+
+var (
+	appleAppIDRE  = regexp.MustCompile(`^[A-Z0-9]{10}\.[A-Za-z0-9-]+(\.[A-Za-z0-9-]+)+$`)
+	packageNameRE = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*(\.[A-Za-z][A-Za-z0-9_]*)+$`)
+)
+
+// ValidAppleAppID reports whether id is a Team ID followed by a bundle ID.
+func ValidAppleAppID(id string) bool {
+	return appleAppIDRE.MatchString(id)
+}
+
+// ValidPackageName reports whether name is a valid Android application ID.
+func ValidPackageName(name string) bool {
+	return packageNameRE.MatchString(name)
+}
+
+// ValidAppLinkPath reports whether p can be claimed in an
+// apple-app-site-association file: an absolute path, optionally with *
+// and ? wildcards.
+func ValidAppLinkPath(p string) bool {
+	return strings.HasPrefix(p, "/") && len(p) <= 256 && !strings.ContainsAny(p, " \t\r\n\"")
+}
+
+// NormalizeFingerprint returns a SHA-256 certificate fingerprint in the
+// upper-case, colon-separated form assetlinks.json expects. Colons are
+// optional in the input.
+func NormalizeFingerprint(fp string) (string, error) {
+	raw, err := hex.DecodeString(strings.ReplaceAll(strings.TrimSpace(fp), ":", ""))
+	if err != nil || len(raw) != 32 {
+		return "", errors.New("fingerprint must be 32 hex bytes")
+	}
+	parts := make([]string, len(raw))
+	for i, b := range raw {
+		parts[i] = strings.ToUpper(hex.EncodeToString([]byte{b}))
+	}
+	return strings.Join(parts, ":"), nil
+}
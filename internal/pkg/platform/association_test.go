@@ -0,0 +1,51 @@
+package platform
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestNormalizeFingerprint(t *testing.T) {
+	const want = "14:6D:E9:83:C5:73:06:50:D8:EE:B9:95:2F:34:FC:64:16:A0:83:42:E6:1D:BE:A8:8A:04:96:B2:3F:CF:44:E5"
+	for _, in := range []string{
+		want,
+		"146de983c5730650d8eeb9952f34fc6416a08342e61dbea88a0496b23fcf44e5",
+		" 14:6d:e9:83:c5:73:06:50:d8:ee:b9:95:2f:34:fc:64:16:a0:83:42:e6:1d:be:a8:8a:04:96:b2:3f:cf:44:e5 ",
+	} {
+		got, err := NormalizeFingerprint(in)
+		if err != nil || got != want {
+			t.Errorf("NormalizeFingerprint(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "14:6D", "zz6de983c5730650d8eeb9952f34fc6416a08342e61dbea88a0496b23fcf44e5"} {
+		if _, err := NormalizeFingerprint(in); err == nil {
+			t.Errorf("NormalizeFingerprint(%q) succeeded", in)
+		}
+	}
+}
+
+func TestBuildAASA(t *testing.T) {
+	doc := BuildAASA([]IOSApp{
+		{AppID: "ABCDE12345.com.example.app"},
+		{AppID: "ABCDE12345.com.example.other", Paths: []string{"/go/promo*"}},
+	})
+	got, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"applinks":{"apps":[],"details":[` +
+		`{"appIDs":["ABCDE12345.com.example.app"],"appID":"ABCDE12345.com.example.app","components":[{"/":"/go/*"}],"paths":["/go/*"]},` +
+		`{"appIDs":["ABCDE12345.com.example.other"],"appID":"ABCDE12345.com.example.other","components":[{"/":"/go/promo*"}],"paths":["/go/promo*"]}]}}`
+	if string(got) != want {
+		t.Errorf("BuildAASA =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestValidAppIDs(t *testing.T) {
+	if !ValidAppleAppID("ABCDE12345.com.example.app") || ValidAppleAppID("com.example.app") {
+		t.Error("ValidAppleAppID")
+	}
+	if !ValidPackageName("com.example.app") || ValidPackageName("example") || ValidPackageName("com.1example") {
+		t.Error("ValidPackageName")
+	}
+}
@@ -0,0 +1,89 @@
+package platform
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite testdata/*.golden")
+
+// TestGolden resolves the real-world URLs in testdata/*.urls with the
+// embedded registry and compares the deep links with testdata/*.golden.
+// Run with -update after an intended change to services.json.
+func TestGolden(t *testing.T) {
+	inputs, err := filepath.Glob("testdata/*.urls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inputs) == 0 {
+		t.Fatal("no testdata/*.urls files")
+	}
+	reg := DefaultRegistry()
+	for _, input := range inputs {
+		name := strings.TrimSuffix(filepath.Base(input), ".urls")
+		t.Run(name, func(t *testing.T) {
+			got := resolveAll(t, reg, input)
+			golden := strings.TrimSuffix(input, ".urls") + ".golden"
+			if *update {
+				if err := os.WriteFile(golden, []byte(got), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%v (run go test -update to create it)", err)
+			}
+			if got != string(want) {
+				t.Errorf("%s differs from the registry output:\n%s", golden, diffLines(string(want), got))
+			}
+		})
+	}
+}
+
+func resolveAll(t *testing.T, reg *Registry, path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var b strings.Builder
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		dest := strings.TrimSpace(scanner.Text())
+		if dest == "" || strings.HasPrefix(dest, "#") {
+			continue
+		}
+		ios := reg.DeepLink(PlatformIOS, dest)
+		android := reg.DeepLink(PlatformAndroid, dest)
+		fmt.Fprintf(&b, "%s\n\tservice: %s\n\tios:     %s\n\tandroid: %s\n\n", dest, ios.Service, ios.URL, android.URL)
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return b.String()
+}
+
+// diffLines lists the lines that differ, prefixed with - and +.
+func diffLines(want, got string) string {
+	w, g := strings.Split(want, "\n"), strings.Split(got, "\n")
+	var b strings.Builder
+	for i := 0; i < len(w) || i < len(g); i++ {
+		var wl, gl string
+		if i < len(w) {
+			wl = w[i]
+		}
+		if i < len(g) {
+			gl = g[i]
+		}
+		if wl != gl {
+			fmt.Fprintf(&b, "line %d:\n-%s\n+%s\n", i+1, wl, gl)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,460 @@
+package platform
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+//go:embed services.json
+var defaultServices []byte
+
+// Registry maps destination URLs to app deep links. It is built from a JSON
+// document so services can be added or corrected without code changes:
+//
+//	{"services": [{
+//	  "name": "spotify",
+//	  "hosts": ["open.spotify.com"],
+//	  "ios": {"store_url": "https://apps.apple.com/app/id324684580"},
+//	  "android": {"package": "com.spotify.music"},
+//	  "extractors": [{
+//	    "path": "/track/{id}",
+//	    "ios": "spotify:track:{id}",
+//	    "android": "intent://track/{id}#Intent;scheme=spotify;package={package};S.browser_fallback_url={fallback};end"
+//	  }]
+//	}]}
+//
+// A host entry matches the host and its subdomains and may carry a path
+// prefix, as in "google.com/maps". Extractors are tried in order; the first
+// whose path and query match supplies the templates.
+type Registry struct {
+	services []ServiceSpec
+}
+
+type registryFile struct {
+	Services []ServiceSpec `json:"services"`
+}
+
+// ServiceSpec describes one app.
+type ServiceSpec struct {
+	Name       Service     `json:"name"`
+	Hosts      []string    `json:"hosts"`
+	IOS        AppTarget   `json:"ios"`
+	Android    AppTarget   `json:"android"`
+	Extractors []Extractor `json:"extractors"`
+}
+
+// AppTarget holds the store listing of an app on one platform.
+type AppTarget struct {
+	// Package is the Android application ID or the iOS bundle ID.
+	Package  string `json:"package,omitempty"`
+	StoreURL string `json:"store_url,omitempty"`
+}
+
+// Extractor captures IDs from a destination URL and names the deep-link
+// templates built from them.
+//
+// Path is matched segment by segment: "{name}" captures one segment, "*"
+// as the last segment matches whatever remains, anything else must match
+// literally, ignoring case. Query maps required query parameters to capture
+// names. Values restricts captures to a list of values, ignoring case; a
+// value ending in "*" matches by prefix. Host, when set, limits the
+// extractor to one of the service's hosts. PlusAsSpace decodes "+" in path
+// captures as a space, as Google Maps writes them.
+//
+// Templates may use the captures and {url} (the destination), {host},
+// {path}, {query} (the raw query string), {rest} (the query without the
+// captured parameters), {package}, {store} and {fallback} (the destination,
+// for browser fallbacks). Everything except {path}, {query} and {rest} is
+// escaped for use in a URL. Writing {?name} or {&name} prefixes the value
+// with "?" or "&" when it is not empty, so optional query strings can be
+// carried over. An empty template leaves the destination unchanged on that
+// platform.
+type Extractor struct {
+	Host        string              `json:"host,omitempty"`
+	Path        string              `json:"path"`
+	Query       map[string]string   `json:"query,omitempty"`
+	Values      map[string][]string `json:"values,omitempty"`
+	PlusAsSpace bool                `json:"plus_as_space,omitempty"`
+	IOS         string              `json:"ios,omitempty"`
+	Android     string              `json:"android,omitempty"`
+}
+
+// DeepLink is the result of resolving a destination for a platform.
+type DeepLink struct {
+	Service Service
+	// URL is the deep link, or the destination when none applies.
+	URL string
+	// StoreURL is the app's store listing on the platform, if known.
+	StoreURL string
+}
+
+var (
+	captureRE  = regexp.MustCompile(`\{([a-z0-9_]+)\}`)
+	templateRE = regexp.MustCompile(`\{([?&]?)([a-z0-9_]+)\}`)
+)
+
+var builtinVars = map[string]bool{
+	"url": true, "host": true, "path": true, "query": true, "rest": true,
+	"package": true, "store": true, "fallback": true,
+}
+
+// rawVars are substituted without escaping.
+var rawVars = map[string]bool{"path": true, "query": true, "rest": true}
+
+var defaultRegistry = sync.OnceValue(func() *Registry {
+	r, err := ParseRegistry(defaultServices)
+	if err != nil {
+		panic(fmt.Sprintf("platform: invalid embedded services.json: %v", err))
+	}
+	return r
+})
+
+// DefaultRegistry returns the registry embedded in the binary.
+func DefaultRegistry() *Registry {
+	return defaultRegistry()
+}
+
+// LoadRegistry reads a registry from a JSON file.
+func LoadRegistry(path string) (*Registry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read deep-link registry: %w", err)
+	}
+	r, err := ParseRegistry(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return r, nil
+}
+
+// ParseRegistry parses and validates a registry document.
+func ParseRegistry(data []byte) (*Registry, error) {
+	var f registryFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parse deep-link registry: %w", err)
+	}
+	seen := map[Service]bool{}
+	for i := range f.Services {
+		s := &f.Services[i]
+		s.Name = Service(strings.ToLower(strings.TrimSpace(string(s.Name))))
+		if s.Name == "" || s.Name == ServiceUnknown {
+			return nil, fmt.Errorf("service %d: invalid name %q", i+1, s.Name)
+		}
+		if seen[s.Name] {
+			return nil, fmt.Errorf("service %s: duplicate name", s.Name)
+		}
+		seen[s.Name] = true
+		if err := s.validate(); err != nil {
+			return nil, fmt.Errorf("service %s: %w", s.Name, err)
+		}
+	}
+	return &Registry{services: f.Services}, nil
+}
+
+func (s *ServiceSpec) validate() error {
+	if len(s.Hosts) == 0 {
+		return errors.New("no hosts")
+	}
+	for j, h := range s.Hosts {
+		h = strings.ToLower(strings.TrimSpace(h))
+		if h == "" || strings.Contains(h, "://") {
+			return fmt.Errorf("invalid host %q", s.Hosts[j])
+		}
+		s.Hosts[j] = h
+	}
+	for j := range s.Extractors {
+		e := &s.Extractors[j]
+		if err := e.validate(); err != nil {
+			return fmt.Errorf("extractor %d: %w", j+1, err)
+		}
+	}
+	return nil
+}
+
+func (e *Extractor) validate() error {
+	e.Host = strings.ToLower(strings.TrimSpace(e.Host))
+	if !strings.HasPrefix(e.Path, "/") {
+		return fmt.Errorf("path %q must start with /", e.Path)
+	}
+	vars := map[string]bool{}
+	segs := pathSegments(e.Path)
+	for i, seg := range segs {
+		switch {
+		case seg == "*":
+			if i != len(segs)-1 {
+				return errors.New("* must be the last path segment")
+			}
+		case strings.HasPrefix(seg, "{"):
+			m := captureRE.FindStringSubmatch(seg)
+			if m == nil || m[0] != seg {
+				return fmt.Errorf("invalid capture %q", seg)
+			}
+			vars[m[1]] = true
+		}
+	}
+	for param, name := range e.Query {
+		if param == "" || !captureRE.MatchString("{"+name+"}") {
+			return fmt.Errorf("invalid query capture %q=%q", param, name)
+		}
+		vars[name] = true
+	}
+	for name := range vars {
+		if builtinVars[name] {
+			return fmt.Errorf("capture {%s} shadows a built-in", name)
+		}
+	}
+	for name, values := range e.Values {
+		if !vars[name] {
+			return fmt.Errorf("values for unknown capture {%s}", name)
+		}
+		if len(values) == 0 {
+			return fmt.Errorf("no values for {%s}", name)
+		}
+	}
+	for _, tmpl := range []string{e.IOS, e.Android} {
+		for _, m := range templateRE.FindAllStringSubmatch(tmpl, -1) {
+			if !vars[m[2]] && !builtinVars[m[2]] {
+				return fmt.Errorf("template uses unknown {%s}", m[2])
+			}
+		}
+	}
+	return nil
+}
+
+// Service returns the service a destination belongs to, or ServiceUnknown.
+func (r *Registry) Service(destination string) Service {
+	u, err := url.Parse(destination)
+	if err != nil {
+		return ServiceUnknown
+	}
+	if s := r.lookup(u); s != nil {
+		return s.Name
+	}
+	return ServiceUnknown
+}
+
+// DeepLink resolves destination for a platform. Web visitors, unknown
+// services and URLs no extractor recognizes get the destination back.
+func (r *Registry) DeepLink(p Platform, destination string) DeepLink {
+	link := DeepLink{Service: ServiceUnknown, URL: destination}
+	u, err := url.Parse(destination)
+	if err != nil {
+		return link
+	}
+	s := r.lookup(u)
+	if s == nil {
+		return link
+	}
+	link.Service = s.Name
+	link.URL = r.build(s, p, u, destination)
+	switch p {
+	case PlatformIOS:
+		link.StoreURL = s.IOS.StoreURL
+	case PlatformAndroid:
+		link.StoreURL = s.Android.StoreURL
+	}
+	return link
+}
+
+// generate builds the deep link for a service already detected by name.
+func (r *Registry) generate(p Platform, service Service, destination string) string {
+	u, err := url.Parse(destination)
+	if err != nil {
+		return destination
+	}
+	for i := range r.services {
+		if s := &r.services[i]; s.Name == service {
+			return r.build(s, p, u, destination)
+		}
+	}
+	return destination
+}
+
+func (r *Registry) lookup(u *url.URL) *ServiceSpec {
+	host := urlHost(u)
+	if host == "" {
+		return nil
+	}
+	for i := range r.services {
+		for _, h := range r.services[i].Hosts {
+			if matchHost(h, host, u.EscapedPath()) {
+				return &r.services[i]
+			}
+		}
+	}
+	return nil
+}
+
+func (r *Registry) build(s *ServiceSpec, p Platform, u *url.URL, destination string) string {
+	var target AppTarget
+	switch p {
+	case PlatformIOS:
+		target = s.IOS
+	case PlatformAndroid:
+		target = s.Android
+	default:
+		return destination
+	}
+	host := urlHost(u)
+	for _, e := range s.Extractors {
+		if e.Host != "" && !matchHost(e.Host, host, u.EscapedPath()) {
+			continue
+		}
+		vars, ok := e.match(u)
+		if !ok {
+			continue
+		}
+		tmpl := e.IOS
+		if p == PlatformAndroid {
+			tmpl = e.Android
+		}
+		if tmpl == "" {
+			return destination
+		}
+		vars["url"] = destination
+		vars["fallback"] = destination
+		vars["host"] = host
+		vars["path"] = strings.TrimPrefix(u.EscapedPath(), "/")
+		vars["query"] = u.RawQuery
+		vars["rest"] = e.restQuery(u.RawQuery)
+		vars["package"] = target.Package
+		vars["store"] = target.StoreURL
+		return expand(tmpl, vars)
+	}
+	return destination
+}
+
+// match reports whether u fits the extractor and returns the captures.
+func (e *Extractor) match(u *url.URL) (map[string]string, bool) {
+	vars := map[string]string{}
+	pattern := pathSegments(e.Path)
+	path := pathSegments(u.Path)
+	for i, seg := range pattern {
+		if seg == "*" {
+			break
+		}
+		if i >= len(path) {
+			return nil, false
+		}
+		if strings.HasPrefix(seg, "{") {
+			v := path[i]
+			if e.PlusAsSpace {
+				v = strings.ReplaceAll(v, "+", " ")
+			}
+			vars[seg[1:len(seg)-1]] = v
+		} else if !strings.EqualFold(seg, path[i]) {
+			return nil, false
+		}
+	}
+	if len(pattern) == 0 || pattern[len(pattern)-1] != "*" {
+		if len(path) != len(pattern) {
+			return nil, false
+		}
+	}
+	q := u.Query()
+	for param, name := range e.Query {
+		v := q.Get(param)
+		if v == "" {
+			return nil, false
+		}
+		vars[name] = v
+	}
+	for name, values := range e.Values {
+		if !matchValue(values, vars[name]) {
+			return nil, false
+		}
+	}
+	return vars, true
+}
+
+func matchValue(values []string, v string) bool {
+	for _, want := range values {
+		if prefix, ok := strings.CutSuffix(want, "*"); ok {
+			if len(v) > len(prefix) && strings.EqualFold(v[:len(prefix)], prefix) {
+				return true
+			}
+		} else if strings.EqualFold(v, want) {
+			return true
+		}
+	}
+	return false
+}
+
+// restQuery drops the captured parameters from a raw query string, keeping
+// the order and encoding of the others.
+func (e *Extractor) restQuery(raw string) string {
+	if len(e.Query) == 0 || raw == "" {
+		return raw
+	}
+	var kept []string
+	for _, part := range strings.Split(raw, "&") {
+		key, _, _ := strings.Cut(part, "=")
+		if k, err := url.QueryUnescape(key); err == nil {
+			if _, captured := e.Query[k]; captured {
+				continue
+			}
+		}
+		if part != "" {
+			kept = append(kept, part)
+		}
+	}
+	return strings.Join(kept, "&")
+}
+
+func expand(tmpl string, vars map[string]string) string {
+	return templateRE.ReplaceAllStringFunc(tmpl, func(m string) string {
+		sub := templateRE.FindStringSubmatch(m)
+		op, name := sub[1], sub[2]
+		v := vars[name]
+		if !rawVars[name] {
+			v = escape(v)
+		}
+		if op != "" && v != "" {
+			v = op + v
+		}
+		return v
+	})
+}
+
+// escape makes v safe in a path segment and in a query value alike.
+func escape(v string) string {
+	return strings.ReplaceAll(url.QueryEscape(v), "+", "%20")
+}
+
+// matchHost reports whether pattern ("example.com" or "example.com/prefix")
+// matches host, its subdomains and, with a prefix, the path.
+func matchHost(pattern, host, path string) bool {
+	domain, prefix, _ := strings.Cut(pattern, "/")
+	if host != domain && !strings.HasSuffix(host, "."+domain) {
+		return false
+	}
+	if prefix == "" {
+		return true
+	}
+	path = strings.ToLower(strings.TrimPrefix(path, "/"))
+	return path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
+func urlHost(u *url.URL) string {
+	host := u.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.TrimSuffix(strings.ToLower(host), ".")
+}
+
+func pathSegments(p string) []string {
+	p = strings.Trim(p, "/")
+	if p == "" {
+		return nil
+	}
+	return strings.Split(p, "/")
+}
@@ -0,0 +1,57 @@
+package platform
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDefaultRegistry(t *testing.T) {
+	d := &DefaultPlatformDetector{}
+	tests := []struct {
+		platform Platform
+		dest     string
+		service  Service
+		want     string
+	}{
+		{PlatformIOS, "https://open.spotify.com/track/4uLU6hMCjMI75M1A2tKUQC", ServiceSpotify, "spotify:track:4uLU6hMCjMI75M1A2tKUQC"},
+		{PlatformIOS, "https://www.youtube.com/watch?v=dQw4w9WgXcQ&t=42", ServiceYouTube, "youtube://www.youtube.com/watch?v=dQw4w9WgXcQ&t=42"},
+		{PlatformIOS, "https://youtu.be/dQw4w9WgXcQ", ServiceYouTube, "youtube://www.youtube.com/watch?v=dQw4w9WgXcQ"},
+		{PlatformIOS, "https://m.uber.com/ul/?action=setPickup&pickup=my_location", ServiceUber, "uber://?action=setPickup&pickup=my_location"},
+		{PlatformIOS, "https://www.google.com/maps/search/?api=1&query=Eiffel%20Tower", ServiceGoogleMaps, "comgooglemaps://?q=Eiffel%20Tower"},
+		{PlatformIOS, "https://www.google.com/search?q=maps", ServiceUnknown, "https://www.google.com/search?q=maps"},
+		{PlatformIOS, "https://www.uber.com/us/en/about/", ServiceUber, "https://www.uber.com/us/en/about/"},
+		{PlatformIOS, "https://notspotify.com/track/1", ServiceUnknown, "https://notspotify.com/track/1"},
+		{PlatformWeb, "https://open.spotify.com/track/4uLU6hMCjMI75M1A2tKUQC", ServiceSpotify, "https://open.spotify.com/track/4uLU6hMCjMI75M1A2tKUQC"},
+		{PlatformAndroid, "https://www.instagram.com/nasa/", ServiceInstagram,
+			"intent://instagram.com/_u/nasa#Intent;scheme=https;package=com.instagram.android;S.browser_fallback_url=https%3A%2F%2Fwww.instagram.com%2Fnasa%2F;end"},
+	}
+	for _, tt := range tests {
+		if got := d.GetService(tt.dest); got != tt.service {
+			t.Errorf("GetService(%q) = %q, want %q", tt.dest, got, tt.service)
+		}
+		if got := d.GenerateDeepLink(tt.platform, tt.service, tt.dest); got != tt.want {
+			t.Errorf("GenerateDeepLink(%s, %q) = %q, want %q", tt.platform, tt.dest, got, tt.want)
+		}
+	}
+}
+
+func TestParseRegistryErrors(t *testing.T) {
+	tests := []struct {
+		doc  string
+		want string
+	}{
+		{`{"services":[{"name":"a"}]}`, "no hosts"},
+		{`{"services":[{"name":"a","hosts":["a.com"]},{"name":"A","hosts":["b.com"]}]}`, "duplicate"},
+		{`{"services":[{"name":"a","hosts":["a.com"],"extractors":[{"path":"x"}]}]}`, "must start with /"},
+		{`{"services":[{"name":"a","hosts":["a.com"],"extractors":[{"path":"/*/x"}]}]}`, "last path segment"},
+		{`{"services":[{"name":"a","hosts":["a.com"],"extractors":[{"path":"/{id}","ios":"a://{other}"}]}]}`, "unknown {other}"},
+		{`{"services":[{"name":"a","hosts":["a.com"],"extractors":[{"path":"/{url}"}]}]}`, "shadows"},
+		{`{"services":[{"name":"a","hosts":["a.com"],"extractors":[{"path":"/{id}","values":{"kind":["x"]}}]}]}`, "unknown capture {kind}"},
+	}
+	for _, tt := range tests {
+		_, err := ParseRegistry([]byte(tt.doc))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("ParseRegistry(%s) error = %v, want %q", tt.doc, err, tt.want)
+		}
+	}
+}
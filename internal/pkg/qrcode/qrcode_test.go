@@ -0,0 +1,95 @@
+package qrcode
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestDataCodewords(t *testing.T) {
+	tests := []struct {
+		version int
+		level   Level
+		want    int
+	}{
+		{1, Low, 19},
+		{1, Medium, 16},
+		{1, Quartile, 13},
+		{1, High, 9},
+		{5, Quartile, 62},
+		{10, Medium, 216},
+		{40, Low, 2956},
+		{40, High, 1276},
+	}
+	for _, tt := range tests {
+		if got := dataCodewords(tt.version, tt.level); got != tt.want {
+			t.Errorf("dataCodewords(%d, %s) = %d, want %d", tt.version, tt.level, got, tt.want)
+		}
+	}
+}
+
+func TestRSRemainder(t *testing.T) {
+	// "HELLO WORLD" as 1-M, from the worked example at thonky.com.
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	want := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+	if got := rsRemainder(data, rsDivisor(10)); !bytes.Equal(got, want) {
+		t.Errorf("rsRemainder = %v, want %v", got, want)
+	}
+}
+
+func TestFormatAndVersionBits(t *testing.T) {
+	formats := []struct {
+		level Level
+		mask  int
+		want  int
+	}{
+		{Low, 0, 0b111011111000100},
+		{Medium, 0, 0b101010000010010},
+		{Quartile, 0, 0b011010101011111},
+		{High, 0, 0b001011010001001},
+		{Low, 7, 0b110100101110110},
+	}
+	for _, tt := range formats {
+		if got := formatBits(tt.level, tt.mask); got != tt.want {
+			t.Errorf("formatBits(%s, %d) = %015b, want %015b", tt.level, tt.mask, got, tt.want)
+		}
+	}
+	if got := versionBits(7); got != 0b000111110010010100 {
+		t.Errorf("versionBits(7) = %018b", got)
+	}
+}
+
+func TestAlignmentPositions(t *testing.T) {
+	tests := map[int][]int{
+		1:  nil,
+		2:  {6, 18},
+		7:  {6, 22, 38},
+		32: {6, 34, 60, 86, 112, 138},
+		40: {6, 30, 58, 86, 114, 142, 170},
+	}
+	for version, want := range tests {
+		if got := alignmentPositions(version); !reflect.DeepEqual(got, want) {
+			t.Errorf("alignmentPositions(%d) = %v, want %v", version, got, want)
+		}
+	}
+}
+
+func TestEncode(t *testing.T) {
+	code, err := Encode([]byte("https://redo.ai/go/abc123?src=qr"), Medium)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code.Version != 3 || code.Size != 29 {
+		t.Errorf("got version %d size %d, want 3 and 29", code.Version, code.Size)
+	}
+	// Finder pattern corners and the always-dark module.
+	for _, p := range [][2]int{{0, 0}, {28, 0}, {0, 28}, {8, 21}} {
+		if !code.Dark(p[0], p[1]) {
+			t.Errorf("module %v should be dark", p)
+		}
+	}
+
+	if _, err := Encode(make([]byte, 2954), Low); err != ErrTooLong {
+		t.Errorf("oversized data: err = %v, want ErrTooLong", err)
+	}
+}
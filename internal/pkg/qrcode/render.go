@@ -0,0 +1,156 @@
+package qrcode
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Options control how a symbol is drawn.
+type Options struct {
+	// Size is the width and height of the output in pixels.
+	Size int
+	// Margin is the quiet zone in modules; the specification asks for 4.
+	Margin     int
+	Foreground color.Color
+	Background color.Color
+	// Logo, when set, is drawn over the centre of the symbol on a patch of
+	// background. Encode at High so the covered modules can be recovered.
+	Logo image.Image
+}
+
+// logoFraction is the share of the symbol width a logo may cover.
+const logoFraction = 0.22
+
+func (o Options) colors() (fg, bg color.Color) {
+	fg, bg = o.Foreground, o.Background
+	if fg == nil {
+		fg = color.Black
+	}
+	if bg == nil {
+		bg = color.White
+	}
+	return fg, bg
+}
+
+// layout returns the pixels per module and the offset that centres the
+// symbol in an output of o.Size pixels.
+func (c *Code) layout(o Options) (scale, offset, size int) {
+	total := c.Size + 2*o.Margin
+	size = max(o.Size, total)
+	scale = size / total
+	offset = (size-scale*total)/2 + scale*o.Margin
+	return scale, offset, size
+}
+
+// logoBox returns the square the logo is drawn in. Callers clear one module
+// of background around it.
+func (c *Code) logoBox(scale, offset int) image.Rectangle {
+	side := int(float64(c.Size*scale) * logoFraction)
+	start := offset + (c.Size*scale-side)/2
+	return image.Rect(start, start, start+side, start+side)
+}
+
+// PNG writes the symbol as a PNG image.
+func (c *Code) PNG(w io.Writer, o Options) error {
+	scale, offset, size := c.layout(o)
+	fg, bg := o.colors()
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.Draw(img, img.Bounds(), image.NewUniform(bg), image.Point{}, draw.Src)
+	ink := image.NewUniform(fg)
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if c.Dark(x, y) {
+				r := image.Rect(offset+x*scale, offset+y*scale, offset+(x+1)*scale, offset+(y+1)*scale)
+				draw.Draw(img, r, ink, image.Point{}, draw.Src)
+			}
+		}
+	}
+	if o.Logo != nil {
+		box := c.logoBox(scale, offset)
+		draw.Draw(img, box.Inset(-scale), image.NewUniform(bg), image.Point{}, draw.Src)
+		draw.Draw(img, box, fit(o.Logo, box.Dx()), image.Point{}, draw.Over)
+	}
+	return png.Encode(w, img)
+}
+
+// SVG writes the symbol as an SVG document, one path for all dark modules.
+func (c *Code) SVG(w io.Writer, o Options) error {
+	scale, offset, size := c.layout(o)
+	fg, bg := o.colors()
+
+	var path strings.Builder
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if c.Dark(x, y) {
+				fmt.Fprintf(&path, "M%d %dh%dv%dh-%dz", offset+x*scale, offset+y*scale, scale, scale, scale)
+			}
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`, size, size, size, size)
+	fmt.Fprintf(&b, `<rect width="100%%" height="100%%" fill="%s"/>`, hexColor(bg))
+	fmt.Fprintf(&b, `<path fill="%s" d="%s"/>`, hexColor(fg), path.String())
+	if o.Logo != nil {
+		box := c.logoBox(scale, offset)
+		outer := box.Inset(-scale)
+		var logo bytes.Buffer
+		if err := png.Encode(&logo, o.Logo); err != nil {
+			return fmt.Errorf("encode logo: %w", err)
+		}
+		fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s"/>`, outer.Min.X, outer.Min.Y, outer.Dx(), outer.Dy(), hexColor(bg))
+		fmt.Fprintf(&b, `<image x="%d" y="%d" width="%d" height="%d" preserveAspectRatio="xMidYMid meet" href="data:image/png;base64,%s"/>`,
+			box.Min.X, box.Min.Y, box.Dx(), box.Dy(), base64.StdEncoding.EncodeToString(logo.Bytes()))
+	}
+	b.WriteString(`</svg>`)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// fit scales img with nearest neighbour sampling so its longer side is side
+// pixels, centred in a side x side square.
+func fit(img image.Image, side int) image.Image {
+	src := img.Bounds()
+	out := image.NewRGBA(image.Rect(0, 0, side, side))
+	if src.Empty() || side <= 0 {
+		return out
+	}
+	longest := max(src.Dx(), src.Dy())
+	w, h := src.Dx()*side/longest, src.Dy()*side/longest
+	ox, oy := (side-w)/2, (side-h)/2
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			out.Set(ox+x, oy+y, img.At(src.Min.X+x*longest/side, src.Min.Y+y*longest/side))
+		}
+	}
+	return out
+}
+
+// ParseColor accepts #rgb, #rrggbb or the same without the hash.
+func ParseColor(s string) (color.Color, error) {
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) != 6 {
+		return nil, fmt.Errorf("invalid color %q", s)
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid color %q", s)
+	}
+	return color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xFF}, nil
+}
+
+func hexColor(c color.Color) string {
+	r, g, b, _ := c.RGBA()
+	return fmt.Sprintf("#%02x%02x%02x", r>>8, g>>8, b>>8)
+}
@@ -0,0 +1,287 @@
+// Package rules routes a visit to a destination by matching it against an
+// ordered list of conditions, such as country, language, device, time of
+// day, referrer or query parameters.
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"redo.ai/internal/model"
+)
+
+// MaxRules bounds the rules a single link may carry.
+const MaxRules = 50
+
+// Rule is a routing rule as stored on a link.
+type Rule = model.RoutingRule
+
+// Visit is what the rules are matched against.
+type Visit struct {
+	Country string
+	// Languages in order of preference, see ParseAcceptLanguage.
+	Languages []string
+	Device    string
+	Referrer  string
+	Query     url.Values
+	Time      time.Time
+}
+
+// Match returns the first rule matching v.
+func Match(rules []Rule, v Visit) (Rule, int, bool) {
+	for i, r := range rules {
+		if matches(r, v) {
+			return r, i, true
+		}
+	}
+	return Rule{}, -1, false
+}
+
+func matches(r Rule, v Visit) bool {
+	if len(r.Countries) > 0 && !containsFold(r.Countries, v.Country) {
+		return false
+	}
+	if len(r.Languages) > 0 && !matchLanguage(r.Languages, v.Languages) {
+		return false
+	}
+	if len(r.Devices) > 0 && !containsFold(r.Devices, v.Device) {
+		return false
+	}
+	if len(r.ReferrerDomains) > 0 && !matchReferrer(r.ReferrerDomains, v.Referrer) {
+		return false
+	}
+	for k, want := range r.Query {
+		got, ok := v.Query[k]
+		if !ok || (want != "" && (len(got) == 0 || got[0] != want)) {
+			return false
+		}
+	}
+	if r.StartsAt != nil && v.Time.Before(*r.StartsAt) {
+		return false
+	}
+	if r.EndsAt != nil && !v.Time.Before(*r.EndsAt) {
+		return false
+	}
+	if len(r.Days) == 0 && r.TimeFrom == "" {
+		return true
+	}
+
+	loc, err := location(r.Timezone)
+	if err != nil {
+		return false
+	}
+	local := v.Time.In(loc)
+	if len(r.Days) > 0 && !containsFold(r.Days, dayName(local.Weekday())) {
+		return false
+	}
+	if r.TimeFrom != "" {
+		from, _ := parseClock(r.TimeFrom)
+		to, _ := parseClock(r.TimeTo)
+		now := local.Hour()*60 + local.Minute()
+		if from <= to {
+			return now >= from && now < to
+		}
+		return now >= from || now < to
+	}
+	return true
+}
+
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchLanguage(want, prefs []string) bool {
+	if len(prefs) == 0 {
+		return false
+	}
+	top := strings.ToLower(prefs[0])
+	base, _, _ := strings.Cut(top, "-")
+	for _, w := range want {
+		w = strings.ToLower(w)
+		if w == top || (!strings.Contains(w, "-") && w == base) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchReferrer(domains []string, referrer string) bool {
+	u, err := url.Parse(referrer)
+	if err != nil || u.Hostname() == "" {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, d := range domains {
+		d = strings.ToLower(strings.TrimPrefix(d, "."))
+		if host == d || strings.HasSuffix(host, "."+d) {
+			return true
+		}
+	}
+	return false
+}
+
+// locations caches time zones, which LoadLocation reads from disk each time.
+var locations sync.Map
+
+func location(name string) (*time.Location, error) {
+	if name == "" {
+		return time.UTC, nil
+	}
+	if loc, ok := locations.Load(name); ok {
+		return loc.(*time.Location), nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+	locations.Store(name, loc)
+	return loc, nil
+}
+
+var days = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+func dayName(d time.Weekday) string {
+	return days[d]
+}
+
+// parseClock parses HH:MM into minutes after midnight.
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// ParseAcceptLanguage returns the language tags of an Accept-Language
+// header, most preferred first, without wildcards or q=0 entries.
+func ParseAcceptLanguage(header string) []string {
+	type tag struct {
+		lang string
+		q    float64
+	}
+	var tags []tag
+	for _, part := range strings.Split(header, ",") {
+		lang, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		lang = strings.TrimSpace(lang)
+		if lang == "" || lang == "*" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q > 0 {
+			tags = append(tags, tag{lang, q})
+		}
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+	out := make([]string, len(tags))
+	for i, t := range tags {
+		out[i] = t.lang
+	}
+	return out
+}
+
+// Validate checks rules before they are stored. Destinations are left to the
+// caller's own URL checks.
+func Validate(rules []Rule) error {
+	if len(rules) > MaxRules {
+		return fmt.Errorf("at most %d rules are allowed", MaxRules)
+	}
+	for i, r := range rules {
+		if err := validate(r); err != nil {
+			return fmt.Errorf("rule %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+func validate(r Rule) error {
+	if r.Destination == "" {
+		return errors.New("destination is required")
+	}
+	for _, c := range r.Countries {
+		if len(c) != 2 || !isAlnum(c) || strings.ContainsAny(c, "0123456789") {
+			return fmt.Errorf("invalid country %q", c)
+		}
+	}
+	for _, l := range r.Languages {
+		if l == "" || len(l) > 35 || !isAlnum(strings.ReplaceAll(l, "-", "")) {
+			return fmt.Errorf("invalid language %q", l)
+		}
+	}
+	for _, d := range r.Devices {
+		if !containsFold([]string{"ios", "android", "web"}, d) {
+			return fmt.Errorf("invalid device %q", d)
+		}
+	}
+	for _, d := range r.Days {
+		if !containsFold(days, d) {
+			return fmt.Errorf("invalid day %q", d)
+		}
+	}
+	for _, d := range r.ReferrerDomains {
+		if d == "" || strings.ContainsAny(d, "/:") {
+			return fmt.Errorf("invalid referrer domain %q", d)
+		}
+	}
+	for k := range r.Query {
+		if k == "" {
+			return errors.New("empty query parameter name")
+		}
+	}
+	if (r.TimeFrom == "") != (r.TimeTo == "") {
+		return errors.New("time_from and time_to must be set together")
+	}
+	if r.TimeFrom != "" {
+		if _, err := parseClock(r.TimeFrom); err != nil {
+			return fmt.Errorf("invalid time_from %q", r.TimeFrom)
+		}
+		if _, err := parseClock(r.TimeTo); err != nil {
+			return fmt.Errorf("invalid time_to %q", r.TimeTo)
+		}
+	}
+	if r.Timezone != "" {
+		if _, err := location(r.Timezone); err != nil {
+			return fmt.Errorf("invalid timezone %q", r.Timezone)
+		}
+	}
+	if r.StartsAt != nil && r.EndsAt != nil && !r.EndsAt.After(*r.StartsAt) {
+		return errors.New("ends_at must be after starts_at")
+	}
+	if !hasCondition(r) {
+		return errors.New("at least one condition is required")
+	}
+	return nil
+}
+
+func hasCondition(r Rule) bool {
+	return len(r.Countries) > 0 || len(r.Languages) > 0 || len(r.Devices) > 0 ||
+		len(r.ReferrerDomains) > 0 || len(r.Query) > 0 || len(r.Days) > 0 ||
+		r.TimeFrom != "" || r.StartsAt != nil || r.EndsAt != nil
+}
+
+func isAlnum(s string) bool {
+	for _, c := range s {
+		if (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			return false
+		}
+	}
+	return s != ""
+}
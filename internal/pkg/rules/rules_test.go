@@ -0,0 +1,95 @@
+package rules
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMatch(t *testing.T) {
+	// Wednesday 2024-05-15 21:30 UTC, 23:30 in Berlin.
+	now := time.Date(2024, 5, 15, 21, 30, 0, 0, time.UTC)
+	visit := Visit{
+		Country:   "DE",
+		Languages: []string{"de-DE", "en"},
+		Device:    "iOS",
+		Referrer:  "https://m.facebook.com/story",
+		Query:     url.Values{"utm_source": {"newsletter"}, "promo": {""}},
+		Time:      now,
+	}
+	past := now.Add(-time.Hour)
+
+	tests := []struct {
+		name string
+		rule Rule
+		want bool
+	}{
+		{"country", Rule{Countries: []string{"fr", "de"}}, true},
+		{"other country", Rule{Countries: []string{"US"}}, false},
+		{"language base", Rule{Languages: []string{"de"}}, true},
+		{"language exact", Rule{Languages: []string{"de-AT"}}, false},
+		{"only top language", Rule{Languages: []string{"en"}}, false},
+		{"device", Rule{Devices: []string{"ios"}}, true},
+		{"referrer subdomain", Rule{ReferrerDomains: []string{"facebook.com"}}, true},
+		{"referrer suffix only", Rule{ReferrerDomains: []string{"book.com"}}, false},
+		{"query value", Rule{Query: map[string]string{"utm_source": "newsletter"}}, true},
+		{"query presence", Rule{Query: map[string]string{"promo": ""}}, true},
+		{"query mismatch", Rule{Query: map[string]string{"utm_source": "ads"}}, false},
+		{"day in zone", Rule{Days: []string{"wed"}, Timezone: "Europe/Berlin"}, true},
+		{"window", Rule{TimeFrom: "21:00", TimeTo: "22:00"}, true},
+		{"window in zone", Rule{TimeFrom: "21:00", TimeTo: "22:00", Timezone: "Europe/Berlin"}, false},
+		{"overnight window", Rule{TimeFrom: "23:00", TimeTo: "06:00", Timezone: "Europe/Berlin"}, true},
+		{"ended", Rule{EndsAt: &past}, false},
+		{"started", Rule{StartsAt: &past}, true},
+		{"all conditions", Rule{Countries: []string{"DE"}, Devices: []string{"android"}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matches(tt.rule, visit); got != tt.want {
+				t.Errorf("matches = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	rules := []Rule{
+		{Destination: "https://example.com/us", Countries: []string{"US"}},
+		{Destination: "https://example.com/de", Languages: []string{"de"}},
+		{Destination: "https://example.com/eu", Countries: []string{"DE"}},
+	}
+	if r, i, ok := Match(rules, visit); !ok || i != 1 || r.Destination != "https://example.com/de" {
+		t.Errorf("Match = %v, %d, %v; want the second rule", r.Destination, i, ok)
+	}
+	if _, _, ok := Match(rules[:1], visit); ok {
+		t.Error("Match should fall back when no rule matches")
+	}
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	got := ParseAcceptLanguage("fr-CH, fr;q=0.9, en;q=0.8, de;q=0.7, *;q=0.5, it;q=0")
+	want := []string{"fr-CH", "fr", "en", "de"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseAcceptLanguage = %v, want %v", got, want)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	bad := []Rule{
+		{Destination: "https://example.com"},
+		{Destination: "https://example.com", Countries: []string{"USA"}},
+		{Destination: "https://example.com", Devices: []string{"blackberry"}},
+		{Destination: "https://example.com", TimeFrom: "09:00"},
+		{Destination: "https://example.com", Days: []string{"monday"}},
+		{Destination: "https://example.com", Days: []string{"mon"}, Timezone: "Mars/Base"},
+		{Countries: []string{"US"}},
+	}
+	for _, r := range bad {
+		if err := Validate([]Rule{r}); err == nil {
+			t.Errorf("Validate(%+v) should fail", r)
+		}
+	}
+	good := Rule{Destination: "https://example.com", Days: []string{"Sat", "sun"}, TimeFrom: "22:00", TimeTo: "02:00", Timezone: "America/New_York"}
+	if err := Validate([]Rule{good}); err != nil {
+		t.Errorf("Validate: %v", err)
+	}
+}
@@ -0,0 +1,71 @@
+package shortcode
+
+import (
+	_ "embed"
+	"strings"
+	"sync"
+)
+
+//go:embed blocklist.txt
+var defaultBlocklist string
+
+// Blocklist rejects codes containing offensive words, including common
+// digit-for-letter spellings and words split by separators.
+type Blocklist struct {
+	words []string
+}
+
+// NewBlocklist builds a blocklist from words; blank entries are ignored.
+func NewBlocklist(words []string) *Blocklist {
+	b := &Blocklist{}
+	for _, w := range words {
+		if w = normalize(w); w != "" {
+			b.words = append(b.words, w)
+		}
+	}
+	return b
+}
+
+var (
+	defaultOnce sync.Once
+	defaultList *Blocklist
+)
+
+// DefaultBlocklist returns the built-in list, one word per line with #
+// comments.
+func DefaultBlocklist() *Blocklist {
+	defaultOnce.Do(func() {
+		var words []string
+		for _, line := range strings.Split(defaultBlocklist, "\n") {
+			if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+				words = append(words, line)
+			}
+		}
+		defaultList = NewBlocklist(words)
+	})
+	return defaultList
+}
+
+// Blocked reports whether code contains a blocked word.
+func (b *Blocklist) Blocked(code string) bool {
+	s := normalize(code)
+	for _, w := range b.words {
+		if strings.Contains(s, w) {
+			return true
+		}
+	}
+	return false
+}
+
+var leet = strings.NewReplacer("0", "o", "1", "i", "3", "e", "4", "a", "5", "s", "7", "t", "8", "b", "@", "a", "$", "s")
+
+// normalize lower-cases s, undoes digit substitutions and drops separators.
+func normalize(s string) string {
+	s = leet.Replace(strings.ToLower(s))
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == '_' || r == '.' || r == ' ' {
+			return -1
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,234 @@
+// Package shortcode generates the globally unique codes links are resolved by.
+package shortcode
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Strategies selectable through SHORT_CODE_STRATEGY.
+const (
+	StrategyRandom     = "random"
+	StrategySequential = "sequential"
+	StrategyWords      = "words"
+)
+
+const (
+	alphabet      = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	DefaultLength = 7
+	MinLength     = 4
+	MaxLength     = 32
+	// DefaultWordDigits gives the words strategy 56*56*10^4 (about 31M)
+	// codes, enough that the link service's few retries rarely all collide.
+	DefaultWordDigits = 4
+	MaxWordDigits     = 9
+	// maxBlockedRetries bounds how often a blocked code is regenerated.
+	maxBlockedRetries = 20
+)
+
+var ErrExhausted = errors.New("could not generate an acceptable short code")
+
+// Generator produces candidate short codes. Callers still have to handle the
+// unique constraint: Random and Words can collide.
+type Generator interface {
+	Generate(ctx context.Context) (string, error)
+}
+
+// Random draws Length characters of base62 from crypto/rand.
+type Random struct {
+	Length int
+}
+
+func (g Random) Generate(ctx context.Context) (string, error) {
+	return randomString(alphabet, g.Length)
+}
+
+func randomString(chars string, n int) (string, error) {
+	max := big.NewInt(int64(len(chars)))
+	var b strings.Builder
+	b.Grow(n)
+	for i := 0; i < n; i++ {
+		idx, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", fmt.Errorf("read random: %w", err)
+		}
+		b.WriteByte(chars[idx.Int64()])
+	}
+	return b.String(), nil
+}
+
+// Sequential encodes numbers from Next, typically a database sequence, as
+// base62. The numbers are scrambled with an affine permutation keyed by Salt
+// so consecutive links do not get guessable neighbouring codes. Codes are at
+// least MinLength characters and grow by one character each time the space
+// of the current length is used up, so they never collide.
+type Sequential struct {
+	Next      func(ctx context.Context) (uint64, error)
+	Salt      uint64
+	MinLength int
+}
+
+func (g Sequential) Generate(ctx context.Context) (string, error) {
+	n, err := g.Next(ctx)
+	if err != nil {
+		return "", fmt.Errorf("next sequence value: %w", err)
+	}
+	length := g.MinLength
+	if length < 1 {
+		length = DefaultLength
+	}
+	// Find the code length whose space holds n, numbering each length's
+	// space from zero.
+	space := pow62(length)
+	for n >= space && length < MaxLength {
+		n -= space
+		length++
+		space = pow62(length)
+	}
+	return encode(scramble(n, space, g.Salt), length), nil
+}
+
+// scramble maps n onto [0, space) with x*a + b mod space. a is odd and not a
+// multiple of 31, so it is coprime with 62^k and the map is a bijection.
+func scramble(n, space, salt uint64) uint64 {
+	a := new(big.Int).SetUint64(salt | 1)
+	for new(big.Int).Mod(a, big.NewInt(31)).Sign() == 0 {
+		a.Add(a, big.NewInt(2))
+	}
+	x := new(big.Int).SetUint64(n)
+	x.Mul(x, a)
+	x.Add(x, new(big.Int).SetUint64(salt>>1))
+	x.Mod(x, new(big.Int).SetUint64(space))
+	return x.Uint64()
+}
+
+func pow62(n int) uint64 {
+	p := uint64(1)
+	for i := 0; i < n; i++ {
+		if p > ^uint64(0)/62 {
+			return ^uint64(0)
+		}
+		p *= 62
+	}
+	return p
+}
+
+func encode(n uint64, length int) string {
+	buf := make([]byte, length)
+	for i := length - 1; i >= 0; i-- {
+		buf[i] = alphabet[n%62]
+		n /= 62
+	}
+	return string(buf)
+}
+
+// Words builds readable codes such as "brave-otter-4217". The word pairs
+// alone give only 3136 combinations, so the digit suffix carries most of the
+// key space.
+type Words struct {
+	// Digits appended after the word pair; 0 disables the suffix.
+	Digits int
+}
+
+func (g Words) Generate(ctx context.Context) (string, error) {
+	adj, err := pick(adjectives)
+	if err != nil {
+		return "", err
+	}
+	noun, err := pick(nouns)
+	if err != nil {
+		return "", err
+	}
+	code := adj + "-" + noun
+	if g.Digits > 0 {
+		suffix, err := randomString("0123456789", g.Digits)
+		if err != nil {
+			return "", err
+		}
+		code += "-" + suffix
+	}
+	return code, nil
+}
+
+func pick(words []string) (string, error) {
+	idx, err := rand.Int(rand.Reader, big.NewInt(int64(len(words))))
+	if err != nil {
+		return "", fmt.Errorf("read random: %w", err)
+	}
+	return words[idx.Int64()], nil
+}
+
+// filtered regenerates codes the blocklist rejects.
+type filtered struct {
+	next      Generator
+	blocklist *Blocklist
+}
+
+// WithBlocklist wraps g so it never returns a code blocked by b.
+func WithBlocklist(g Generator, b *Blocklist) Generator {
+	if b == nil {
+		return g
+	}
+	return filtered{next: g, blocklist: b}
+}
+
+func (f filtered) Generate(ctx context.Context) (string, error) {
+	for i := 0; i < maxBlockedRetries; i++ {
+		code, err := f.next.Generate(ctx)
+		if err != nil {
+			return "", err
+		}
+		if !f.blocklist.Blocked(code) {
+			return code, nil
+		}
+	}
+	return "", ErrExhausted
+}
+
+// FromEnv builds the generator configured by SHORT_CODE_STRATEGY (random,
+// sequential or words; default random). SHORT_CODE_LENGTH sets the code
+// length for random and the minimum length for sequential, which also needs
+// SHORT_CODE_SALT. Word codes have no fixed length; SHORT_CODE_WORD_DIGITS
+// sets their digit suffix instead. next supplies sequence values. The result
+// always applies the default blocklist.
+func FromEnv(next func(ctx context.Context) (uint64, error)) (Generator, error) {
+	length := DefaultLength
+	if raw := os.Getenv("SHORT_CODE_LENGTH"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < MinLength || n > MaxLength {
+			return nil, fmt.Errorf("SHORT_CODE_LENGTH must be between %d and %d", MinLength, MaxLength)
+		}
+		length = n
+	}
+
+	var g Generator
+	switch strategy := strings.ToLower(os.Getenv("SHORT_CODE_STRATEGY")); strategy {
+	case "", StrategyRandom:
+		g = Random{Length: length}
+	case StrategySequential:
+		salt, err := strconv.ParseUint(os.Getenv("SHORT_CODE_SALT"), 10, 64)
+		if err != nil {
+			return nil, errors.New("SHORT_CODE_SALT must be set to an unsigned integer for the sequential strategy")
+		}
+		g = Sequential{Next: next, Salt: salt, MinLength: length}
+	case StrategyWords:
+		digits := DefaultWordDigits
+		if raw := os.Getenv("SHORT_CODE_WORD_DIGITS"); raw != "" {
+			n, err := strconv.Atoi(raw)
+			if err != nil || n < 0 || n > MaxWordDigits {
+				return nil, fmt.Errorf("SHORT_CODE_WORD_DIGITS must be between 0 and %d", MaxWordDigits)
+			}
+			digits = n
+		}
+		g = Words{Digits: digits}
+	default:
+		return nil, fmt.Errorf("unknown SHORT_CODE_STRATEGY %q", strategy)
+	}
+	return WithBlocklist(g, DefaultBlocklist()), nil
+}
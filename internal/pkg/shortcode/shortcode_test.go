@@ -0,0 +1,168 @@
+package shortcode
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestScrambleIsBijection(t *testing.T) {
+	// 31 and 62 exercise the adjustment that keeps a coprime with 62^k.
+	for _, salt := range []uint64{0, 1, 31, 62, 93, 12345, ^uint64(0)} {
+		for _, length := range []int{1, 2} {
+			space := pow62(length)
+			seen := make([]bool, space)
+			for n := uint64(0); n < space; n++ {
+				x := scramble(n, space, salt)
+				if x >= space {
+					t.Fatalf("salt %d: scramble(%d) = %d, outside [0, %d)", salt, n, x, space)
+				}
+				if seen[x] {
+					t.Fatalf("salt %d, length %d: %d produced twice", salt, length, x)
+				}
+				seen[x] = true
+			}
+		}
+	}
+}
+
+func TestSequentialGrowsWithoutCollisions(t *testing.T) {
+	var n uint64
+	g := Sequential{
+		Next:      func(ctx context.Context) (uint64, error) { v := n; n++; return v, nil },
+		Salt:      987654321,
+		MinLength: 2,
+	}
+	seen := make(map[string]bool)
+	// 62^2 two-character codes, then the first three-character ones.
+	for i := 0; i < 3844+100; i++ {
+		code, err := g.Generate(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		wantLen := 2
+		if i >= 3844 {
+			wantLen = 3
+		}
+		if len(code) != wantLen {
+			t.Fatalf("code %d = %q, want length %d", i, code, wantLen)
+		}
+		if seen[code] {
+			t.Fatalf("code %q issued twice", code)
+		}
+		seen[code] = true
+	}
+}
+
+func TestSequentialNextError(t *testing.T) {
+	g := Sequential{Next: func(ctx context.Context) (uint64, error) { return 0, errors.New("db down") }}
+	if _, err := g.Generate(context.Background()); err == nil {
+		t.Error("expected the sequence error")
+	}
+}
+
+func TestBlocked(t *testing.T) {
+	b := NewBlocklist([]string{"bad", "", " SEX "})
+	tests := []struct {
+		code string
+		want bool
+	}{
+		{"bad", true},
+		{"xBaDx", true},
+		{"b4d", true},
+		{"8ad", true},
+		{"b-a_d", true},
+		{"b.4.d", true},
+		{"5e%", false},
+		{"53x", true},
+		{"s3x9", true},
+		{"bat", false},
+		{"abc123", false},
+	}
+	for _, tt := range tests {
+		if got := b.Blocked(tt.code); got != tt.want {
+			t.Errorf("Blocked(%q) = %v, want %v", tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestDefaultBlocklistSkipsComments(t *testing.T) {
+	b := DefaultBlocklist()
+	for _, w := range b.words {
+		if strings.HasPrefix(w, "#") {
+			t.Fatalf("comment line loaded as word %q", w)
+		}
+	}
+	if !b.Blocked("xfuckx") {
+		t.Error("default blocklist missed a listed word")
+	}
+}
+
+type sequence struct {
+	codes []string
+	calls int
+}
+
+func (s *sequence) Generate(ctx context.Context) (string, error) {
+	code := s.codes[s.calls%len(s.codes)]
+	s.calls++
+	return code, nil
+}
+
+func TestWithBlocklistRetries(t *testing.T) {
+	b := NewBlocklist([]string{"bad"})
+
+	gen := &sequence{codes: []string{"bad1", "b4d2", "good"}}
+	code, err := WithBlocklist(gen, b).Generate(context.Background())
+	if err != nil || code != "good" {
+		t.Fatalf("got %q, %v; want good", code, err)
+	}
+	if gen.calls != 3 {
+		t.Errorf("generated %d codes, want 3", gen.calls)
+	}
+
+	gen = &sequence{codes: []string{"bad"}}
+	if _, err := WithBlocklist(gen, b).Generate(context.Background()); !errors.Is(err, ErrExhausted) {
+		t.Fatalf("err = %v, want ErrExhausted", err)
+	}
+	if gen.calls != maxBlockedRetries {
+		t.Errorf("generated %d codes, want %d", gen.calls, maxBlockedRetries)
+	}
+
+	if g := WithBlocklist(gen, nil); g != Generator(gen) {
+		t.Error("nil blocklist should return the generator unchanged")
+	}
+}
+
+func TestWordsDigits(t *testing.T) {
+	code, err := Words{Digits: 4}.Generate(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(code, "-")
+	if len(parts) != 3 || len(parts[2]) != 4 {
+		t.Errorf("code %q, want adjective-noun-dddd", code)
+	}
+	code, _ = Words{}.Generate(context.Background())
+	if strings.Count(code, "-") != 1 {
+		t.Errorf("code %q, want no digit suffix", code)
+	}
+}
+
+func TestFromEnvWordDigits(t *testing.T) {
+	t.Setenv("SHORT_CODE_STRATEGY", StrategyWords)
+	t.Setenv("SHORT_CODE_WORD_DIGITS", "")
+	g, err := FromEnv(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w := g.(filtered).next.(Words); w.Digits != DefaultWordDigits {
+		t.Errorf("digits = %d, want %d", w.Digits, DefaultWordDigits)
+	}
+
+	t.Setenv("SHORT_CODE_WORD_DIGITS", "10")
+	if _, err := FromEnv(nil); err == nil {
+		t.Error("expected an error for too many digits")
+	}
+}
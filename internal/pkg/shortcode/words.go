@@ -0,0 +1,23 @@
+package shortcode
+
+// Word lists for the words strategy. Keep entries short, lower-case and
+// inoffensive in combination.
+var adjectives = []string{
+	"amber", "bold", "brave", "bright", "calm", "clever", "cosmic", "crisp",
+	"daring", "eager", "early", "fancy", "fluffy", "gentle", "giant", "glad",
+	"golden", "happy", "hidden", "humble", "jolly", "keen", "kind", "lively",
+	"lucky", "mellow", "merry", "mighty", "misty", "noble", "polite", "proud",
+	"quick", "quiet", "rapid", "rosy", "royal", "rustic", "shiny", "silent",
+	"silver", "sleek", "smart", "snowy", "solid", "sunny", "swift", "tidy",
+	"tiny", "vivid", "warm", "wild", "wise", "witty", "young", "zesty",
+}
+
+var nouns = []string{
+	"acorn", "anchor", "badger", "beacon", "bison", "breeze", "brook", "cactus",
+	"canyon", "cedar", "comet", "coral", "crane", "delta", "dolphin", "falcon",
+	"fern", "forest", "fox", "garden", "glacier", "harbor", "hawk", "heron",
+	"island", "lagoon", "lantern", "lemon", "lynx", "maple", "meadow", "meteor",
+	"moose", "orbit", "otter", "owl", "panda", "pebble", "pine", "planet",
+	"prairie", "quartz", "raven", "reef", "river", "robin", "sparrow", "summit",
+	"tiger", "tulip", "valley", "walrus", "willow", "wolf", "zebra", "zephyr",
+}
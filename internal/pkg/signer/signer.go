@@ -0,0 +1,71 @@
+// Package signer produces and checks HMAC signatures for values handed to
+// visitors, such as access cookies.
+package signer
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"redo.ai/logger"
+)
+
+type Signer struct {
+	key []byte
+}
+
+func New(key []byte) *Signer {
+	return &Signer{key: key}
+}
+
+// FromEnv uses the key in the environment variable name. Without one it
+// falls back to a random key, so signatures do not survive a restart or
+// work across instances.
+func FromEnv(name string) *Signer {
+	if key := os.Getenv(name); key != "" {
+		return New([]byte(key))
+	}
+	logger.Warn("%s is not set; using a random signing key for this process", name)
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		logger.Fatal("signer: failed to generate key: %v", err)
+	}
+	return New(key)
+}
+
+// Sign returns the base64url HMAC-SHA256 of payload.
+func (s *Signer) Sign(payload string) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether sig is the signature of payload.
+func (s *Signer) Verify(payload, sig string) bool {
+	return hmac.Equal([]byte(s.Sign(payload)), []byte(sig))
+}
+
+// SignExpiring returns a token "<unix expiry>.<signature>" that binds payload
+// to an expiry time. The payload itself is not included.
+func (s *Signer) SignExpiring(payload string, expires time.Time) string {
+	exp := strconv.FormatInt(expires.Unix(), 10)
+	return exp + "." + s.Sign(exp+"|"+payload)
+}
+
+// VerifyExpiring checks a token from SignExpiring against payload and now.
+func (s *Signer) VerifyExpiring(payload, token string, now time.Time) bool {
+	exp, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return false
+	}
+	unix, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil || now.Unix() >= unix {
+		return false
+	}
+	return s.Verify(exp+"|"+payload, sig)
+}
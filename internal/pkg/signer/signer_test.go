@@ -0,0 +1,61 @@
+package signer
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignVerify(t *testing.T) {
+	s := New([]byte("secret"))
+	sig := s.Sign("payload")
+	if !s.Verify("payload", sig) {
+		t.Fatal("signature did not verify")
+	}
+	if s.Verify("payload2", sig) {
+		t.Error("signature verified for another payload")
+	}
+	if s.Verify("payload", sig[:len(sig)-1]+flip(sig[len(sig)-1])) {
+		t.Error("tampered signature verified")
+	}
+	if New([]byte("other")).Verify("payload", sig) {
+		t.Error("signature verified under another key")
+	}
+}
+
+func TestSignExpiring(t *testing.T) {
+	s := New([]byte("secret"))
+	now := time.Unix(1700000000, 0)
+	token := s.SignExpiring("link-1", now.Add(time.Hour))
+
+	if !s.VerifyExpiring("link-1", token, now) {
+		t.Fatal("token did not verify")
+	}
+	if s.VerifyExpiring("link-2", token, now) {
+		t.Error("token verified for another payload")
+	}
+	if s.VerifyExpiring("link-1", token, now.Add(time.Hour)) {
+		t.Error("token verified at its expiry")
+	}
+
+	exp, sig, _ := strings.Cut(token, ".")
+	tests := map[string]string{
+		"extended expiry": "1800000000." + sig,
+		"bad signature":   exp + "." + sig[:len(sig)-1] + flip(sig[len(sig)-1]),
+		"no separator":    exp + sig,
+		"bad expiry":      "soon." + sig,
+		"empty":           "",
+	}
+	for name, tok := range tests {
+		if s.VerifyExpiring("link-1", tok, now) {
+			t.Errorf("%s: token verified", name)
+		}
+	}
+}
+
+func flip(c byte) string {
+	if c == 'A' {
+		return "B"
+	}
+	return "A"
+}
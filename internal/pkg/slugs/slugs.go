@@ -0,0 +1,73 @@
+// Package slugs holds the rules for user-chosen vanity slugs beyond their
+// character set: reserved words and alternative suggestions.
+package slugs
+
+import (
+	_ "embed"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+//go:embed reserved.txt
+var reservedList string
+
+var (
+	loadOnce  sync.Once
+	exact     map[string]bool
+	contained []string
+)
+
+func load() {
+	exact = map[string]bool{}
+	for _, line := range strings.Split(reservedList, "\n") {
+		line = strings.ToLower(strings.TrimSpace(line))
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+		case strings.HasPrefix(line, "*") && strings.HasSuffix(line, "*") && len(line) > 2:
+			contained = append(contained, strings.Trim(line, "*"))
+		default:
+			exact[line] = true
+		}
+	}
+}
+
+// IsReserved reports whether slug collides with a route or system name, or
+// contains a brand or account term commonly abused for phishing. Matching
+// ignores case.
+func IsReserved(slug string) bool {
+	loadOnce.Do(load)
+	s := strings.ToLower(slug)
+	if exact[s] {
+		return true
+	}
+	for _, c := range contained {
+		if strings.Contains(s, c) {
+			return true
+		}
+	}
+	return false
+}
+
+// Candidates returns alternatives to a taken slug, most natural first. They
+// all use the slug character set; callers still check them for
+// availability and policy.
+func Candidates(slug string, now time.Time) []string {
+	base := strings.Trim(slug, "-_")
+	if base == "" {
+		return nil
+	}
+	year := strconv.Itoa(now.Year())
+	out := []string{
+		base + "-" + year,
+		"my-" + base,
+		"get-" + base,
+		base + "-link",
+		base + "-hq",
+	}
+	for i := 2; i <= 9; i++ {
+		out = append(out, base+"-"+strconv.Itoa(i))
+	}
+	return out
+}
@@ -0,0 +1,174 @@
+// Package throttle counts failed attempts per key and locks a key out once
+// it fails too often, or makes it wait longer after each failure, e.g. to
+// slow down password guessing.
+package throttle
+
+import (
+	"sync"
+	"time"
+)
+
+// sweepEvery is how many calls pass between removals of expired entries.
+const sweepEvery = 1024
+
+// Limiter allows Max failures per key within Window, counted from the first
+// failure. It is in-memory, so limits apply per process.
+type Limiter struct {
+	Max    int
+	Window time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*entry
+	calls   int
+}
+
+type entry struct {
+	failures int
+	start    time.Time
+}
+
+func New(max int, window time.Duration) *Limiter {
+	return &Limiter{Max: max, Window: window, entries: map[string]*entry{}}
+}
+
+// Blocked reports whether key is locked out and for how much longer.
+func (l *Limiter) Blocked(key string, now time.Time) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sweep(now)
+	e, ok := l.entries[key]
+	if !ok || now.Sub(e.start) >= l.Window {
+		return 0, false
+	}
+	if e.failures < l.Max {
+		return 0, false
+	}
+	return e.start.Add(l.Window).Sub(now), true
+}
+
+// Fail records a failed attempt for key.
+func (l *Limiter) Fail(key string, now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sweep(now)
+	e, ok := l.entries[key]
+	if !ok || now.Sub(e.start) >= l.Window {
+		l.entries[key] = &entry{failures: 1, start: now}
+		return
+	}
+	e.failures++
+}
+
+// Reset forgets key's failures, e.g. after a successful attempt.
+func (l *Limiter) Reset(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.entries, key)
+}
+
+// sweep drops expired entries now and then; l.mu must be held.
+func (l *Limiter) sweep(now time.Time) {
+	l.calls++
+	if l.calls%sweepEvery != 0 {
+		return
+	}
+	for k, e := range l.entries {
+		if now.Sub(e.start) >= l.Window {
+			delete(l.entries, k)
+		}
+	}
+}
+
+// Backoff makes a key wait before its next attempt once it has failed more
+// than Free times. The wait starts at Base and doubles with each further
+// failure, up to Max. A key's failures are forgotten after Window without
+// one. Unlike Limiter it never locks a key out for the whole window, so
+// slowing an attacker down does not shut out everyone sharing the key.
+type Backoff struct {
+	Free   int
+	Base   time.Duration
+	Max    time.Duration
+	Window time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*backoffEntry
+	calls   int
+}
+
+type backoffEntry struct {
+	failures int
+	last     time.Time
+}
+
+func NewBackoff(free int, base, max, window time.Duration) *Backoff {
+	return &Backoff{Free: free, Base: base, Max: max, Window: window, entries: map[string]*backoffEntry{}}
+}
+
+// Wait reports how much longer key has to wait before its next attempt.
+func (b *Backoff) Wait(key string, now time.Time) (time.Duration, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.sweep(now)
+	e, ok := b.entries[key]
+	if !ok || now.Sub(e.last) >= b.Window {
+		return 0, false
+	}
+	wait := e.last.Add(b.delay(e.failures)).Sub(now)
+	if wait <= 0 {
+		return 0, false
+	}
+	return wait, true
+}
+
+// Fail records a failed attempt for key.
+func (b *Backoff) Fail(key string, now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.sweep(now)
+	e, ok := b.entries[key]
+	if !ok || now.Sub(e.last) >= b.Window {
+		e = &backoffEntry{}
+		b.entries[key] = e
+	}
+	e.failures++
+	e.last = now
+}
+
+// Reset forgets key's failures.
+func (b *Backoff) Reset(key string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.entries, key)
+}
+
+// delay is the wait after the given number of failures.
+func (b *Backoff) delay(failures int) time.Duration {
+	n := failures - b.Free
+	if n <= 0 {
+		return 0
+	}
+	d := b.Base
+	for i := 1; i < n; i++ {
+		if d >= b.Max {
+			break
+		}
+		d *= 2
+	}
+	if d > b.Max {
+		d = b.Max
+	}
+	return d
+}
+
+// sweep drops expired entries now and then; b.mu must be held.
+func (b *Backoff) sweep(now time.Time) {
+	b.calls++
+	if b.calls%sweepEvery != 0 {
+		return
+	}
+	for k, e := range b.entries {
+		if now.Sub(e.last) >= b.Window {
+			delete(b.entries, k)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package throttle
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLimiter(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	l := New(3, 10*time.Minute)
+
+	for i := 0; i < 3; i++ {
+		if _, blocked := l.Blocked("k", start); blocked {
+			t.Fatalf("blocked after %d failures", i)
+		}
+		l.Fail("k", start.Add(time.Duration(i)*time.Minute))
+	}
+	wait, blocked := l.Blocked("k", start.Add(4*time.Minute))
+	if !blocked || wait != 6*time.Minute {
+		t.Fatalf("Blocked = %v, %v; want 6m, true", wait, blocked)
+	}
+	if _, blocked := l.Blocked("other", start.Add(4*time.Minute)); blocked {
+		t.Error("failures leaked to another key")
+	}
+
+	// The window counts from the first failure.
+	if _, blocked := l.Blocked("k", start.Add(10*time.Minute)); blocked {
+		t.Error("still blocked after the window")
+	}
+	l.Fail("k", start.Add(10*time.Minute))
+	if _, blocked := l.Blocked("k", start.Add(10*time.Minute)); blocked {
+		t.Error("a failure after the window should start a new count")
+	}
+}
+
+func TestLimiterReset(t *testing.T) {
+	now := time.Now()
+	l := New(1, time.Hour)
+	l.Fail("k", now)
+	if _, blocked := l.Blocked("k", now); !blocked {
+		t.Fatal("not blocked after reaching Max")
+	}
+	l.Reset("k")
+	if _, blocked := l.Blocked("k", now); blocked {
+		t.Error("still blocked after Reset")
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	b := NewBackoff(2, time.Second, 5*time.Second, time.Hour)
+
+	now := start
+	want := []time.Duration{0, 0, time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, w := range want {
+		b.Fail("k", now)
+		wait, blocked := b.Wait("k", now)
+		if wait != w || blocked != (w > 0) {
+			t.Fatalf("after %d failures: Wait = %v, %v; want %v", i+1, wait, blocked, w)
+		}
+		now = now.Add(wait)
+		if _, blocked := b.Wait("k", now); blocked {
+			t.Fatalf("after %d failures: still waiting once the delay passed", i+1)
+		}
+	}
+	if _, blocked := b.Wait("other", now); blocked {
+		t.Error("failures leaked to another key")
+	}
+
+	// Failures are forgotten after a quiet window.
+	b.Fail("k", now)
+	later := now.Add(time.Hour)
+	b.Fail("k", later)
+	if _, blocked := b.Wait("k", later); blocked {
+		t.Error("failures before the window still count")
+	}
+
+	b.Fail("k", later)
+	b.Fail("k", later)
+	if _, blocked := b.Wait("k", later); !blocked {
+		t.Fatal("expected a delay")
+	}
+	b.Reset("k")
+	if _, blocked := b.Wait("k", later); blocked {
+		t.Error("still waiting after Reset")
+	}
+}
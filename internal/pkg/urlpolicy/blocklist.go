@@ -0,0 +1,117 @@
+package urlpolicy
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Blocklist holds blocked domains loaded from a file, one per line with #
+// comments. A domain also blocks its subdomains. Reload picks up edits
+// without a restart.
+type Blocklist struct {
+	path string
+
+	mu      sync.RWMutex
+	domains map[string]bool
+	modTime time.Time
+}
+
+// LoadBlocklist reads the blocklist at path.
+func LoadBlocklist(path string) (*Blocklist, error) {
+	b := &Blocklist{path: path}
+	if _, err := b.Reload(context.Background()); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// NewBlocklist returns an in-memory blocklist that never reloads.
+func NewBlocklist(domains ...string) *Blocklist {
+	b := &Blocklist{domains: map[string]bool{}}
+	for _, d := range domains {
+		if d = normalizeDomain(d); d != "" {
+			b.domains[d] = true
+		}
+	}
+	return b
+}
+
+// Reload re-reads the file when its modification time changed and reports
+// whether it did. A failed reload keeps the previous list.
+func (b *Blocklist) Reload(ctx context.Context) (bool, error) {
+	if b.path == "" {
+		return false, nil
+	}
+	info, err := os.Stat(b.path)
+	if err != nil {
+		return false, fmt.Errorf("stat blocklist: %w", err)
+	}
+	b.mu.RLock()
+	unchanged := b.domains != nil && info.ModTime().Equal(b.modTime)
+	b.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	f, err := os.Open(b.path)
+	if err != nil {
+		return false, fmt.Errorf("open blocklist: %w", err)
+	}
+	defer f.Close()
+	domains := map[string]bool{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		if d := normalizeDomain(line); d != "" {
+			domains[d] = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return false, fmt.Errorf("read blocklist: %w", err)
+	}
+
+	b.mu.Lock()
+	b.domains = domains
+	b.modTime = info.ModTime()
+	b.mu.Unlock()
+	return true, nil
+}
+
+// Len returns the number of blocked domains.
+func (b *Blocklist) Len() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.domains)
+}
+
+// Blocked reports whether host or any parent domain is listed.
+func (b *Blocklist) Blocked(host string) bool {
+	host = normalizeDomain(host)
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for host != "" {
+		if b.domains[host] {
+			return true
+		}
+		i := strings.IndexByte(host, '.')
+		if i < 0 {
+			break
+		}
+		host = host[i+1:]
+	}
+	return false
+}
+
+func normalizeDomain(d string) string {
+	d = strings.ToLower(strings.TrimSpace(d))
+	d = strings.TrimPrefix(d, "*.")
+	return strings.TrimSuffix(d, ".")
+}
@@ -0,0 +1,44 @@
+package urlpolicy
+
+import (
+	"context"
+	"net"
+	"sync"
+)
+
+// ForBatch returns a copy of p that resolves each host at most once, for
+// screening many destinations together. Safe for concurrent use; discard it
+// when the batch is done so lookups do not go stale.
+func (p *Policy) ForBatch() *Policy {
+	batch := *p
+	if p.Resolver != nil {
+		batch.Resolver = &hostCache{next: p.Resolver, entries: make(map[string]*hostEntry)}
+	}
+	return &batch
+}
+
+// hostCache memoizes LookupIPAddr, including failures. Concurrent lookups
+// of the same host wait for the first one.
+type hostCache struct {
+	next    Resolver
+	mu      sync.Mutex
+	entries map[string]*hostEntry
+}
+
+type hostEntry struct {
+	once  sync.Once
+	addrs []net.IPAddr
+	err   error
+}
+
+func (c *hostCache) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	c.mu.Lock()
+	e, ok := c.entries[host]
+	if !ok {
+		e = &hostEntry{}
+		c.entries[host] = e
+	}
+	c.mu.Unlock()
+	e.once.Do(func() { e.addrs, e.err = c.next.LookupIPAddr(ctx, host) })
+	return e.addrs, e.err
+}
@@ -0,0 +1,45 @@
+package urlpolicy
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// PublicClient returns an http.Client that refuses to connect to private,
+// loopback or link-local addresses, for fetching user-supplied URLs. The
+// check runs on the resolved address of every connection, redirects
+// included, so DNS cannot be used to reach internal services.
+func PublicClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || isPrivate(ip) {
+				return fmt.Errorf("urlpolicy: refusing to connect to %s", host)
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= DefaultMaxHops {
+				return fmt.Errorf("urlpolicy: stopped after %d redirects", len(via))
+			}
+			if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+				return fmt.Errorf("urlpolicy: refusing redirect to %s", req.URL.Scheme)
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,227 @@
+// Package urlpolicy screens link destinations before they are stored.
+package urlpolicy
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"redo.ai/logger"
+)
+
+// Rules a destination can break.
+const (
+	RuleScheme         = "scheme"
+	RulePrivateAddress = "private_address"
+	RuleBlocklist      = "blocklist"
+	RuleRedirectChain  = "redirect_chain"
+	RuleThreatIntel    = "threat_intel"
+)
+
+// DefaultMaxHops bounds how many shortener redirects are followed.
+const DefaultMaxHops = 5
+
+// Violation explains why a destination was rejected. URL is the hop that
+// broke the rule, which differs from the submitted URL when a shortener
+// redirect led there.
+type Violation struct {
+	Rule   string
+	Reason string
+	URL    string
+}
+
+func (v *Violation) Error() string {
+	return fmt.Sprintf("destination rejected (%s): %s", v.Rule, v.Reason)
+}
+
+// Resolver looks up host addresses; *net.Resolver satisfies it.
+type Resolver interface {
+	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+}
+
+// Doer sends HTTP requests; *http.Client satisfies it. It must not follow
+// redirects itself.
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Policy is the destination policy engine. Zero-valued fields switch their
+// check off, except AllowedSchemes and MaxHops which fall back to defaults.
+type Policy struct {
+	// AllowedSchemes defaults to http and https.
+	AllowedSchemes []string
+	// Resolver, when set, blocks hostnames that resolve to private,
+	// loopback or link-local addresses. Literal IPs are always checked.
+	Resolver  Resolver
+	Blocklist *Blocklist
+	// Client, when set, unwinds links from other shorteners and screens
+	// every hop of the redirect chain.
+	Client     Doer
+	Shorteners map[string]bool
+	MaxHops    int
+	// ThreatIntel, when set, is consulted for the final destination.
+	ThreatIntel ThreatIntel
+}
+
+// NoRedirectClient returns an http.Client suitable for Policy.Client.
+func NoRedirectClient(c *http.Client) *http.Client {
+	c.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+	return c
+}
+
+// Check returns a *Violation when rawURL may not be used as a destination,
+// or another error when a check could not run.
+func (p *Policy) Check(ctx context.Context, rawURL string) error {
+	current := rawURL
+	maxHops := p.MaxHops
+	if maxHops <= 0 {
+		maxHops = DefaultMaxHops
+	}
+	shorteners := p.Shorteners
+	if shorteners == nil {
+		shorteners = DefaultShorteners
+	}
+
+	for hop := 0; ; hop++ {
+		u, err := p.checkStatic(ctx, current)
+		if err != nil {
+			return err
+		}
+		if p.Client == nil || !shorteners[strings.ToLower(u.Hostname())] {
+			break
+		}
+		if hop == maxHops {
+			return &Violation{Rule: RuleRedirectChain, Reason: fmt.Sprintf("more than %d shortener redirects", maxHops), URL: current}
+		}
+		next, err := p.unwind(ctx, u)
+		if err != nil {
+			logger.Warn("urlpolicy: could not unwind %s: %v", current, err)
+			return &Violation{Rule: RuleRedirectChain, Reason: "shortened link could not be resolved", URL: current}
+		}
+		if next == "" {
+			break
+		}
+		current = next
+	}
+
+	if p.ThreatIntel != nil {
+		verdict, err := p.ThreatIntel.Lookup(ctx, current)
+		if err != nil {
+			return fmt.Errorf("threat intel lookup failed: %w", err)
+		}
+		if verdict.Malicious {
+			return &Violation{Rule: RuleThreatIntel, Reason: "flagged as " + verdict.Category, URL: current}
+		}
+	}
+	return nil
+}
+
+// checkStatic runs the checks that need no HTTP request.
+func (p *Policy) checkStatic(ctx context.Context, rawURL string) (*url.URL, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return nil, &Violation{Rule: RuleScheme, Reason: "not an absolute URL", URL: rawURL}
+	}
+	if !p.schemeAllowed(u.Scheme) {
+		return nil, &Violation{Rule: RuleScheme, Reason: fmt.Sprintf("scheme %q is not allowed", u.Scheme), URL: rawURL}
+	}
+	if u.User != nil {
+		return nil, &Violation{Rule: RuleScheme, Reason: "credentials in URLs are not allowed", URL: rawURL}
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if isInternalName(host) {
+		return nil, &Violation{Rule: RulePrivateAddress, Reason: "internal host names are not allowed", URL: rawURL}
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		if isPrivate(ip) {
+			return nil, &Violation{Rule: RulePrivateAddress, Reason: "private or loopback address", URL: rawURL}
+		}
+	} else if p.Resolver != nil {
+		addrs, err := p.Resolver.LookupIPAddr(ctx, host)
+		if err != nil {
+			// Unresolvable hosts cannot reach our network; let them through.
+			logger.Warn("urlpolicy: lookup %s failed: %v", host, err)
+		}
+		for _, a := range addrs {
+			if isPrivate(a.IP) {
+				return nil, &Violation{Rule: RulePrivateAddress, Reason: "host resolves to a private or loopback address", URL: rawURL}
+			}
+		}
+	}
+
+	if p.Blocklist != nil && p.Blocklist.Blocked(host) {
+		return nil, &Violation{Rule: RuleBlocklist, Reason: "domain is blocklisted", URL: rawURL}
+	}
+	return u, nil
+}
+
+func (p *Policy) schemeAllowed(scheme string) bool {
+	allowed := p.AllowedSchemes
+	if len(allowed) == 0 {
+		allowed = []string{"http", "https"}
+	}
+	for _, s := range allowed {
+		if strings.EqualFold(s, scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+// unwind asks a shortener where u points. It returns "" when u does not
+// redirect.
+func (p *Policy) unwind(ctx context.Context, u *url.URL) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, u.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 300 || resp.StatusCode > 399 {
+		return "", nil
+	}
+	loc, err := resp.Location()
+	if err != nil {
+		return "", fmt.Errorf("redirect without location: %w", err)
+	}
+	return loc.String(), nil
+}
+
+var cgnat = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+func isPrivate(ip net.IP) bool {
+	return ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsUnspecified() || ip.IsMulticast() || cgnat.Contains(ip)
+}
+
+func isInternalName(host string) bool {
+	return host == "localhost" || strings.HasSuffix(host, ".localhost") ||
+		strings.HasSuffix(host, ".local") || strings.HasSuffix(host, ".internal")
+}
+
+// DefaultShorteners are hosts whose links are unwound before screening.
+var DefaultShorteners = map[string]bool{
+	"bit.ly":      true,
+	"bitly.com":   true,
+	"buff.ly":     true,
+	"cutt.ly":     true,
+	"goo.gl":      true,
+	"is.gd":       true,
+	"ow.ly":       true,
+	"rb.gy":       true,
+	"rebrand.ly":  true,
+	"shorturl.at": true,
+	"t.co":        true,
+	"t.ly":        true,
+	"tiny.cc":     true,
+	"tinyurl.com": true,
+	"v.gd":        true,
+}
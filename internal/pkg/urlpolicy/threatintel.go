@@ -0,0 +1,47 @@
+package urlpolicy
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// ThreatVerdict is a threat-intel provider's opinion of a URL.
+type ThreatVerdict struct {
+	Malicious bool
+	// Category names the threat, such as "phishing" or "malware".
+	Category string
+}
+
+// ThreatIntel looks URLs up in a reputation service such as Google Safe
+// Browsing. Implementations should treat unknown URLs as not malicious and
+// reserve errors for failed lookups.
+type ThreatIntel interface {
+	Lookup(ctx context.Context, rawURL string) (ThreatVerdict, error)
+}
+
+// FakeThreatIntel is an in-memory ThreatIntel for tests and local
+// development. It flags URLs containing any key of Flagged with that key's
+// category and records every lookup.
+type FakeThreatIntel struct {
+	Flagged map[string]string
+	Err     error
+
+	mu      sync.Mutex
+	Lookups []string
+}
+
+func (f *FakeThreatIntel) Lookup(ctx context.Context, rawURL string) (ThreatVerdict, error) {
+	f.mu.Lock()
+	f.Lookups = append(f.Lookups, rawURL)
+	f.mu.Unlock()
+	if f.Err != nil {
+		return ThreatVerdict{}, f.Err
+	}
+	for needle, category := range f.Flagged {
+		if strings.Contains(rawURL, needle) {
+			return ThreatVerdict{Malicious: true, Category: category}, nil
+		}
+	}
+	return ThreatVerdict{}, nil
+}
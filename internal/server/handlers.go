@@ -3,20 +3,56 @@ package server
 import (
 	"encoding/json"
 	"net/http"
+	"os"
 
 	"redo.ai/internal/api/handlers"
+	"redo.ai/internal/pkg/platform"
 )
 
 type HandlerContainer struct {
-	AuthHandler *handlers.AuthHandler
-	LinkHandler *handlers.LinkHandler
+	AuthHandler     *handlers.AuthHandler
+	LinkHandler     *handlers.LinkHandler
+	ClickHandler    *handlers.ClickHandler
+	UsageHandler    *handlers.UsageHandler
+	UserHandler     *handlers.UserHandler
+	AdminHandler    *handlers.AdminHandler
+	AuditHandler    *handlers.AuditHandler
+	TagHandler      *handlers.TagHandler
+	CampaignHandler *handlers.CampaignHandler
+	ExportHandler   *handlers.ExportHandler
+	AppHandler      *handlers.AppHandler
+	BioHandler      *handlers.BioHandler
+
+	ConversionHandler *handlers.ConversionHandler
 	//MetricsHandler *handlers.MetricsHandler
 }
 
 func NewHandlerContainer(srv *Server) *HandlerContainer {
+	authHandler := handlers.NewAuthHandler(srv.UserSvc, srv.cache)
+	linkHandler := handlers.NewLinkHandler(srv.UserSvc, srv.LinkSvc, srv.cache)
+	linkHandler.Audit = srv.AuditSvc
+	linkHandler.Bulk = srv.BulkSvc
+	linkHandler.Platform = &platform.DefaultPlatformDetector{Registry: srv.DeepLinks}
+	linkHandler.AppLinks = srv.AppLinkSvc
+	linkHandler.AccessSigner = srv.Signer
+	linkHandler.ClickSigner = srv.Signer
+	linkHandler.ShortURLBase = srv.ShortURLBase
+	linkHandler.CountryHeader = os.Getenv("GEO_COUNTRY_HEADER")
 	return &HandlerContainer{
-		AuthHandler: handlers.NewAuthHandler(srv.UserSvc, srv.cache),
-		LinkHandler: handlers.NewLinkHandler(srv.UserSvc, srv.LinkSvc, srv.cache),
+		AuthHandler:     authHandler,
+		LinkHandler:     linkHandler,
+		ClickHandler:    handlers.NewClickHandler(srv.ClickSvc, srv.UserSvc),
+		UsageHandler:    handlers.NewUsageHandler(srv.UsageSvc, srv.UserSvc),
+		UserHandler:     handlers.NewUserHandler(authHandler, srv.UserSvc, srv.LinkSvc, srv.ClickSvc, srv.cache),
+		AdminHandler:    handlers.NewAdminHandler(srv.AdminSvc, srv.UserSvc, srv.LinkSvc, srv.AuditSvc, srv.cache),
+		AuditHandler:    handlers.NewAuditHandler(srv.AuditSvc, srv.UserSvc),
+		TagHandler:      handlers.NewTagHandler(srv.TagSvc, srv.UserSvc),
+		CampaignHandler: handlers.NewCampaignHandler(srv.CampaignSvc, srv.UserSvc),
+		ExportHandler:   handlers.NewExportHandler(srv.LinkSvc, srv.ClickSvc, srv.UserSvc),
+		AppHandler:      handlers.NewAppHandler(srv.AppLinkSvc, srv.UserSvc, srv.AuditSvc),
+		BioHandler:      handlers.NewBioHandler(srv.BioSvc, srv.UserSvc, srv.AuditSvc),
+
+		ConversionHandler: handlers.NewConversionHandler(srv.ClickSvc, srv.UserSvc, srv.Signer),
 	}
 }
 
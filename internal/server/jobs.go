@@ -0,0 +1,54 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"redo.ai/logger"
+)
+
+// startJobs launches the periodic maintenance goroutines. They stop when ctx
+// is cancelled.
+func (s *Server) startJobs(ctx context.Context) {
+	go s.every(ctx, time.Hour, "purge deleted accounts", func(ctx context.Context) error {
+		n, err := s.UserSvc.PurgeDeletedUsers(ctx)
+		if n > 0 {
+			logger.Info("purged %d deleted accounts", n)
+		}
+		return err
+	})
+	if s.URLPolicy != nil && s.URLPolicy.Blocklist != nil {
+		bl := s.URLPolicy.Blocklist
+		go s.every(ctx, 30*time.Second, "reload destination blocklist", func(ctx context.Context) error {
+			reloaded, err := bl.Reload(ctx)
+			if reloaded {
+				logger.Info("reloaded destination blocklist: %d domains", bl.Len())
+			}
+			return err
+		})
+	}
+	go s.every(ctx, 30*time.Second, "apply link schedules", func(ctx context.Context) error {
+		n, err := s.LinkSvc.ApplyDueSchedules(ctx, time.Now().UTC())
+		if n > 0 {
+			logger.Info("applied %d scheduled destination changes", n)
+		}
+		return err
+	})
+	go s.BulkSvc.Work(ctx, s.HC.LinkHandler.RunBulkJob)
+}
+
+// every runs fn immediately and then on each tick of interval.
+func (s *Server) every(ctx context.Context, interval time.Duration, name string, fn func(context.Context) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := fn(ctx); err != nil {
+			logger.Error("job %q failed: %v", name, err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	lru "github.com/hashicorp/golang-lru"
 	"redo.ai/internal/api/handlers"
@@ -29,6 +30,16 @@ func (m *mockLinkService) DeleteLink(ctx context.Context, userID string, linkID
 	panic("unimplemented")
 }
 
+// GetLink implements link.LinkService.
+func (m *mockLinkService) GetLink(ctx context.Context, userID string, linkID string) (model.Link, error) {
+	panic("unimplemented")
+}
+
+// UpdateLink implements link.LinkService.
+func (m *mockLinkService) UpdateLink(ctx context.Context, userID string, linkID string, req model.UpdateLinkRequest) (model.Link, error) {
+	panic("unimplemented")
+}
+
 // ResolveUserSlug implements link.LinkService.
 func (m *mockLinkService) ResolveUserSlug(ctx context.Context, userID string, slug string) (model.Link, error) {
 	panic("unimplemented")
@@ -41,6 +52,36 @@ func (m *mockUserService) GetByID(ctx context.Context, auth0Sub string) (*model.
 	panic("unimplemented")
 }
 
+// GetByUserID implements user.UserService.
+func (m *mockUserService) GetByUserID(ctx context.Context, userID string) (*model.User, error) {
+	panic("unimplemented")
+}
+
+// UpdateProfile implements user.UserService.
+func (m *mockUserService) UpdateProfile(ctx context.Context, userID string, update model.ProfileUpdate) (*model.User, error) {
+	panic("unimplemented")
+}
+
+// ChangeEmail implements user.UserService.
+func (m *mockUserService) ChangeEmail(ctx context.Context, userID string, email string) (*model.User, error) {
+	panic("unimplemented")
+}
+
+// ScheduleDeletion implements user.UserService.
+func (m *mockUserService) ScheduleDeletion(ctx context.Context, userID string) (*model.User, error) {
+	panic("unimplemented")
+}
+
+// CancelDeletion implements user.UserService.
+func (m *mockUserService) CancelDeletion(ctx context.Context, userID string) (*model.User, error) {
+	panic("unimplemented")
+}
+
+// PurgeDeletedUsers implements user.UserService.
+func (m *mockUserService) PurgeDeletedUsers(ctx context.Context) (int64, error) {
+	panic("unimplemented")
+}
+
 // SignUp implements user.UserService.
 func (m *mockUserService) SignUp(context.Context, string, string) (*model.User, error) {
 	panic("unimplemented")
@@ -55,14 +96,54 @@ func (m *mockLinkService) CreateLink(ctx context.Context, userID string, req mod
 	return model.Link{}, nil // Always succeed
 }
 
-func (m *mockLinkService) ResolveLink(ctx context.Context, slug string) (string, string, error) {
-	return "https://example.com", "", nil // Return a fake URL
+func (m *mockLinkService) ResolveLink(ctx context.Context, slug string) (model.ResolvedLink, error) {
+	return model.ResolvedLink{ShortCode: slug, Destination: "https://example.com"}, nil
+}
+
+func (m *mockLinkService) CheckLinkPassword(ctx context.Context, linkID, password string) error {
+	return nil
 }
 
-func (m *mockLinkService) TrackClick(ctx context.Context, slug, ip, referrer, userAgent string) error {
+func (m *mockLinkService) TrackClick(ctx context.Context, ev model.ClickEvent) error {
 	return nil // Do nothing
 }
 
+func (m *mockLinkService) ListVariants(ctx context.Context, userID, linkID string) ([]model.LinkVariant, error) {
+	return nil, nil
+}
+
+func (m *mockLinkService) SetVariants(ctx context.Context, userID, linkID string, inputs []model.LinkVariantInput) ([]model.LinkVariant, error) {
+	return nil, nil
+}
+
+func (m *mockLinkService) GetRules(ctx context.Context, userID, linkID string) ([]model.RoutingRule, error) {
+	return nil, nil
+}
+
+func (m *mockLinkService) SetRules(ctx context.Context, userID, linkID string, rules []model.RoutingRule) ([]model.RoutingRule, error) {
+	return nil, nil
+}
+
+func (m *mockLinkService) ListSchedules(ctx context.Context, userID, linkID string) ([]model.LinkSchedule, error) {
+	return nil, nil
+}
+
+func (m *mockLinkService) CreateSchedule(ctx context.Context, userID, linkID string, req model.CreateScheduleRequest) (model.LinkSchedule, error) {
+	return model.LinkSchedule{}, nil
+}
+
+func (m *mockLinkService) CancelSchedule(ctx context.Context, userID, linkID, scheduleID string) (model.LinkSchedule, error) {
+	return model.LinkSchedule{}, nil
+}
+
+func (m *mockLinkService) ApplyDueSchedules(ctx context.Context, now time.Time) (int, error) {
+	return 0, nil
+}
+
+func (m *mockLinkService) VariantStats(ctx context.Context, userID, linkID string, since time.Time) ([]model.VariantStats, error) {
+	return nil, nil
+}
+
 func (m *mockLinkService) GetClickCount(ctx context.Context, slug string) (int, error) {
 	return 42, nil // Return dummy click count
 }
@@ -71,6 +152,38 @@ func (m *mockLinkService) ListLinks(ctx context.Context, userID string) ([]model
 	return []model.Link{}, nil
 }
 
+func (m *mockLinkService) ImportLinks(ctx context.Context, userID, format string, items []model.ImportItem, dryRun bool) (model.ImportReport, error) {
+	return model.ImportReport{}, nil
+}
+
+func (m *mockLinkService) CheckSlugAvailability(ctx context.Context, userID, slug string) (model.SlugAvailability, error) {
+	return model.SlugAvailability{Slug: slug, Available: true, Suggestions: []string{}}, nil
+}
+
+func (m *mockLinkService) StreamLinks(ctx context.Context, userID string, fn func(model.Link) error) error {
+	return nil
+}
+
+func (m *mockLinkService) QueryLinks(ctx context.Context, userID string, q model.LinkQuery) (model.LinkPage, error) {
+	return model.LinkPage{Links: []model.Link{}}, nil
+}
+
+func (m *mockLinkService) SearchLinks(ctx context.Context, userID, term string, limit int) ([]model.LinkSearchResult, error) {
+	return []model.LinkSearchResult{}, nil
+}
+
+func (m *mockLinkService) BulkCreateLinks(ctx context.Context, userID string, items []model.BulkCreateItem, atomic bool) (model.BulkResult, error) {
+	return model.BulkResult{}, nil
+}
+
+func (m *mockLinkService) BulkUpdateLinks(ctx context.Context, userID string, items []model.BulkUpdateItem, atomic bool) (model.BulkResult, error) {
+	return model.BulkResult{}, nil
+}
+
+func (m *mockLinkService) BulkDeleteLinks(ctx context.Context, userID string, items []model.BulkDeleteItem, atomic bool) (model.BulkResult, error) {
+	return model.BulkResult{}, nil
+}
+
 // TestCreateLinkHandler uses a table-driven format to test various scenarios.
 func TestCreateLinkHandler(t *testing.T) {
 	// Create a mock service and cache.
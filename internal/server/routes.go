@@ -8,14 +8,43 @@ func (s *Server) routes() {
 	// Public routes (no auth)
 	s.Mux.HandleFunc("/go/", hc.LinkHandler.RedirectHandler().ServeHTTP)
 	s.Mux.HandleFunc("/api/health", s.HealthHandler())
+	// Conversion reports from destination sites, authenticated by signed click ID
+	s.Mux.Handle("/api/conversions", hc.ConversionHandler.ConversionsRouter())
+	s.Mux.Handle("/api/conversions/", hc.ConversionHandler.ConversionsRouter())
+	// Universal Links and App Links association files, per custom domain
+	s.Mux.HandleFunc("/.well-known/apple-app-site-association", hc.AppHandler.AppleAppSiteAssociation())
+	s.Mux.HandleFunc("/.well-known/assetlinks.json", hc.AppHandler.AssetLinks())
+	// Link-in-bio pages at /@handle; anything else unmatched is a 404
+	s.Mux.HandleFunc("/", hc.BioHandler.BioPageHandler())
 
 	// User-related
 	// User-related routes
 
-	s.Mux.Handle("/api/user", middleware.ValidateJWT("https://api.mybackend.com", "dev-omr1iha4te137r50.us.auth0.com", hc.AuthHandler.LoginHandler()))
+	s.Mux.Handle("/api/user", middleware.ValidateJWT("https://api.mybackend.com", "dev-omr1iha4te137r50.us.auth0.com", hc.UserHandler.UserRouter()))
+	s.Mux.Handle("/api/user/", middleware.ValidateJWT("https://api.mybackend.com", "dev-omr1iha4te137r50.us.auth0.com", hc.UserHandler.UserRouter()))
 
 	//Link-related (protected by auth)
 	s.Mux.Handle("/api/links", middleware.ValidateJWT("https://api.mybackend.com", "dev-omr1iha4te137r50.us.auth0.com", hc.LinkHandler.LinksRouter()))
+	s.Mux.Handle("/api/links/", middleware.ValidateJWT("https://api.mybackend.com", "dev-omr1iha4te137r50.us.auth0.com", hc.LinkHandler.LinksRouter()))
+	// Link organisation (protected by auth)
+	s.Mux.Handle("/api/tags", middleware.ValidateJWT("https://api.mybackend.com", "dev-omr1iha4te137r50.us.auth0.com", hc.TagHandler.TagsRouter()))
+	s.Mux.Handle("/api/campaigns", middleware.ValidateJWT("https://api.mybackend.com", "dev-omr1iha4te137r50.us.auth0.com", hc.CampaignHandler.CampaignsRouter()))
+	s.Mux.Handle("/api/campaigns/", middleware.ValidateJWT("https://api.mybackend.com", "dev-omr1iha4te137r50.us.auth0.com", hc.CampaignHandler.CampaignsRouter()))
+	// Custom domains and the mobile apps allowed to open links on them (protected by auth)
+	s.Mux.Handle("/api/domains", middleware.ValidateJWT("https://api.mybackend.com", "dev-omr1iha4te137r50.us.auth0.com", hc.AppHandler.DomainsRouter()))
+	s.Mux.Handle("/api/domains/", middleware.ValidateJWT("https://api.mybackend.com", "dev-omr1iha4te137r50.us.auth0.com", hc.AppHandler.DomainsRouter()))
+	s.Mux.Handle("/api/apps", middleware.ValidateJWT("https://api.mybackend.com", "dev-omr1iha4te137r50.us.auth0.com", hc.AppHandler.AppsRouter()))
+	s.Mux.Handle("/api/bio", middleware.ValidateJWT("https://api.mybackend.com", "dev-omr1iha4te137r50.us.auth0.com", hc.BioHandler.BioRouter()))
+
+	// Analytics (protected by auth, gated by plan)
+	s.Mux.Handle("/api/clicks", middleware.ValidateJWT("https://api.mybackend.com", "dev-omr1iha4te137r50.us.auth0.com", hc.ClickHandler.ClicksRouter()))
+	s.Mux.Handle("/api/export/", middleware.ValidateJWT("https://api.mybackend.com", "dev-omr1iha4te137r50.us.auth0.com", hc.ExportHandler.ExportRouter()))
+	s.Mux.Handle("/api/usage", middleware.ValidateJWT("https://api.mybackend.com", "dev-omr1iha4te137r50.us.auth0.com", hc.UsageHandler.UsageRouter()))
+	// Admin console (protected by auth, admin role only)
+	s.Mux.Handle("/api/admin/", middleware.ValidateJWT("https://api.mybackend.com", "dev-omr1iha4te137r50.us.auth0.com", hc.AdminHandler.AdminRouter()))
+	// Secret that signs server-to-server conversion reports (protected by auth)
+	s.Mux.Handle("/api/conversions/secret", middleware.ValidateJWT("https://api.mybackend.com", "dev-omr1iha4te137r50.us.auth0.com", hc.ConversionHandler.SecretHandler()))
+	s.Mux.Handle("/api/audit", middleware.ValidateJWT("https://api.mybackend.com", "dev-omr1iha4te137r50.us.auth0.com", hc.AuditHandler.AuditRouter()))
 	// s.Mux.Handle("/api/links/list", auth(withUser(hc.LinkHandler.ListLinksHandler())))
 	//s.Mux.Handle("/api/links/", auth(withUser(hc.LinkHandler.GetMetricsHandler())))
 }
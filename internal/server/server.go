@@ -1,43 +1,120 @@
 package server
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"net"
 	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
 
 	lru "github.com/hashicorp/golang-lru"
 
+	"redo.ai/internal/pkg/ogmeta"
+	"redo.ai/internal/pkg/platform"
+	"redo.ai/internal/pkg/shortcode"
+	"redo.ai/internal/pkg/signer"
+	"redo.ai/internal/pkg/urlpolicy"
+	"redo.ai/internal/service/admin"
+	"redo.ai/internal/service/applink"
+	"redo.ai/internal/service/audit"
+	"redo.ai/internal/service/bio"
+	"redo.ai/internal/service/bulk"
+	"redo.ai/internal/service/campaign"
+	"redo.ai/internal/service/clicks"
 	"redo.ai/internal/service/link"
+	"redo.ai/internal/service/tag"
+	"redo.ai/internal/service/usage"
 	"redo.ai/internal/service/user"
 	"redo.ai/internal/utils"
 	"redo.ai/logger"
 )
 
 type Server struct {
-	DB         *sql.DB
-	LinkSvc    link.LinkService
-	UserSvc    user.UserService
-	cache      *lru.Cache
-	Mux        *http.ServeMux
-	HttpServer *http.Server
-	Handler    http.Handler
-	HC         *HandlerContainer
+	DB          *sql.DB
+	LinkSvc     link.LinkService
+	ClickSvc    clicks.ClickService
+	UsageSvc    usage.UsageService
+	AdminSvc    admin.AdminService
+	AuditSvc    audit.AuditService
+	TagSvc      tag.TagService
+	CampaignSvc campaign.CampaignService
+	BulkSvc     bulk.BulkService
+	AppLinkSvc  applink.AppLinkService
+	BioSvc      bio.BioService
+	UserSvc     user.UserService
+	cache       *lru.Cache
+	Mux         *http.ServeMux
+	HttpServer  *http.Server
+	Handler     http.Handler
+	Signer      *signer.Signer
+	// URLPolicy screens link destinations for the link service.
+	URLPolicy *urlpolicy.Policy
+	// DeepLinks maps destinations to app deep links.
+	DeepLinks *platform.Registry
+	// ShortURLBase is the validated SHORT_URL_BASE, e.g. https://redo.ai.
+	ShortURLBase string
+	HC           *HandlerContainer
 }
 
 func New(db *sql.DB) *Server {
-	linkSvc := &link.LinkSvc{DB: db}
 	userSvc := &user.UserSvc{DB: db}
+	usageSvc := &usage.UsageSvc{DB: db, Events: usage.LogSink{}}
+	shortCodes, err := shortcode.FromEnv(func(ctx context.Context) (uint64, error) {
+		var n uint64
+		err := db.QueryRowContext(ctx, `SELECT nextval('short_code_seq')`).Scan(&n)
+		return n, err
+	})
+	if err != nil {
+		logger.Fatal("invalid short code configuration: %v", err)
+	}
+	destinations, err := destinationPolicy()
+	if err != nil {
+		logger.Fatal("invalid destination policy configuration: %v", err)
+	}
+	if err := utils.SetTrustedProxies(os.Getenv("TRUSTED_PROXIES")); err != nil {
+		logger.Fatal("invalid TRUSTED_PROXIES: %v", err)
+	}
+	deepLinks, err := deepLinkRegistry()
+	if err != nil {
+		logger.Fatal("invalid deep-link registry: %v", err)
+	}
+	shortURLBase, err := parseShortURLBase(os.Getenv("SHORT_URL_BASE"))
+	if err != nil {
+		logger.Fatal("invalid SHORT_URL_BASE: %v", err)
+	}
+	linkSvc := &link.LinkSvc{DB: db, UserService: userSvc, Usage: usageSvc, ShortCodes: shortCodes, Destinations: destinations,
+		Previews: &ogmeta.HTTPFetcher{Client: urlpolicy.PublicClient(5 * time.Second), UserAgent: "Mozilla/5.0 (compatible; redo.ai link preview)"}}
+	clickSvc := &clicks.ClickSvc{DB: db, UserService: userSvc}
 
 	mux := http.NewServeMux()
 
 	c, _ := lru.New(10000) // cache up to 10,000 links
+	auditSvc := &audit.AuditSvc{DB: db}
 
 	srv := &Server{
-		DB:      db,
-		LinkSvc: linkSvc,
-		UserSvc: userSvc,
-		Mux:     mux,
-		cache:   c,
+		DB:          db,
+		LinkSvc:     linkSvc,
+		ClickSvc:    clickSvc,
+		UsageSvc:    usageSvc,
+		AdminSvc:    &admin.AdminSvc{DB: db},
+		AuditSvc:    auditSvc,
+		TagSvc:      &tag.TagSvc{DB: db},
+		CampaignSvc: &campaign.CampaignSvc{DB: db},
+		BulkSvc:     &bulk.BulkSvc{DB: db, Audit: auditSvc},
+		AppLinkSvc:  &applink.AppLinkSvc{DB: db},
+		BioSvc:      &bio.BioSvc{DB: db},
+		UserSvc:     userSvc,
+		Mux:         mux,
+		cache:       c,
+		URLPolicy:   destinations,
+		Signer:      signer.FromEnv("LINK_SIGNING_KEY"),
+		DeepLinks:   deepLinks,
+
+		ShortURLBase: shortURLBase,
 	}
 
 	// Initialize handler container with the server instance
@@ -45,11 +122,60 @@ func New(db *sql.DB) *Server {
 	srv.routes()
 
 	// Apply logging middleware globally
-	srv.Handler = utils.WithCORS(utils.LoggingWrap(mux))
+	srv.Handler = utils.WithCORS(utils.WithRequestID(utils.LoggingWrap(mux)))
 
 	return srv
 }
 
+// destinationPolicy screens destinations against private addresses, shortener
+// redirect chains and, when DESTINATION_BLOCKLIST names a file, a domain
+// blocklist that is reloaded while the server runs.
+func destinationPolicy() (*urlpolicy.Policy, error) {
+	policy := &urlpolicy.Policy{
+		Resolver: net.DefaultResolver,
+		Client:   urlpolicy.NoRedirectClient(&http.Client{Timeout: 5 * time.Second}),
+	}
+	if path := os.Getenv("DESTINATION_BLOCKLIST"); path != "" {
+		bl, err := urlpolicy.LoadBlocklist(path)
+		if err != nil {
+			return nil, err
+		}
+		logger.Info("loaded %d blocklisted destination domains from %s", bl.Len(), path)
+		policy.Blocklist = bl
+	}
+	return policy, nil
+}
+
+// deepLinkRegistry loads the services file named by DEEPLINK_REGISTRY, or
+// the registry embedded in the binary when it is unset.
+func deepLinkRegistry() (*platform.Registry, error) {
+	path := os.Getenv("DEEPLINK_REGISTRY")
+	if path == "" {
+		return platform.DefaultRegistry(), nil
+	}
+	reg, err := platform.LoadRegistry(path)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("loaded deep-link registry from %s", path)
+	return reg, nil
+}
+
+// parseShortURLBase checks the origin short URLs are built from. Without
+// one, QR codes cannot be generated: the request's Host header is client
+// controlled and must not end up in a printed code.
+func parseShortURLBase(raw string) (string, error) {
+	if raw == "" {
+		logger.Warn("SHORT_URL_BASE is not set; QR codes are disabled")
+		return "", nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.User != nil || u.RawQuery != "" || u.Fragment != "" {
+		return "", fmt.Errorf("%q must be an http or https origin", raw)
+	}
+	return strings.TrimRight(u.Scheme+"://"+u.Host+u.Path, "/"), nil
+}
+
 func (s *Server) Start(port string) error {
 	addr := fmt.Sprintf(":%s", port)
 	logger.Info("Listening on %s", addr)
@@ -58,5 +184,7 @@ func (s *Server) Start(port string) error {
 		Handler: s.Handler,
 	}
 
+	s.startJobs(context.Background())
+
 	return s.HttpServer.ListenAndServe()
 }
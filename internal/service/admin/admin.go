@@ -0,0 +1,231 @@
+package admin
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"redo.ai/internal/model"
+	"redo.ai/internal/pkg/cursor"
+	"redo.ai/internal/pkg/entitlements"
+	"redo.ai/internal/utils"
+	"redo.ai/logger"
+)
+
+// Moderation actions recorded in moderation_actions.
+const (
+	ActionSetRole     = "set_role"
+	ActionDisableLink = "disable_link"
+	ActionEnableLink  = "enable_link"
+)
+
+// AdminService defines the interface for cross-tenant operations used by ops staff.
+type AdminService interface {
+	SearchUsers(ctx context.Context, email string, limit int) ([]model.User, error)
+	SetRole(ctx context.Context, adminID, userID, role string) (*model.User, error)
+	SetLinkActive(ctx context.Context, adminID, linkID string, active bool, reason string) error
+	ListModerationActions(ctx context.Context, after string, limit int) (model.ModerationPage, error)
+}
+
+var ErrUserNotFound = errors.New("user not found")
+var ErrLinkNotFound = errors.New("link not found")
+var ErrInvalidRole = errors.New("invalid role")
+
+type AdminSvc struct {
+	DB *sql.DB
+}
+
+func (s *AdminSvc) SearchUsers(ctx context.Context, email string, limit int) ([]model.User, error) {
+	query := `
+		SELECT id::text, role, email, COALESCE(name, ''), COALESCE(business_name, ''), created_at
+		FROM users
+		WHERE email ILIKE $1
+		ORDER BY email
+		LIMIT $2
+	`
+	rows, err := s.DB.QueryContext(ctx, query, utils.LikePattern(email), limit)
+	if err != nil {
+		logger.Error("SearchUsers: query failed: %v", err)
+		return nil, fmt.Errorf("search users failed: %w", err)
+	}
+	defer rows.Close()
+
+	users := make([]model.User, 0)
+	for rows.Next() {
+		var u model.User
+		if err := rows.Scan(&u.UserID, &u.Role, &u.Email, &u.Name, &u.BusinessName, &u.CreatedAt); err != nil {
+			logger.Error("SearchUsers: scan failed: %v", err)
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+		users = append(users, u)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error("SearchUsers: rows iteration error: %v", err)
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return users, nil
+}
+
+// SetRole changes a user's plan and records the previous role.
+func (s *AdminSvc) SetRole(ctx context.Context, adminID, userID, role string) (*model.User, error) {
+	if !entitlements.IsValidPlan(role) {
+		return nil, ErrInvalidRole
+	}
+
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx failed: %w", err)
+	}
+	defer tx.Rollback()
+
+	var previous string
+	err = tx.QueryRowContext(ctx, `SELECT role FROM users WHERE id = $1 FOR UPDATE`, userID).Scan(&previous)
+	if err == sql.ErrNoRows {
+		return nil, ErrUserNotFound
+	} else if err != nil {
+		logger.Error("SetRole: failed to load user %s: %v", userID, err)
+		return nil, fmt.Errorf("load user failed: %w", err)
+	}
+
+	var u model.User
+	query := `
+		UPDATE users SET role = $2::user_role, updated_at = now()
+		WHERE id = $1
+		RETURNING id::text, role, email, COALESCE(name, ''), COALESCE(business_name, ''), created_at
+	`
+	if err := tx.QueryRowContext(ctx, query, userID, role).Scan(&u.UserID, &u.Role, &u.Email, &u.Name, &u.BusinessName, &u.CreatedAt); err != nil {
+		logger.Error("SetRole: update failed for user %s: %v", userID, err)
+		return nil, fmt.Errorf("update role failed: %w", err)
+	}
+
+	details := map[string]string{"from": previous, "to": role}
+	if err := recordAction(ctx, tx, adminID, ActionSetRole, "user", userID, "", details); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit failed: %w", err)
+	}
+	logger.Info("admin %s changed role of user %s from %s to %s", adminID, userID, previous, role)
+	return &u, nil
+}
+
+// SetLinkActive disables (with a reason) or re-enables any user's link.
+func (s *AdminSvc) SetLinkActive(ctx context.Context, adminID, linkID string, active bool, reason string) error {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx failed: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE links
+		SET is_active = $2,
+		    disabled_reason = CASE WHEN $2 THEN NULL ELSE $3 END,
+		    deactivated_at = CASE WHEN $2 THEN NULL ELSE now() END,
+		    updated_at = now()
+		WHERE id = $1
+	`
+	res, err := tx.ExecContext(ctx, query, linkID, active, reason)
+	if err != nil {
+		logger.Error("SetLinkActive: update failed for link %s: %v", linkID, err)
+		return fmt.Errorf("update link failed: %w", err)
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return ErrLinkNotFound
+	}
+
+	action := ActionDisableLink
+	if active {
+		action = ActionEnableLink
+	}
+	if err := recordAction(ctx, tx, adminID, action, "link", linkID, reason, nil); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit failed: %w", err)
+	}
+	logger.Info("admin %s: %s link %s (%s)", adminID, action, linkID, reason)
+	return nil
+}
+
+// pageKey is the keyset position encoded in moderation log cursors. Actions
+// written in one transaction share created_at, so id breaks ties.
+type pageKey struct {
+	CreatedAt time.Time `json:"t"`
+	ID        string    `json:"id"`
+}
+
+// ListModerationActions returns a page of actions, newest first, starting
+// after the cursor (or at the newest when after is empty).
+func (s *AdminSvc) ListModerationActions(ctx context.Context, after string, limit int) (model.ModerationPage, error) {
+	where, args := "TRUE", []any{}
+	if after != "" {
+		var key pageKey
+		if err := cursor.Decode(after, &key); err != nil {
+			return model.ModerationPage{}, err
+		}
+		where, args = "(created_at, id) < ($1, $2::uuid)", append(args, key.CreatedAt, key.ID)
+	}
+	args = append(args, limit+1)
+	query := fmt.Sprintf(`
+		SELECT id::text, COALESCE(admin_id::text, ''), action, target_type, target_id::text,
+		       COALESCE(reason, ''), COALESCE(details, '{}'::jsonb), created_at
+		FROM moderation_actions
+		WHERE %s
+		ORDER BY created_at DESC, id DESC
+		LIMIT $%d
+	`, where, len(args))
+	rows, err := s.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.Error("ListModerationActions: query failed: %v", err)
+		return model.ModerationPage{}, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	page := model.ModerationPage{Actions: make([]model.ModerationAction, 0, limit)}
+	for rows.Next() {
+		var (
+			a       model.ModerationAction
+			details []byte
+		)
+		if err := rows.Scan(&a.ID, &a.AdminID, &a.Action, &a.TargetType, &a.TargetID, &a.Reason, &details, &a.CreatedAt); err != nil {
+			logger.Error("ListModerationActions: scan failed: %v", err)
+			return model.ModerationPage{}, fmt.Errorf("scan failed: %w", err)
+		}
+		a.Details = json.RawMessage(details)
+		page.Actions = append(page.Actions, a)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error("ListModerationActions: rows iteration error: %v", err)
+		return model.ModerationPage{}, fmt.Errorf("rows error: %w", err)
+	}
+
+	if len(page.Actions) > limit {
+		page.Actions = page.Actions[:limit]
+		last := page.Actions[limit-1]
+		page.NextCursor, _ = cursor.Encode(pageKey{CreatedAt: last.CreatedAt, ID: last.ID})
+	}
+	return page, nil
+}
+
+func recordAction(ctx context.Context, tx *sql.Tx, adminID, action, targetType, targetID, reason string, details any) error {
+	payload := []byte("{}")
+	if details != nil {
+		var err error
+		if payload, err = json.Marshal(details); err != nil {
+			return fmt.Errorf("marshal details failed: %w", err)
+		}
+	}
+	query := `
+		INSERT INTO moderation_actions (admin_id, action, target_type, target_id, reason, details)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6)
+	`
+	if _, err := tx.ExecContext(ctx, query, adminID, action, targetType, targetID, reason, payload); err != nil {
+		logger.Error("recordAction: failed to record %s on %s %s: %v", action, targetType, targetID, err)
+		return fmt.Errorf("record moderation action failed: %w", err)
+	}
+	return nil
+}
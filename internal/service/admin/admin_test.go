@@ -0,0 +1,59 @@
+package admin
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+	"testing"
+	"time"
+
+	"redo.ai/internal/pkg/cursor"
+	"redo.ai/internal/pkg/fakesql"
+)
+
+var actionColumns = []string{"id", "admin_id", "action", "target_type", "target_id", "reason", "details", "created_at"}
+
+func TestListModerationActionsPagesThroughTies(t *testing.T) {
+	// Both actions were written in one transaction and share created_at.
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	f, db := fakesql.New(t)
+	f.On("FROM moderation_actions", func(args []driver.Value) fakesql.Result {
+		res := fakesql.Result{Columns: actionColumns}
+		switch len(args) {
+		case 1:
+			res.Rows = [][]driver.Value{
+				{"b", "admin", ActionDisableLink, "link", "l2", "spam", []byte("{}"), at},
+				{"a", "admin", ActionDisableLink, "link", "l1", "spam", []byte("{}"), at},
+			}
+		case 3:
+			if args[0] != at || args[1] != "b" {
+				t.Errorf("second page keyed on %v, want (%v, b)", args[:2], at)
+			}
+			res.Rows = [][]driver.Value{{"a", "admin", ActionDisableLink, "link", "l1", "spam", []byte("{}"), at}}
+		}
+		return res
+	})
+
+	s := &AdminSvc{DB: db}
+	first, err := s.ListModerationActions(context.Background(), "", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(first.Actions) != 1 || first.Actions[0].ID != "b" || first.NextCursor == "" {
+		t.Fatalf("first page = %+v, want b and a cursor", first)
+	}
+	second, err := s.ListModerationActions(context.Background(), first.NextCursor, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(second.Actions) != 1 || second.Actions[0].ID != "a" || second.NextCursor != "" {
+		t.Fatalf("second page = %+v, want a and no cursor", second)
+	}
+	if q := f.Ran("FROM moderation_actions")[1].Query; !strings.Contains(q, "(created_at, id) <") || !strings.Contains(q, "ORDER BY created_at DESC, id DESC") {
+		t.Errorf("second page query does not page on (created_at, id):\n%s", q)
+	}
+
+	if _, err := s.ListModerationActions(context.Background(), "!!!", 1); err != cursor.ErrInvalidCursor {
+		t.Errorf("bad cursor: err = %v, want ErrInvalidCursor", err)
+	}
+}
@@ -0,0 +1,174 @@
+package applink
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+	"redo.ai/internal/model"
+	"redo.ai/logger"
+)
+
+// AppLinkService manages custom domains and the apps allowed to open short
+// links on them through iOS Universal Links and Android App Links.
+//
+// Registrations feed the association files: once they are served, the OS
+// opens the app for links on the domain before any redirect happens. When
+// Android does not, the redirect hands the short link to the domain's app
+// through an intent instead.
+type AppLinkService interface {
+	ListDomains(ctx context.Context, userID string) ([]model.CustomDomain, error)
+	CreateDomain(ctx context.Context, userID, domain string) (model.CustomDomain, error)
+	// VerifyDomain checks the domain's DNS verification record.
+	VerifyDomain(ctx context.Context, userID, id string) (model.CustomDomain, error)
+	DeleteDomain(ctx context.Context, userID, id string) (model.CustomDomain, error)
+
+	ListApps(ctx context.Context, userID string) ([]model.AppAssociation, error)
+	CreateApp(ctx context.Context, userID string, req model.CreateAppRequest) (model.AppAssociation, error)
+	DeleteApp(ctx context.Context, userID, id string) (model.AppAssociation, error)
+	// AppsForHost returns the apps of the verified custom domain host.
+	AppsForHost(ctx context.Context, host string) ([]model.AppAssociation, error)
+}
+
+var ErrAppNotFound = errors.New("app not found")
+var ErrAppExists = errors.New("app already registered")
+var ErrDomainNotFound = errors.New("domain not found")
+
+// MaxApps bounds the apps a user can register.
+const MaxApps = 50
+
+var ErrTooManyApps = fmt.Errorf("at most %d apps can be registered", MaxApps)
+
+type AppLinkSvc struct {
+	DB *sql.DB
+	// Resolver looks up domain verification records; nil uses the system
+	// resolver.
+	Resolver Resolver
+}
+
+// appColumns is the select list scanned by scanApp; it expects
+// app_associations aliased as a and custom_domains as d.
+const appColumns = `a.id::text, a.platform, a.app_id, COALESCE(d.domain, ''), a.paths, a.fingerprints, a.created_at`
+
+func scanApp(row interface{ Scan(...any) error }) (model.AppAssociation, error) {
+	var a model.AppAssociation
+	err := row.Scan(&a.ID, &a.Platform, &a.AppID, &a.Domain, pq.Array(&a.Paths), pq.Array(&a.Fingerprints), &a.CreatedAt)
+	return a, err
+}
+
+func (s *AppLinkSvc) ListApps(ctx context.Context, userID string) ([]model.AppAssociation, error) {
+	query := `
+		SELECT ` + appColumns + `
+		FROM app_associations a
+		LEFT JOIN custom_domains d ON d.id = a.custom_domain_id
+		WHERE a.user_id = $1
+		ORDER BY a.created_at, a.id
+	`
+	return s.queryApps(ctx, "ListApps", query, userID)
+}
+
+// CreateApp registers an app for one of the user's domains, or for all of
+// them when req.Domain is empty. req must already be validated.
+func (s *AppLinkSvc) CreateApp(ctx context.Context, userID string, req model.CreateAppRequest) (model.AppAssociation, error) {
+	var domainID sql.NullString
+	if req.Domain != "" {
+		err := s.DB.QueryRowContext(ctx, `
+			SELECT id::text FROM custom_domains WHERE domain = $1 AND user_id = $2
+		`, strings.ToLower(req.Domain), userID).Scan(&domainID)
+		if err == sql.ErrNoRows {
+			return model.AppAssociation{}, ErrDomainNotFound
+		} else if err != nil {
+			logger.Error("CreateApp: domain lookup failed for userID=%s: %v", userID, err)
+			return model.AppAssociation{}, fmt.Errorf("domain lookup failed: %w", err)
+		}
+	}
+
+	row := s.DB.QueryRowContext(ctx, `
+		WITH a AS (
+			INSERT INTO app_associations (user_id, custom_domain_id, platform, app_id, paths, fingerprints)
+			SELECT $1, $2, $3, $4, $5, $6
+			WHERE (SELECT COUNT(*) FROM app_associations WHERE user_id = $1) < $7
+			RETURNING *
+		)
+		SELECT `+appColumns+`
+		FROM a LEFT JOIN custom_domains d ON d.id = a.custom_domain_id
+	`, userID, domainID, req.Platform, req.AppID, pq.Array(nonNil(req.Paths)), pq.Array(nonNil(req.Fingerprints)), MaxApps)
+	app, err := scanApp(row)
+	if err == sql.ErrNoRows {
+		return model.AppAssociation{}, ErrTooManyApps
+	} else if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return model.AppAssociation{}, ErrAppExists
+		}
+		logger.Error("CreateApp: insert failed for userID=%s: %v", userID, err)
+		return model.AppAssociation{}, fmt.Errorf("create app failed: %w", err)
+	}
+	return app, nil
+}
+
+// DeleteApp removes an app and returns it as it was.
+func (s *AppLinkSvc) DeleteApp(ctx context.Context, userID, id string) (model.AppAssociation, error) {
+	row := s.DB.QueryRowContext(ctx, `
+		WITH a AS (
+			DELETE FROM app_associations WHERE id = $1 AND user_id = $2 RETURNING *
+		)
+		SELECT `+appColumns+`
+		FROM a LEFT JOIN custom_domains d ON d.id = a.custom_domain_id
+	`, id, userID)
+	app, err := scanApp(row)
+	if err == sql.ErrNoRows {
+		return model.AppAssociation{}, ErrAppNotFound
+	} else if err != nil {
+		logger.Error("DeleteApp: delete failed for id=%s: %v", id, err)
+		return model.AppAssociation{}, fmt.Errorf("delete app failed: %w", err)
+	}
+	return app, nil
+}
+
+// AppsForHost returns the apps registered for the domain and the
+// domain-wide apps of its owner. Unverified domains have none, so nobody can
+// claim links on a domain they do not control.
+func (s *AppLinkSvc) AppsForHost(ctx context.Context, host string) ([]model.AppAssociation, error) {
+	query := `
+		SELECT ` + appColumns + `
+		FROM custom_domains d
+		JOIN app_associations a ON a.user_id = d.user_id
+		     AND (a.custom_domain_id = d.id OR a.custom_domain_id IS NULL)
+		WHERE d.domain = $1 AND d.is_verified
+		ORDER BY a.created_at, a.id
+	`
+	return s.queryApps(ctx, "AppsForHost", query, strings.ToLower(host))
+}
+
+func (s *AppLinkSvc) queryApps(ctx context.Context, fn, query string, args ...any) ([]model.AppAssociation, error) {
+	rows, err := s.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.Error("%s: query failed: %v", fn, err)
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	apps := make([]model.AppAssociation, 0)
+	for rows.Next() {
+		a, err := scanApp(rows)
+		if err != nil {
+			logger.Error("%s: scan failed: %v", fn, err)
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+		apps = append(apps, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return apps, nil
+}
+
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
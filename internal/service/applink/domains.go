@@ -0,0 +1,202 @@
+package applink
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/lib/pq"
+	"redo.ai/internal/model"
+	"redo.ai/internal/pkg/entitlements"
+	"redo.ai/logger"
+)
+
+// VerificationPrefix names the TXT record that proves control of a custom
+// domain: _redo-verify.<domain> must hold "redo-verify=<token>".
+const (
+	VerificationPrefix = "_redo-verify."
+	verificationValue  = "redo-verify="
+)
+
+var ErrDomainExists = errors.New("domain already registered")
+var ErrDomainUnverified = errors.New("verification record not found")
+var ErrDomainInUse = errors.New("domain is used by links")
+
+// Resolver looks up the TXT records of a host; *net.Resolver implements it.
+type Resolver interface {
+	LookupTXT(ctx context.Context, host string) ([]string, error)
+}
+
+func (s *AppLinkSvc) resolver() Resolver {
+	if s.Resolver != nil {
+		return s.Resolver
+	}
+	return net.DefaultResolver
+}
+
+const domainColumns = `id::text, domain, is_verified, verification_token, verified_at, created_at`
+
+func scanDomain(row interface{ Scan(...any) error }) (model.CustomDomain, error) {
+	var (
+		d          model.CustomDomain
+		token      string
+		verifiedAt sql.NullTime
+	)
+	if err := row.Scan(&d.ID, &d.Domain, &d.Verified, &token, &verifiedAt, &d.CreatedAt); err != nil {
+		return d, err
+	}
+	d.VerificationRecord = VerificationPrefix + d.Domain
+	d.VerificationValue = verificationValue + token
+	if verifiedAt.Valid {
+		d.VerifiedAt = &verifiedAt.Time
+	}
+	return d, nil
+}
+
+func (s *AppLinkSvc) ListDomains(ctx context.Context, userID string) ([]model.CustomDomain, error) {
+	rows, err := s.DB.QueryContext(ctx, `
+		SELECT `+domainColumns+`
+		FROM custom_domains
+		WHERE user_id = $1
+		ORDER BY created_at, id
+	`, userID)
+	if err != nil {
+		logger.Error("ListDomains: query failed for userID=%s: %v", userID, err)
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	domains := make([]model.CustomDomain, 0)
+	for rows.Next() {
+		d, err := scanDomain(rows)
+		if err != nil {
+			logger.Error("ListDomains: scan failed: %v", err)
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+		domains = append(domains, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return domains, nil
+}
+
+// CreateDomain adds an unverified domain within the plan's custom domain
+// quota. The user's row is locked while counting so concurrent creates
+// cannot both pass the check. domain must already be validated.
+func (s *AppLinkSvc) CreateDomain(ctx context.Context, userID, domain string) (model.CustomDomain, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("CreateDomain: begin failed for userID=%s: %v", userID, err)
+		return model.CustomDomain{}, fmt.Errorf("begin failed: %w", err)
+	}
+	defer tx.Rollback()
+
+	var role string
+	if err := tx.QueryRowContext(ctx, `
+		SELECT role::text FROM users WHERE id = $1 FOR NO KEY UPDATE
+	`, userID).Scan(&role); err != nil {
+		logger.Error("CreateDomain: lock failed for userID=%s: %v", userID, err)
+		return model.CustomDomain{}, fmt.Errorf("lock user failed: %w", err)
+	}
+	var used int
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM custom_domains WHERE user_id = $1`, userID).Scan(&used); err != nil {
+		logger.Error("CreateDomain: count failed for userID=%s: %v", userID, err)
+		return model.CustomDomain{}, fmt.Errorf("count domains failed: %w", err)
+	}
+	if err := entitlements.For(role).CheckQuota(entitlements.LimitCustomDomains, used, 1); err != nil {
+		logger.Warn("CreateDomain: userID=%s: %v", userID, err)
+		return model.CustomDomain{}, err
+	}
+
+	d, err := scanDomain(tx.QueryRowContext(ctx, `
+		INSERT INTO custom_domains (user_id, domain)
+		VALUES ($1, $2)
+		RETURNING `+domainColumns, userID, strings.ToLower(domain)))
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return model.CustomDomain{}, ErrDomainExists
+		}
+		logger.Error("CreateDomain: insert failed for userID=%s: %v", userID, err)
+		return model.CustomDomain{}, fmt.Errorf("create domain failed: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		logger.Error("CreateDomain: commit failed for userID=%s: %v", userID, err)
+		return model.CustomDomain{}, fmt.Errorf("commit failed: %w", err)
+	}
+	return d, nil
+}
+
+// VerifyDomain looks up the domain's verification record and marks it
+// verified when the token is present. Verified domains stay verified.
+func (s *AppLinkSvc) VerifyDomain(ctx context.Context, userID, id string) (model.CustomDomain, error) {
+	d, err := scanDomain(s.DB.QueryRowContext(ctx, `
+		SELECT `+domainColumns+` FROM custom_domains WHERE id = $1 AND user_id = $2
+	`, id, userID))
+	if err == sql.ErrNoRows {
+		return model.CustomDomain{}, ErrDomainNotFound
+	} else if err != nil {
+		logger.Error("VerifyDomain: lookup failed for id=%s: %v", id, err)
+		return model.CustomDomain{}, fmt.Errorf("domain lookup failed: %w", err)
+	}
+	if d.Verified {
+		return d, nil
+	}
+
+	records, err := s.resolver().LookupTXT(ctx, d.VerificationRecord)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if !errors.As(err, &dnsErr) || !dnsErr.IsNotFound {
+			logger.Warn("VerifyDomain: TXT lookup failed for %s: %v", d.VerificationRecord, err)
+		}
+		return d, ErrDomainUnverified
+	}
+	if !hasRecord(records, d.VerificationValue) {
+		return d, ErrDomainUnverified
+	}
+
+	d, err = scanDomain(s.DB.QueryRowContext(ctx, `
+		UPDATE custom_domains
+		SET is_verified = TRUE, verified_at = NOW()
+		WHERE id = $1 AND user_id = $2
+		RETURNING `+domainColumns, id, userID))
+	if err == sql.ErrNoRows {
+		return model.CustomDomain{}, ErrDomainNotFound
+	} else if err != nil {
+		logger.Error("VerifyDomain: update failed for id=%s: %v", id, err)
+		return model.CustomDomain{}, fmt.Errorf("verify domain failed: %w", err)
+	}
+	return d, nil
+}
+
+// DeleteDomain removes a domain and the apps registered for it, and returns
+// it as it was. Domains that links still use cannot be deleted.
+func (s *AppLinkSvc) DeleteDomain(ctx context.Context, userID, id string) (model.CustomDomain, error) {
+	d, err := scanDomain(s.DB.QueryRowContext(ctx, `
+		DELETE FROM custom_domains WHERE id = $1 AND user_id = $2
+		RETURNING `+domainColumns, id, userID))
+	if err == sql.ErrNoRows {
+		return model.CustomDomain{}, ErrDomainNotFound
+	} else if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+			return model.CustomDomain{}, ErrDomainInUse
+		}
+		logger.Error("DeleteDomain: delete failed for id=%s: %v", id, err)
+		return model.CustomDomain{}, fmt.Errorf("delete domain failed: %w", err)
+	}
+	return d, nil
+}
+
+// hasRecord reports whether want is one of the TXT records. Providers may
+// quote values or split them, so surrounding quotes and spaces are ignored.
+func hasRecord(records []string, want string) bool {
+	for _, r := range records {
+		if strings.Trim(strings.TrimSpace(r), `"`) == want {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,23 @@
+package applink
+
+import "testing"
+
+func TestHasRecord(t *testing.T) {
+	want := "redo-verify=abc123"
+	tests := []struct {
+		records []string
+		ok      bool
+	}{
+		{[]string{"v=spf1 -all", "redo-verify=abc123"}, true},
+		{[]string{`"redo-verify=abc123"`}, true},
+		{[]string{" redo-verify=abc123 "}, true},
+		{[]string{"redo-verify=abc1234"}, false},
+		{[]string{"redo-verify=other"}, false},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		if got := hasRecord(tt.records, want); got != tt.ok {
+			t.Errorf("hasRecord(%q) = %v, want %v", tt.records, got, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"redo.ai/internal/model"
+	"redo.ai/internal/pkg/cursor"
+	"redo.ai/logger"
+)
+
+// Actions recorded in audit_events.
+const (
+	ActionLinkCreate   = "link.create"
+	ActionLinkUpdate   = "link.update"
+	ActionLinkDelete   = "link.delete"
+	ActionDomainCreate = "domain.create"
+	ActionDomainUpdate = "domain.update"
+	ActionDomainDelete = "domain.delete"
+	ActionAPIKeyCreate = "api_key.create"
+	ActionAPIKeyRevoke = "api_key.revoke"
+	ActionRoleChange   = "user.role_change"
+	ActionLinkModerate = "link.moderate"
+	ActionLinkVariants = "link.variants"
+	ActionLinkRules    = "link.rules"
+	ActionLinkSchedule = "link.schedule"
+	ActionAppCreate    = "app.create"
+	ActionAppDelete    = "app.delete"
+	ActionBioUpdate    = "bio.update"
+	ActionBioDelete    = "bio.delete"
+	ActionBulkJobFail  = "bulk_job.fail"
+)
+
+// Target types recorded in audit_events.
+const (
+	TargetLink   = "link"
+	TargetDomain = "domain"
+	TargetAPIKey = "api_key"
+	TargetUser   = "user"
+	TargetApp    = "app"
+	TargetBio    = "bio_page"
+	TargetJob    = "bulk_job"
+)
+
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 200
+)
+
+// AuditService defines the interface for the audit trail.
+type AuditService interface {
+	Record(ctx context.Context, ev model.AuditEvent) error
+	List(ctx context.Context, filter model.AuditFilter) (model.AuditPage, error)
+}
+
+type AuditSvc struct {
+	DB *sql.DB
+}
+
+type pageKey struct {
+	CreatedAt time.Time `json:"t"`
+	ID        string    `json:"id"`
+}
+
+func (s *AuditSvc) Record(ctx context.Context, ev model.AuditEvent) error {
+	query := `
+		INSERT INTO audit_events (actor_id, action, target_type, target_id, before, after, ip, request_id)
+		VALUES (NULLIF($1, '')::uuid, $2, $3, $4, $5, $6, NULLIF($7, ''), NULLIF($8, ''))
+	`
+	_, err := s.DB.ExecContext(ctx, query, ev.ActorID, ev.Action, ev.TargetType, ev.TargetID,
+		nullJSON(ev.Before), nullJSON(ev.After), ev.IP, ev.RequestID)
+	if err != nil {
+		logger.Error("audit.Record: failed to record %s on %s %s: %v", ev.Action, ev.TargetType, ev.TargetID, err)
+		return fmt.Errorf("record audit event failed: %w", err)
+	}
+	return nil
+}
+
+// List returns events newest first, one page at a time.
+func (s *AuditSvc) List(ctx context.Context, f model.AuditFilter) (model.AuditPage, error) {
+	var (
+		where []string
+		args  []any
+	)
+	add := func(cond string, v any) {
+		args = append(args, v)
+		where = append(where, fmt.Sprintf(cond, len(args)))
+	}
+	if f.ActorID != "" {
+		add("actor_id = $%d", f.ActorID)
+	}
+	if f.Action != "" {
+		add("action = $%d", f.Action)
+	}
+	if f.TargetType != "" {
+		add("target_type = $%d", f.TargetType)
+	}
+	if f.TargetID != "" {
+		add("target_id = $%d", f.TargetID)
+	}
+	if !f.Since.IsZero() {
+		add("created_at >= $%d", f.Since)
+	}
+	if !f.Until.IsZero() {
+		add("created_at < $%d", f.Until)
+	}
+	if f.Cursor != "" {
+		var key pageKey
+		if err := cursor.Decode(f.Cursor, &key); err != nil {
+			return model.AuditPage{}, err
+		}
+		args = append(args, key.CreatedAt, key.ID)
+		where = append(where, fmt.Sprintf("(created_at, id) < ($%d, $%d::uuid)", len(args)-1, len(args)))
+	}
+	if len(where) == 0 {
+		where = append(where, "TRUE")
+	}
+
+	limit := f.Limit
+	if limit <= 0 || limit > MaxPageSize {
+		limit = DefaultPageSize
+	}
+	args = append(args, limit+1)
+
+	query := fmt.Sprintf(`
+		SELECT id::text, COALESCE(actor_id::text, ''), action, target_type, target_id,
+		       before, after, COALESCE(ip, ''), COALESCE(request_id, ''), created_at
+		FROM audit_events
+		WHERE %s
+		ORDER BY created_at DESC, id DESC
+		LIMIT $%d
+	`, strings.Join(where, " AND "), len(args))
+
+	rows, err := s.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.Error("audit.List: query failed: %v", err)
+		return model.AuditPage{}, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	page := model.AuditPage{Events: make([]model.AuditEvent, 0, limit)}
+	for rows.Next() {
+		var (
+			ev            model.AuditEvent
+			before, after []byte
+		)
+		if err := rows.Scan(&ev.ID, &ev.ActorID, &ev.Action, &ev.TargetType, &ev.TargetID,
+			&before, &after, &ev.IP, &ev.RequestID, &ev.CreatedAt); err != nil {
+			logger.Error("audit.List: scan failed: %v", err)
+			return model.AuditPage{}, fmt.Errorf("scan failed: %w", err)
+		}
+		ev.Before, ev.After = before, after
+		page.Events = append(page.Events, ev)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error("audit.List: rows iteration error: %v", err)
+		return model.AuditPage{}, fmt.Errorf("rows error: %w", err)
+	}
+
+	if len(page.Events) > limit {
+		page.Events = page.Events[:limit]
+		last := page.Events[limit-1]
+		page.NextCursor, _ = cursor.Encode(pageKey{CreatedAt: last.CreatedAt, ID: last.ID})
+	}
+	return page, nil
+}
+
+// Diff marshals before and after and keeps only the top-level fields that
+// changed. A nil side is recorded as null, so creates and deletes keep the
+// full object on the other side.
+func Diff(before, after any) (json.RawMessage, json.RawMessage, error) {
+	b, err := toMap(before)
+	if err != nil {
+		return nil, nil, err
+	}
+	a, err := toMap(after)
+	if err != nil {
+		return nil, nil, err
+	}
+	if b != nil && a != nil {
+		for k, bv := range b {
+			if av, ok := a[k]; ok && reflect.DeepEqual(av, bv) {
+				delete(a, k)
+				delete(b, k)
+			}
+		}
+	}
+	bj, err := marshalOrNil(b)
+	if err != nil {
+		return nil, nil, err
+	}
+	aj, err := marshalOrNil(a)
+	if err != nil {
+		return nil, nil, err
+	}
+	return bj, aj, nil
+}
+
+func toMap(v any) (map[string]any, error) {
+	if v == nil || (reflect.ValueOf(v).Kind() == reflect.Pointer && reflect.ValueOf(v).IsNil()) {
+		return nil, nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	m := map[string]any{}
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func marshalOrNil(m map[string]any) (json.RawMessage, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return json.Marshal(m)
+}
+
+func nullJSON(raw json.RawMessage) any {
+	if len(raw) == 0 {
+		return nil
+	}
+	return []byte(raw)
+}
@@ -0,0 +1,207 @@
+package bio
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+	"redo.ai/internal/model"
+	"redo.ai/internal/service/link"
+	"redo.ai/logger"
+)
+
+// BioService manages link-in-bio pages.
+type BioService interface {
+	GetPage(ctx context.Context, userID string) (model.BioPage, error)
+	// SavePage creates or replaces the user's page and its items. req must
+	// already be validated.
+	SavePage(ctx context.Context, userID string, req model.SaveBioPageRequest) (model.BioPage, error)
+	DeletePage(ctx context.Context, userID string) (model.BioPage, error)
+	// PublicPage returns the page for visitors: only items whose links are
+	// active and live, and nothing for owners pending deletion.
+	PublicPage(ctx context.Context, handle string) (model.BioPage, error)
+}
+
+var ErrPageNotFound = errors.New("bio page not found")
+var ErrHandleTaken = errors.New("handle already taken")
+
+// MaxItems bounds the links on one page.
+const MaxItems = 100
+
+var ErrTooManyItems = fmt.Errorf("at most %d links can be listed", MaxItems)
+
+// Themes are the page styles bio.html knows how to render.
+var Themes = []string{"light", "dark", "midnight", "sunset", "forest"}
+
+const DefaultTheme = "light"
+
+type BioSvc struct {
+	DB *sql.DB
+}
+
+// pageColumns is the select list scanned by scanPage; it expects bio_pages
+// aliased as p.
+const pageColumns = `p.id::text, p.handle, p.title, p.description, p.avatar_url, p.theme, p.created_at, p.updated_at`
+
+func scanPage(row interface{ Scan(...any) error }) (model.BioPage, error) {
+	var p model.BioPage
+	err := row.Scan(&p.ID, &p.Handle, &p.Title, &p.Description, &p.AvatarURL, &p.Theme, &p.CreatedAt, &p.UpdatedAt)
+	return p, err
+}
+
+func (s *BioSvc) GetPage(ctx context.Context, userID string) (model.BioPage, error) {
+	page, err := scanPage(s.DB.QueryRowContext(ctx, `
+		SELECT `+pageColumns+` FROM bio_pages p WHERE p.user_id = $1
+	`, userID))
+	if err == sql.ErrNoRows {
+		return model.BioPage{}, ErrPageNotFound
+	} else if err != nil {
+		logger.Error("GetPage: query failed for userID=%s: %v", userID, err)
+		return model.BioPage{}, fmt.Errorf("get bio page failed: %w", err)
+	}
+	page.Items, err = s.items(ctx, "GetPage", `
+		SELECT i.link_id::text, l.short_code, i.title, i.icon
+		FROM bio_page_items i
+		JOIN links l ON l.id = i.link_id
+		WHERE i.page_id = $1
+		ORDER BY i.position
+	`, page.ID)
+	if err != nil {
+		return model.BioPage{}, err
+	}
+	return page, nil
+}
+
+func (s *BioSvc) SavePage(ctx context.Context, userID string, req model.SaveBioPageRequest) (model.BioPage, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("SavePage: begin failed for userID=%s: %v", userID, err)
+		return model.BioPage{}, fmt.Errorf("begin failed: %w", err)
+	}
+	defer tx.Rollback()
+
+	var pageID string
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO bio_pages (user_id, handle, title, description, avatar_url, theme)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (user_id) DO UPDATE
+		SET handle = EXCLUDED.handle, title = EXCLUDED.title, description = EXCLUDED.description,
+		    avatar_url = EXCLUDED.avatar_url, theme = EXCLUDED.theme, updated_at = now()
+		RETURNING id::text
+	`, userID, req.Handle, req.Title, req.Description, req.AvatarURL, req.Theme).Scan(&pageID)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return model.BioPage{}, ErrHandleTaken
+		}
+		logger.Error("SavePage: upsert failed for userID=%s: %v", userID, err)
+		return model.BioPage{}, fmt.Errorf("save bio page failed: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM bio_page_items WHERE page_id = $1`, pageID); err != nil {
+		logger.Error("SavePage: clearing items failed for page=%s: %v", pageID, err)
+		return model.BioPage{}, fmt.Errorf("save bio page failed: %w", err)
+	}
+	if len(req.Items) > 0 {
+		linkIDs := make([]string, len(req.Items))
+		titles := make([]string, len(req.Items))
+		icons := make([]string, len(req.Items))
+		for i, it := range req.Items {
+			linkIDs[i], titles[i], icons[i] = it.LinkID, it.Title, it.Icon
+		}
+		// The join on links drops items for links the user does not own, so
+		// a short count means one of them was not found.
+		res, err := tx.ExecContext(ctx, `
+			INSERT INTO bio_page_items (page_id, link_id, position, title, icon)
+			SELECT $1, l.id, t.ord, t.title, t.icon
+			FROM unnest($2::uuid[], $3::text[], $4::text[]) WITH ORDINALITY AS t(link_id, title, icon, ord)
+			JOIN links l ON l.id = t.link_id AND l.user_id = $5
+		`, pageID, pq.Array(linkIDs), pq.Array(titles), pq.Array(icons), userID)
+		if err != nil {
+			logger.Error("SavePage: inserting items failed for page=%s: %v", pageID, err)
+			return model.BioPage{}, fmt.Errorf("save bio page failed: %w", err)
+		}
+		if n, err := res.RowsAffected(); err != nil || n != int64(len(req.Items)) {
+			return model.BioPage{}, link.ErrLinkNotFound
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("SavePage: commit failed for userID=%s: %v", userID, err)
+		return model.BioPage{}, fmt.Errorf("commit failed: %w", err)
+	}
+	return s.GetPage(ctx, userID)
+}
+
+// DeletePage removes the user's page and returns it as it was.
+func (s *BioSvc) DeletePage(ctx context.Context, userID string) (model.BioPage, error) {
+	page, err := s.GetPage(ctx, userID)
+	if err != nil {
+		return model.BioPage{}, err
+	}
+	res, err := s.DB.ExecContext(ctx, `DELETE FROM bio_pages WHERE id = $1 AND user_id = $2`, page.ID, userID)
+	if err != nil {
+		logger.Error("DeletePage: delete failed for userID=%s: %v", userID, err)
+		return model.BioPage{}, fmt.Errorf("delete bio page failed: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return model.BioPage{}, ErrPageNotFound
+	}
+	return page, nil
+}
+
+func (s *BioSvc) PublicPage(ctx context.Context, handle string) (model.BioPage, error) {
+	page, err := scanPage(s.DB.QueryRowContext(ctx, `
+		SELECT `+pageColumns+`
+		FROM bio_pages p
+		JOIN users u ON u.id = p.user_id
+		WHERE lower(p.handle) = $1 AND u.deletion_scheduled_for IS NULL
+	`, strings.ToLower(handle)))
+	if err == sql.ErrNoRows {
+		return model.BioPage{}, ErrPageNotFound
+	} else if err != nil {
+		logger.Error("PublicPage: query failed for handle=%s: %v", handle, err)
+		return model.BioPage{}, fmt.Errorf("get bio page failed: %w", err)
+	}
+	// Untitled items fall back to the short code rather than the
+	// destination, which password-protected links keep private.
+	page.Items, err = s.items(ctx, "PublicPage", `
+		SELECT i.link_id::text, l.short_code,
+		       COALESCE(NULLIF(i.title, ''), NULLIF(l.title, ''), l.short_code), i.icon
+		FROM bio_page_items i
+		JOIN links l ON l.id = i.link_id
+		WHERE i.page_id = $1
+		  AND COALESCE(l.is_active, TRUE)
+		  AND (l.activates_at IS NULL OR l.activates_at <= now())
+		ORDER BY i.position
+	`, page.ID)
+	if err != nil {
+		return model.BioPage{}, err
+	}
+	return page, nil
+}
+
+func (s *BioSvc) items(ctx context.Context, fn, query, pageID string) ([]model.BioItem, error) {
+	rows, err := s.DB.QueryContext(ctx, query, pageID)
+	if err != nil {
+		logger.Error("%s: items query failed for page=%s: %v", fn, pageID, err)
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	items := make([]model.BioItem, 0)
+	for rows.Next() {
+		var it model.BioItem
+		if err := rows.Scan(&it.LinkID, &it.ShortCode, &it.Title, &it.Icon); err != nil {
+			logger.Error("%s: scan failed: %v", fn, err)
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+		items = append(items, it)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return items, nil
+}
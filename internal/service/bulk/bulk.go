@@ -0,0 +1,343 @@
+package bulk
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"redo.ai/internal/model"
+	"redo.ai/internal/pkg/entitlements"
+	"redo.ai/internal/service/audit"
+	"redo.ai/logger"
+)
+
+// Operations recorded in bulk_jobs.
+const (
+	OpCreate = "create"
+	OpUpdate = "update"
+	OpDelete = "delete"
+)
+
+const (
+	// MaxConcurrentJobs bounds how many background jobs one worker runs at
+	// once; the rest wait in the queued state.
+	MaxConcurrentJobs = 4
+	// JobTimeout is the longest a single job may run.
+	JobTimeout = 30 * time.Minute
+	// LeaseTimeout is how long a running job may go without a heartbeat
+	// before another worker takes it over.
+	LeaseTimeout = 2 * time.Minute
+	// MaxAttempts bounds how often an interrupted job is resumed.
+	MaxAttempts = 3
+
+	heartbeatInterval = 30 * time.Second
+	pollInterval      = 10 * time.Second
+)
+
+var ErrJobNotFound = errors.New("bulk job not found")
+
+// Job is a claimed job together with what it was submitted with.
+type Job struct {
+	model.BulkJob
+	UserID  string
+	Payload json.RawMessage
+	// Origin carries the actor and request details of the submitting
+	// request, for audit events written after it has ended.
+	Origin model.AuditEvent
+}
+
+// RunFunc performs the bulk operation of a job. Jobs are resumed from the
+// start after a restart, so run must apply the whole operation in one
+// transaction.
+type RunFunc func(ctx context.Context, job Job) (model.BulkResult, error)
+
+// BulkService runs large bulk link operations in the background. Jobs and
+// their payloads live in bulk_jobs, so any instance can run them and an
+// interrupted job is picked up again.
+type BulkService interface {
+	Submit(ctx context.Context, userID, operation string, atomic bool, total int, payload any, origin model.AuditEvent) (model.BulkJob, error)
+	GetJob(ctx context.Context, userID, jobID string) (model.BulkJob, error)
+	// Work claims and runs jobs with run until ctx is cancelled.
+	Work(ctx context.Context, run RunFunc)
+}
+
+type BulkSvc struct {
+	DB    *sql.DB
+	Audit audit.AuditService
+
+	once sync.Once
+	wake chan struct{}
+}
+
+const jobColumns = `id::text, operation, status, atomic, total, result, COALESCE(error, ''),
+	created_at, started_at, finished_at`
+
+func scanJob(row interface{ Scan(...any) error }, extra ...any) (model.BulkJob, error) {
+	var (
+		job    model.BulkJob
+		result []byte
+	)
+	dest := append([]any{&job.ID, &job.Operation, &job.Status, &job.Atomic, &job.Total, &result,
+		&job.Error, &job.CreatedAt, &job.StartedAt, &job.FinishedAt}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return job, err
+	}
+	if len(result) > 0 {
+		job.Result = &model.BulkResult{}
+		if err := json.Unmarshal(result, job.Result); err != nil {
+			return job, fmt.Errorf("decode job result failed: %w", err)
+		}
+	}
+	return job, nil
+}
+
+func (s *BulkSvc) init() {
+	s.once.Do(func() { s.wake = make(chan struct{}, 1) })
+}
+
+// Submit stores a queued job with its payload and wakes the worker.
+func (s *BulkSvc) Submit(ctx context.Context, userID, operation string, atomic bool, total int, payload any, origin model.AuditEvent) (model.BulkJob, error) {
+	encodedPayload, err := json.Marshal(payload)
+	if err != nil {
+		return model.BulkJob{}, fmt.Errorf("encode job payload failed: %w", err)
+	}
+	encodedOrigin, err := json.Marshal(origin)
+	if err != nil {
+		return model.BulkJob{}, fmt.Errorf("encode job origin failed: %w", err)
+	}
+	query := `
+		INSERT INTO bulk_jobs (user_id, operation, atomic, total, payload, origin)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING ` + jobColumns
+	job, err := scanJob(s.DB.QueryRowContext(ctx, query, userID, operation, atomic, total, encodedPayload, encodedOrigin))
+	if err != nil {
+		logger.Error("bulk.Submit: failed to create job for userID=%s: %v", userID, err)
+		return model.BulkJob{}, fmt.Errorf("create job failed: %w", err)
+	}
+
+	s.init()
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	return job, nil
+}
+
+func (s *BulkSvc) Work(ctx context.Context, run RunFunc) {
+	s.init()
+	slots := make(chan struct{}, MaxConcurrentJobs)
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		s.failExhausted(ctx)
+		for {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			job, ok := s.claim(ctx)
+			if !ok {
+				<-slots
+				break
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer func() { <-slots }()
+				s.execute(ctx, job, run)
+			}()
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// claim leases the oldest queued job, or a running one whose worker stopped
+// heartbeating and which has attempts left.
+func (s *BulkSvc) claim(ctx context.Context) (Job, bool) {
+	query := `
+		UPDATE bulk_jobs
+		SET status = 'running', started_at = COALESCE(started_at, now()), heartbeat_at = now(), attempts = attempts + 1
+		WHERE id = (
+			SELECT id FROM bulk_jobs
+			WHERE payload IS NOT NULL
+			  AND (status = 'queued'
+			       OR (status = 'running' AND heartbeat_at < now() - make_interval(secs => $1) AND attempts < $2))
+			ORDER BY created_at
+			FOR UPDATE SKIP LOCKED
+			LIMIT 1
+		)
+		RETURNING ` + jobColumns + `, user_id::text, payload, origin`
+	var (
+		job    Job
+		origin []byte
+	)
+	var err error
+	job.BulkJob, err = scanJob(s.DB.QueryRowContext(ctx, query, LeaseTimeout.Seconds(), MaxAttempts), &job.UserID, &job.Payload, &origin)
+	if err == sql.ErrNoRows {
+		return Job{}, false
+	} else if err != nil {
+		if ctx.Err() == nil {
+			logger.Error("bulk.claim: failed to claim a job: %v", err)
+		}
+		return Job{}, false
+	}
+	if len(origin) > 0 {
+		if err := json.Unmarshal(origin, &job.Origin); err != nil {
+			logger.Warn("bulk.claim: bad origin on job %s: %v", job.ID, err)
+		}
+	}
+	return job, true
+}
+
+func (s *BulkSvc) execute(parent context.Context, job Job, run RunFunc) {
+	ctx, cancel := context.WithTimeout(parent, JobTimeout)
+	defer cancel()
+	go s.heartbeat(ctx, job.ID)
+
+	result, err := run(ctx, job)
+	if err != nil && parent.Err() != nil {
+		// Shutting down: the transaction rolled back and the lease will
+		// lapse, so another worker resumes the job.
+		logger.Warn("bulk.execute: job %s interrupted by shutdown", job.ID)
+		return
+	}
+	if err != nil {
+		logger.Error("bulk.execute: job %s failed: %v", job.ID, err)
+		s.finish(job, model.BulkJobFailed, nil, publicError(err))
+		return
+	}
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		logger.Error("bulk.execute: failed to encode result of job %s: %v", job.ID, err)
+		s.finish(job, model.BulkJobFailed, nil, "failed to store result")
+		return
+	}
+	logger.Info("bulk job %s completed: %d succeeded, %d failed", job.ID, result.Succeeded, result.Failed)
+	s.finish(job, model.BulkJobCompleted, encoded, "")
+}
+
+// heartbeat renews the job's lease until ctx ends.
+func (s *BulkSvc) heartbeat(ctx context.Context, jobID string) {
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.DB.ExecContext(ctx, `UPDATE bulk_jobs SET heartbeat_at = now() WHERE id = $1 AND status = 'running'`, jobID); err != nil && ctx.Err() == nil {
+				logger.Warn("bulk.heartbeat: job %s: %v", jobID, err)
+			}
+		}
+	}
+}
+
+// finish records the final status and drops the payload, which may hold
+// link passwords. Failures are written to the audit log.
+func (s *BulkSvc) finish(job Job, status string, result []byte, message string) {
+	// The job context may have expired; the final status must still land.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	query := `
+		UPDATE bulk_jobs
+		SET status = $2, result = $3, error = NULLIF($4, ''), finished_at = now(), payload = NULL
+		WHERE id = $1
+	`
+	if _, err := s.DB.ExecContext(ctx, query, job.ID, status, result, message); err != nil {
+		logger.Error("bulk.finish: failed to record status of job %s: %v", job.ID, err)
+	}
+	if status == model.BulkJobFailed {
+		s.auditFailure(ctx, job.ID, job.Operation, job.Origin, message)
+	}
+}
+
+// failExhausted fails jobs that were interrupted MaxAttempts times.
+func (s *BulkSvc) failExhausted(ctx context.Context) {
+	rows, err := s.DB.QueryContext(ctx, `
+		UPDATE bulk_jobs
+		SET status = 'failed', error = 'job interrupted', finished_at = now(), payload = NULL
+		WHERE status = 'running' AND heartbeat_at < now() - make_interval(secs => $1) AND attempts >= $2
+		RETURNING id::text, operation, origin
+	`, LeaseTimeout.Seconds(), MaxAttempts)
+	if err != nil {
+		if ctx.Err() == nil {
+			logger.Error("bulk.failExhausted: update failed: %v", err)
+		}
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			id, op string
+			raw    []byte
+			origin model.AuditEvent
+		)
+		if err := rows.Scan(&id, &op, &raw); err != nil {
+			logger.Error("bulk.failExhausted: scan failed: %v", err)
+			return
+		}
+		if len(raw) > 0 {
+			_ = json.Unmarshal(raw, &origin)
+		}
+		logger.Warn("bulk job %s failed after %d interrupted attempts", id, MaxAttempts)
+		s.auditFailure(ctx, id, op, origin, "job interrupted")
+	}
+}
+
+func (s *BulkSvc) auditFailure(ctx context.Context, jobID, operation string, origin model.AuditEvent, message string) {
+	if s.Audit == nil {
+		return
+	}
+	after, err := json.Marshal(struct {
+		Operation string `json:"operation"`
+		Error     string `json:"error"`
+	}{operation, message})
+	if err != nil {
+		return
+	}
+	ev := origin
+	ev.Action = audit.ActionBulkJobFail
+	ev.TargetType = audit.TargetJob
+	ev.TargetID = jobID
+	ev.After = after
+	_ = s.Audit.Record(ctx, ev)
+}
+
+// publicError hides internal failures from the job status while keeping plan
+// limit messages, which tell the user what to do.
+func publicError(err error) string {
+	var qe *entitlements.QuotaError
+	var fe *entitlements.FeatureError
+	if errors.As(err, &qe) || errors.As(err, &fe) {
+		return err.Error()
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "job timed out"
+	}
+	return "bulk operation failed"
+}
+
+func (s *BulkSvc) GetJob(ctx context.Context, userID, jobID string) (model.BulkJob, error) {
+	query := `SELECT ` + jobColumns + ` FROM bulk_jobs WHERE id = $1 AND user_id = $2`
+	job, err := scanJob(s.DB.QueryRowContext(ctx, query, jobID, userID))
+	if err == sql.ErrNoRows {
+		return model.BulkJob{}, ErrJobNotFound
+	} else if err != nil {
+		logger.Error("bulk.GetJob: DB error: %v", err)
+		return model.BulkJob{}, fmt.Errorf("get job failed: %w", err)
+	}
+	return job, nil
+}
@@ -0,0 +1,234 @@
+package campaign
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"redo.ai/internal/model"
+	"redo.ai/logger"
+)
+
+// CampaignService defines the interface for grouping links into campaigns.
+type CampaignService interface {
+	ListCampaigns(ctx context.Context, userID string) ([]model.Campaign, error)
+	GetCampaign(ctx context.Context, userID, campaignID string) (model.Campaign, error)
+	CreateCampaign(ctx context.Context, userID string, req model.CampaignRequest) (model.Campaign, error)
+	UpdateCampaign(ctx context.Context, userID, campaignID string, req model.CampaignRequest) (model.Campaign, error)
+	DeleteCampaign(ctx context.Context, userID, campaignID string) error
+	CampaignAnalytics(ctx context.Context, userID, campaignID string, since time.Time) (model.CampaignAnalytics, error)
+}
+
+var ErrCampaignNotFound = errors.New("campaign not found")
+var ErrCampaignExists = errors.New("campaign already exists")
+var ErrInvalidCampaign = errors.New("invalid campaign name")
+
+type CampaignSvc struct {
+	DB *sql.DB
+}
+
+const campaignColumns = `c.id::text, c.name, COALESCE(c.description, ''), c.created_at,
+	(SELECT COUNT(*) FROM links l WHERE l.campaign_id = c.id)`
+
+func scanCampaign(row interface{ Scan(...any) error }) (model.Campaign, error) {
+	var c model.Campaign
+	err := row.Scan(&c.ID, &c.Name, &c.Description, &c.CreatedAt, &c.LinkCount)
+	return c, err
+}
+
+func isDuplicate(err error) bool {
+	pqErr, ok := err.(*pq.Error)
+	return ok && pqErr.Code == "23505"
+}
+
+func (s *CampaignSvc) ListCampaigns(ctx context.Context, userID string) ([]model.Campaign, error) {
+	query := `SELECT ` + campaignColumns + ` FROM campaigns c WHERE c.user_id = $1 ORDER BY c.created_at DESC`
+	rows, err := s.DB.QueryContext(ctx, query, userID)
+	if err != nil {
+		logger.Error("ListCampaigns: query failed: %v", err)
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	campaigns := make([]model.Campaign, 0)
+	for rows.Next() {
+		c, err := scanCampaign(rows)
+		if err != nil {
+			logger.Error("ListCampaigns: scan failed: %v", err)
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+		campaigns = append(campaigns, c)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error("ListCampaigns: rows iteration error: %v", err)
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return campaigns, nil
+}
+
+func (s *CampaignSvc) GetCampaign(ctx context.Context, userID, campaignID string) (model.Campaign, error) {
+	query := `SELECT ` + campaignColumns + ` FROM campaigns c WHERE c.id = $1 AND c.user_id = $2`
+	c, err := scanCampaign(s.DB.QueryRowContext(ctx, query, campaignID, userID))
+	if err == sql.ErrNoRows {
+		return model.Campaign{}, ErrCampaignNotFound
+	} else if err != nil {
+		logger.Error("GetCampaign: query failed: %v", err)
+		return model.Campaign{}, fmt.Errorf("get campaign failed: %w", err)
+	}
+	return c, nil
+}
+
+func (s *CampaignSvc) CreateCampaign(ctx context.Context, userID string, req model.CampaignRequest) (model.Campaign, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return model.Campaign{}, ErrInvalidCampaign
+	}
+	query := `
+		WITH member_clicks AS (
+			INSERT INTO campaigns (user_id, name, description)
+			VALUES ($1, $2, NULLIF($3, ''))
+			RETURNING *
+		)
+		SELECT ` + campaignColumns + ` FROM c
+	`
+	c, err := scanCampaign(s.DB.QueryRowContext(ctx, query, userID, name, req.Description))
+	if isDuplicate(err) {
+		return model.Campaign{}, ErrCampaignExists
+	} else if err != nil {
+		logger.Error("CreateCampaign: insert failed for userID=%s: %v", userID, err)
+		return model.Campaign{}, fmt.Errorf("create campaign failed: %w", err)
+	}
+	return c, nil
+}
+
+func (s *CampaignSvc) UpdateCampaign(ctx context.Context, userID, campaignID string, req model.CampaignRequest) (model.Campaign, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return model.Campaign{}, ErrInvalidCampaign
+	}
+	query := `
+		WITH member_clicks AS (
+			UPDATE campaigns
+			SET name = $3, description = NULLIF($4, ''), updated_at = now()
+			WHERE id = $1 AND user_id = $2
+			RETURNING *
+		)
+		SELECT ` + campaignColumns + ` FROM c
+	`
+	c, err := scanCampaign(s.DB.QueryRowContext(ctx, query, campaignID, userID, name, req.Description))
+	if err == sql.ErrNoRows {
+		return model.Campaign{}, ErrCampaignNotFound
+	} else if isDuplicate(err) {
+		return model.Campaign{}, ErrCampaignExists
+	} else if err != nil {
+		logger.Error("UpdateCampaign: update failed for campaignID=%s: %v", campaignID, err)
+		return model.Campaign{}, fmt.Errorf("update campaign failed: %w", err)
+	}
+	return c, nil
+}
+
+// DeleteCampaign removes the campaign; its links stay and simply leave it.
+func (s *CampaignSvc) DeleteCampaign(ctx context.Context, userID, campaignID string) error {
+	res, err := s.DB.ExecContext(ctx, `DELETE FROM campaigns WHERE id = $1 AND user_id = $2`, campaignID, userID)
+	if err != nil {
+		logger.Error("DeleteCampaign: delete failed for campaignID=%s: %v", campaignID, err)
+		return fmt.Errorf("delete campaign failed: %w", err)
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return ErrCampaignNotFound
+	}
+	return nil
+}
+
+// CampaignAnalytics aggregates the clicks on every member link into
+// campaign-level totals in one grouped query. Clicks older than since are
+// ignored; a zero since keeps everything.
+func (s *CampaignSvc) CampaignAnalytics(ctx context.Context, userID, campaignID string, since time.Time) (model.CampaignAnalytics, error) {
+	if _, err := s.GetCampaign(ctx, userID, campaignID); err != nil {
+		return model.CampaignAnalytics{}, err
+	}
+
+	// Each grouping set yields one dimension; the empty set is the total.
+	rows, err := s.DB.QueryContext(ctx, `
+		WITH member_clicks AS (
+			SELECT to_char(c.created_at AT TIME ZONE 'UTC', 'YYYY-MM-DD') AS day,
+			       COALESCE(NULLIF(l.slug, ''), l.short_code) AS link,
+			       COALESCE(NULLIF(c.country, ''), 'unknown') AS country,
+			       COALESCE(NULLIF(c.device_type, ''), 'unknown') AS device,
+			       COALESCE(c.conversion, FALSE) AS conversion
+			FROM clicks c
+			JOIN links l ON l.id = c.link_id
+			WHERE l.campaign_id = $1 AND l.user_id = $2 AND c.created_at >= $3
+		)
+		SELECT CASE WHEN GROUPING(day) = 0 THEN 'day'
+		            WHEN GROUPING(link) = 0 THEN 'link'
+		            WHEN GROUPING(country) = 0 THEN 'country'
+		            WHEN GROUPING(device) = 0 THEN 'device'
+		            ELSE 'total' END,
+		       COALESCE(day, link, country, device, ''),
+		       COUNT(*), COUNT(*) FILTER (WHERE conversion)
+		FROM member_clicks
+		GROUP BY GROUPING SETS ((day), (link), (country), (device), ())
+	`, campaignID, userID, since)
+	if err != nil {
+		logger.Error("CampaignAnalytics: query failed for campaignID=%s: %v", campaignID, err)
+		return model.CampaignAnalytics{}, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	result := model.CampaignAnalytics{CampaignID: campaignID}
+	counts := map[string]map[string]int{"day": {}, "link": {}, "country": {}, "device": {}}
+	for rows.Next() {
+		var (
+			dim, label        string
+			clicks, converted int
+		)
+		if err := rows.Scan(&dim, &label, &clicks, &converted); err != nil {
+			logger.Error("CampaignAnalytics: scan failed: %v", err)
+			return model.CampaignAnalytics{}, fmt.Errorf("scan failed: %w", err)
+		}
+		if dim == "total" {
+			result.TotalClicks, result.Conversions = clicks, converted
+			continue
+		}
+		counts[dim][label] = clicks
+	}
+	if err := rows.Err(); err != nil {
+		return model.CampaignAnalytics{}, fmt.Errorf("rows error: %w", err)
+	}
+	perDay := counts["day"]
+
+	days := make([]string, 0, len(perDay))
+	for d := range perDay {
+		days = append(days, d)
+	}
+	sort.Strings(days)
+	result.ClicksPerDay = make([]model.ClicksByDay, 0, len(days))
+	for _, d := range days {
+		result.ClicksPerDay = append(result.ClicksPerDay, model.ClicksByDay{DateLabel: d, Clicks: perDay[d]})
+	}
+	result.ByLink = grouped(counts["link"])
+	result.ByCountry = grouped(counts["country"])
+	result.ByDevice = grouped(counts["device"])
+	return result, nil
+}
+
+// grouped flattens counts into metrics, highest count first.
+func grouped(counts map[string]int) []model.GroupedMetric {
+	out := make([]model.GroupedMetric, 0, len(counts))
+	for label, n := range counts {
+		out = append(out, model.GroupedMetric{Label: label, Count: n})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Label < out[j].Label
+	})
+	return out
+}
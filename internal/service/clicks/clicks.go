@@ -9,6 +9,7 @@ import (
 
 	"redo.ai/internal/model"
 	"redo.ai/internal/service/user"
+	"redo.ai/internal/utils"
 	"redo.ai/logger"
 )
 
@@ -18,9 +19,16 @@ type ClickService interface {
 	TrackClick(ctx context.Context, shortCode, ip, referrer, userAgent, deviceType, country, org string, conversion, highValue bool) error
 	GetClickCount(ctx context.Context, shortCode string) (int, error)
 	GetLinkClicks(ctx context.Context, linkID string) ([]model.Click, error)
+	StreamClicks(ctx context.Context, filter model.ClickFilter, fn func(model.Click) error) error
 	GetRecentClicksByUser(ctx context.Context, userID string, limit int) ([]model.Click, error)
-	GetClicksGroupedByDevice(ctx context.Context, userID string) ([]model.GroupedMetric, error)
-	GetClicksGroupedByCountry(ctx context.Context, userID string) ([]model.GroupedMetric, error)
+	GetClicksGroupedByDevice(ctx context.Context, userID string, since time.Time) ([]model.GroupedMetric, error)
+	GetClicksGroupedByCountry(ctx context.Context, userID string, since time.Time) ([]model.GroupedMetric, error)
+	GetClicksGroupedBySource(ctx context.Context, userID string, since time.Time) ([]model.GroupedMetric, error)
+	RecordConversion(ctx context.Context, clickID string, revenueCents *int64) (model.Conversion, error)
+	ConversionSecret(ctx context.Context, userID string, rotate bool) (string, error)
+	ClickConversionSecret(ctx context.Context, clickID string) (string, error)
+	ConversionsByLink(ctx context.Context, userID string, since time.Time) ([]model.LinkConversions, error)
+	ConversionsPerDay(ctx context.Context, userID, linkID string, since time.Time) ([]model.ConversionsByDay, error)
 }
 
 var ErrLinkNotFound = errors.New("link not found")
@@ -102,33 +110,63 @@ func (s *ClickSvc) TrackClick(ctx context.Context, shortCode, ip, referrer, user
 }
 
 func (s *ClickSvc) GetLinkClicks(ctx context.Context, linkID string) ([]model.Click, error) {
+	var clicks []model.Click = make([]model.Click, 0)
+	err := s.StreamClicks(ctx, model.ClickFilter{LinkID: linkID}, func(c model.Click) error {
+		clicks = append(clicks, c)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("GetLinkClicks: %w", err)
+	}
+	return clicks, nil
+}
+
+// StreamClicks calls fn for each click matching filter, newest first, without
+// buffering the result set. An error from fn stops the iteration and is
+// returned as is.
+func (s *ClickSvc) StreamClicks(ctx context.Context, f model.ClickFilter, fn func(model.Click) error) error {
 	query := `
-		SELECT id::text, link_id::text, ip, referrer, user_agent, device_type, country, conversion, is_high_value, created_at
-		FROM clicks
-		WHERE link_id = $1
-		ORDER BY created_at DESC;
+		SELECT c.id::text, c.link_id::text, COALESCE(c.ip, ''), COALESCE(c.referrer, ''), COALESCE(c.user_agent, ''),
+		       COALESCE(c.device_type, ''), COALESCE(c.country, ''), COALESCE(c.conversion, FALSE), COALESCE(c.is_high_value, FALSE),
+		       COALESCE(c.source, ''), COALESCE(c.variant_id::text, ''), c.created_at,
+		       c.revenue_cents, c.converted_at
+		FROM clicks c
+		JOIN links l ON c.link_id = l.id
+		WHERE (NULLIF($1, '') IS NULL OR l.user_id = NULLIF($1, '')::uuid)
+		  AND (NULLIF($2, '') IS NULL OR c.link_id = NULLIF($2, '')::uuid)
+		  AND ($3::timestamptz IS NULL OR c.created_at >= $3)
+		  AND ($4::timestamptz IS NULL OR c.created_at < $4)
+		ORDER BY c.created_at DESC;
 	`
-	rows, err := s.DB.QueryContext(ctx, query, linkID)
+	rows, err := s.DB.QueryContext(ctx, query, f.UserID, f.LinkID, utils.NullTime(f.From), utils.NullTime(f.To))
 	if err != nil {
-		logger.Error("GetLinkClicks: query failed: %v", err)
-		return nil, fmt.Errorf("GetLinkClicks: query failed: %w", err)
+		logger.Error("StreamClicks: query failed: %v", err)
+		return fmt.Errorf("query failed: %w", err)
 	}
 	defer rows.Close()
-	var clicks []model.Click = make([]model.Click, 0)
 	for rows.Next() {
 		var c model.Click
-		if err := rows.Scan(&c.ID, &c.LinkID, &c.IP, &c.Referrer, &c.UserAgent, &c.DeviceType, &c.Country, &c.Conversion, &c.IsHighValue, &c.CreatedAt); err != nil {
-			logger.Error("GetLinkClicks: scan failed: %v", err)
-			return nil, fmt.Errorf("GetLinkClicks: scan failed: %w", err)
+		if err := rows.Scan(&c.ID, &c.LinkID, &c.IP, &c.Referrer, &c.UserAgent, &c.DeviceType, &c.Country, &c.Conversion, &c.IsHighValue, &c.Source, &c.VariantID, &c.CreatedAt, &c.RevenueCents, &c.ConvertedAt); err != nil {
+			logger.Error("StreamClicks: scan failed: %v", err)
+			return fmt.Errorf("scan failed: %w", err)
+		}
+		if err := fn(c); err != nil {
+			return err
 		}
-		clicks = append(clicks, c)
 	}
-	return clicks, nil
+	if err := rows.Err(); err != nil {
+		logger.Error("StreamClicks: row iteration failed: %v", err)
+		return fmt.Errorf("row iteration failed: %w", err)
+	}
+	return nil
 }
 
 func (s *ClickSvc) GetRecentClicksByUser(ctx context.Context, userID string, limit int) ([]model.Click, error) {
 	query := `
-		SELECT c.id::text, c.link_id::text, c.ip, c.referrer, c.user_agent, c.device_type, c.country, c.conversion, c.is_high_value, c.created_at
+		SELECT c.id::text, c.link_id::text, COALESCE(c.ip, ''), COALESCE(c.referrer, ''), COALESCE(c.user_agent, ''),
+		       COALESCE(c.device_type, ''), COALESCE(c.country, ''), COALESCE(c.conversion, FALSE), COALESCE(c.is_high_value, FALSE),
+		       COALESCE(c.source, ''), COALESCE(c.variant_id::text, ''), c.created_at,
+		       c.revenue_cents, c.converted_at
 		FROM clicks c
 		JOIN links l ON c.link_id = l.id
 		WHERE l.user_id = $1
@@ -145,7 +183,7 @@ func (s *ClickSvc) GetRecentClicksByUser(ctx context.Context, userID string, lim
 	var clicks []model.Click = make([]model.Click, 0)
 	for rows.Next() {
 		var c model.Click
-		if err := rows.Scan(&c.ID, &c.LinkID, &c.IP, &c.Referrer, &c.UserAgent, &c.DeviceType, &c.Country, &c.Conversion, &c.IsHighValue, &c.CreatedAt); err != nil {
+		if err := rows.Scan(&c.ID, &c.LinkID, &c.IP, &c.Referrer, &c.UserAgent, &c.DeviceType, &c.Country, &c.Conversion, &c.IsHighValue, &c.Source, &c.VariantID, &c.CreatedAt, &c.RevenueCents, &c.ConvertedAt); err != nil {
 			logger.Error("GetRecentClicksByUser: scan failed: %v", err)
 			return nil, fmt.Errorf("GetRecentClicksByUser: scan failed: %w", err)
 		}
@@ -154,15 +192,18 @@ func (s *ClickSvc) GetRecentClicksByUser(ctx context.Context, userID string, lim
 	return clicks, nil
 }
 
-func (s *ClickSvc) GetClicksGroupedByDevice(ctx context.Context, userID string) ([]model.GroupedMetric, error) {
+// GetClicksGroupedByDevice counts clicks newer than since; a zero since
+// counts everything.
+func (s *ClickSvc) GetClicksGroupedByDevice(ctx context.Context, userID string, since time.Time) ([]model.GroupedMetric, error) {
 	query := `
 		SELECT COALESCE(device_type, 'unknown') AS label, COUNT(*)
 		FROM clicks c
 		JOIN links l ON c.link_id = l.id
 		WHERE l.user_id = $1
+		  AND ($2::timestamptz IS NULL OR c.created_at >= $2)
 		GROUP BY device_type;
 	`
-	rows, err := s.DB.QueryContext(ctx, query, userID)
+	rows, err := s.DB.QueryContext(ctx, query, userID, utils.NullTime(since))
 	if err != nil {
 		logger.Error("GetClicksGroupedByDevice: query failed: %v", err)
 		return nil, fmt.Errorf("GetClicksGroupedByDevice: query failed: %w", err)
@@ -181,15 +222,18 @@ func (s *ClickSvc) GetClicksGroupedByDevice(ctx context.Context, userID string)
 	return results, nil
 }
 
-func (s *ClickSvc) GetClicksGroupedByCountry(ctx context.Context, userID string) ([]model.GroupedMetric, error) {
+// GetClicksGroupedByCountry counts clicks newer than since; a zero since
+// counts everything.
+func (s *ClickSvc) GetClicksGroupedByCountry(ctx context.Context, userID string, since time.Time) ([]model.GroupedMetric, error) {
 	query := `
 		SELECT COALESCE(country, 'unknown') AS label, COUNT(*)
 		FROM clicks c
 		JOIN links l ON c.link_id = l.id
 		WHERE l.user_id = $1
+		  AND ($2::timestamptz IS NULL OR c.created_at >= $2)
 		GROUP BY country;
 	`
-	rows, err := s.DB.QueryContext(ctx, query, userID)
+	rows, err := s.DB.QueryContext(ctx, query, userID, utils.NullTime(since))
 	if err != nil {
 		logger.Error("GetClicksGroupedByCountry: query failed: %v", err)
 		return nil, fmt.Errorf("GetClicksGroupedByCountry: query failed: %w", err)
@@ -207,3 +251,37 @@ func (s *ClickSvc) GetClicksGroupedByCountry(ctx context.Context, userID string)
 	}
 	return results, nil
 }
+
+// GetClicksGroupedBySource counts clicks newer than since by source, with
+// direct clicks labelled "direct"; a zero since counts everything.
+func (s *ClickSvc) GetClicksGroupedBySource(ctx context.Context, userID string, since time.Time) ([]model.GroupedMetric, error) {
+	query := `
+		SELECT COALESCE(source, 'direct') AS label, COUNT(*)
+		FROM clicks c
+		JOIN links l ON c.link_id = l.id
+		WHERE l.user_id = $1
+		  AND ($2::timestamptz IS NULL OR c.created_at >= $2)
+		GROUP BY source;
+	`
+	rows, err := s.DB.QueryContext(ctx, query, userID, utils.NullTime(since))
+	if err != nil {
+		logger.Error("GetClicksGroupedBySource: query failed: %v", err)
+		return nil, fmt.Errorf("GetClicksGroupedBySource: query failed: %w", err)
+	}
+	defer rows.Close()
+
+	var results []model.GroupedMetric = make([]model.GroupedMetric, 0)
+	for rows.Next() {
+		var g model.GroupedMetric
+		if err := rows.Scan(&g.Label, &g.Count); err != nil {
+			logger.Error("GetClicksGroupedBySource: scan failed: %v", err)
+			return nil, fmt.Errorf("GetClicksGroupedBySource: scan failed: %w", err)
+		}
+		results = append(results, g)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error("GetClicksGroupedBySource: row iteration failed: %v", err)
+		return nil, fmt.Errorf("GetClicksGroupedBySource: row iteration failed: %w", err)
+	}
+	return results, nil
+}
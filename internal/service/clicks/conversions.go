@@ -0,0 +1,159 @@
+package clicks
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"redo.ai/internal/model"
+	"redo.ai/internal/utils"
+	"redo.ai/logger"
+)
+
+var ErrClickNotFound = errors.New("click not found")
+
+// RecordConversion marks a click converted and stores the reported revenue.
+// Only the first report counts, so retries and duplicate pixels are safe.
+func (s *ClickSvc) RecordConversion(ctx context.Context, clickID string, revenueCents *int64) (model.Conversion, error) {
+	conv := model.Conversion{ClickID: clickID}
+	err := s.DB.QueryRowContext(ctx, `
+		UPDATE clicks SET conversion = TRUE, converted_at = now(), revenue_cents = $2
+		WHERE id = $1 AND NOT COALESCE(conversion, FALSE)
+		RETURNING link_id::text
+	`, clickID, revenueCents).Scan(&conv.LinkID)
+	if err == nil {
+		return conv, nil
+	} else if err != sql.ErrNoRows {
+		logger.Error("RecordConversion: update failed for click %s: %v", clickID, err)
+		return model.Conversion{}, fmt.Errorf("record conversion failed: %w", err)
+	}
+
+	err = s.DB.QueryRowContext(ctx, `SELECT link_id::text FROM clicks WHERE id = $1`, clickID).Scan(&conv.LinkID)
+	if err == sql.ErrNoRows {
+		return model.Conversion{}, ErrClickNotFound
+	} else if err != nil {
+		logger.Error("RecordConversion: lookup failed for click %s: %v", clickID, err)
+		return model.Conversion{}, fmt.Errorf("record conversion failed: %w", err)
+	}
+	conv.AlreadyConverted = true
+	return conv, nil
+}
+
+// ConversionSecret returns the user's conversion signing secret, creating it
+// on first use. rotate replaces it, invalidating the old one.
+func (s *ClickSvc) ConversionSecret(ctx context.Context, userID string, rotate bool) (string, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", fmt.Errorf("generate secret failed: %w", err)
+	}
+	fresh := hex.EncodeToString(key)
+	query := `UPDATE users SET conversion_secret = COALESCE(conversion_secret, $2) WHERE id = $1 RETURNING conversion_secret`
+	if rotate {
+		query = `UPDATE users SET conversion_secret = $2 WHERE id = $1 RETURNING conversion_secret`
+	}
+	var secret string
+	if err := s.DB.QueryRowContext(ctx, query, userID, fresh).Scan(&secret); err != nil {
+		logger.Error("ConversionSecret: failed for userID=%s: %v", userID, err)
+		return "", fmt.Errorf("conversion secret failed: %w", err)
+	}
+	return secret, nil
+}
+
+// ClickConversionSecret returns the conversion secret of the user owning the
+// click, or "" when they have none yet.
+func (s *ClickSvc) ClickConversionSecret(ctx context.Context, clickID string) (string, error) {
+	var secret string
+	err := s.DB.QueryRowContext(ctx, `
+		SELECT COALESCE(u.conversion_secret, '')
+		FROM clicks c
+		JOIN links l ON l.id = c.link_id
+		JOIN users u ON u.id = l.user_id
+		WHERE c.id = $1
+	`, clickID).Scan(&secret)
+	if err == sql.ErrNoRows {
+		return "", ErrClickNotFound
+	} else if err != nil {
+		logger.Error("ClickConversionSecret: lookup failed for click %s: %v", clickID, err)
+		return "", fmt.Errorf("load conversion secret failed: %w", err)
+	}
+	return secret, nil
+}
+
+// ConversionsByLink reports clicks, conversions and revenue for each of the
+// user's links with clicks since the given time; a zero since counts
+// everything.
+func (s *ClickSvc) ConversionsByLink(ctx context.Context, userID string, since time.Time) ([]model.LinkConversions, error) {
+	query := `
+		SELECT l.id::text, COALESCE(NULLIF(l.slug, ''), l.short_code),
+		       COUNT(*), COUNT(*) FILTER (WHERE c.conversion), COALESCE(SUM(c.revenue_cents), 0)
+		FROM clicks c
+		JOIN links l ON c.link_id = l.id
+		WHERE l.user_id = $1
+		  AND ($2::timestamptz IS NULL OR c.created_at >= $2)
+		GROUP BY l.id
+		ORDER BY 5 DESC, 4 DESC, 3 DESC;
+	`
+	rows, err := s.DB.QueryContext(ctx, query, userID, utils.NullTime(since))
+	if err != nil {
+		logger.Error("ConversionsByLink: query failed: %v", err)
+		return nil, fmt.Errorf("ConversionsByLink: query failed: %w", err)
+	}
+	defer rows.Close()
+
+	results := make([]model.LinkConversions, 0)
+	for rows.Next() {
+		var lc model.LinkConversions
+		if err := rows.Scan(&lc.LinkID, &lc.Label, &lc.Clicks, &lc.Conversions, &lc.RevenueCents); err != nil {
+			logger.Error("ConversionsByLink: scan failed: %v", err)
+			return nil, fmt.Errorf("ConversionsByLink: scan failed: %w", err)
+		}
+		lc.ConversionRate = conversionRate(lc.Conversions, lc.Clicks)
+		results = append(results, lc)
+	}
+	return results, rows.Err()
+}
+
+// ConversionsPerDay reports the daily funnel of the user's clicks since the
+// given time, optionally for one link. Days are UTC.
+func (s *ClickSvc) ConversionsPerDay(ctx context.Context, userID, linkID string, since time.Time) ([]model.ConversionsByDay, error) {
+	query := `
+		SELECT to_char((c.created_at AT TIME ZONE 'UTC')::date, 'YYYY-MM-DD') AS day,
+		       COUNT(*), COUNT(*) FILTER (WHERE c.conversion), COALESCE(SUM(c.revenue_cents), 0)
+		FROM clicks c
+		JOIN links l ON c.link_id = l.id
+		WHERE l.user_id = $1
+		  AND (NULLIF($2, '') IS NULL OR c.link_id = NULLIF($2, '')::uuid)
+		  AND ($3::timestamptz IS NULL OR c.created_at >= $3)
+		GROUP BY day
+		ORDER BY day;
+	`
+	rows, err := s.DB.QueryContext(ctx, query, userID, linkID, utils.NullTime(since))
+	if err != nil {
+		logger.Error("ConversionsPerDay: query failed: %v", err)
+		return nil, fmt.Errorf("ConversionsPerDay: query failed: %w", err)
+	}
+	defer rows.Close()
+
+	results := make([]model.ConversionsByDay, 0)
+	for rows.Next() {
+		var d model.ConversionsByDay
+		if err := rows.Scan(&d.DateLabel, &d.Clicks, &d.Conversions, &d.RevenueCents); err != nil {
+			logger.Error("ConversionsPerDay: scan failed: %v", err)
+			return nil, fmt.Errorf("ConversionsPerDay: scan failed: %w", err)
+		}
+		d.ConversionRate = conversionRate(d.Conversions, d.Clicks)
+		results = append(results, d)
+	}
+	return results, rows.Err()
+}
+
+func conversionRate(conversions, clicks int) float64 {
+	if clicks == 0 {
+		return 0
+	}
+	return float64(conversions) / float64(clicks)
+}
@@ -0,0 +1,187 @@
+package link
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"redo.ai/internal/model"
+	"redo.ai/internal/pkg/urlpolicy"
+	"redo.ai/internal/service/usage"
+	"redo.ai/logger"
+)
+
+// MaxBulkRows caps the number of rows in a single bulk operation.
+const MaxBulkRows = 10000
+
+// bulkRowFunc applies one row inside tx and returns the affected link id.
+type bulkRowFunc func(ctx context.Context, tx *sql.Tx, i int) (string, error)
+
+// bulkPrepFunc runs once inside the bulk transaction before any row.
+type bulkPrepFunc func(ctx context.Context, tx *sql.Tx) error
+
+// BulkCreateLinks inserts items in one transaction. Each row runs under its
+// own savepoint so a failed row does not poison the rest; when atomic is set
+// any failure rolls back the whole batch.
+func (s *LinkSvc) BulkCreateLinks(ctx context.Context, userID string, items []model.BulkCreateItem, atomic bool) (model.BulkResult, error) {
+	ent, err := s.checkLinkQuota(ctx, userID, len(items))
+	if err != nil {
+		return model.BulkResult{}, err
+	}
+	if s.Usage != nil {
+		if err := s.Usage.Check(ctx, userID, usage.MetricLinks, len(items)); err != nil {
+			return model.BulkResult{}, err
+		}
+	}
+
+	rows := make([]int, len(items))
+	destinations := make([]string, len(items))
+	for i, it := range items {
+		rows[i] = it.Row
+		destinations[i] = it.Destination
+	}
+	screened := s.screenDestinations(ctx, destinations)
+	lock := func(ctx context.Context, tx *sql.Tx) error {
+		return lockLinkQuota(ctx, tx, userID, ent, len(items))
+	}
+	result, err := s.runBulk(ctx, rows, atomic, false, lock, func(ctx context.Context, tx *sql.Tx, i int) (string, error) {
+		if err := checkSlugPolicy(ent, items[i].Slug); err != nil {
+			return "", err
+		}
+		if screened[i] != nil {
+			return "", screened[i]
+		}
+		lk, err := s.insertLink(ctx, tx, userID, items[i].CreateLinkRequest)
+		return lk.LinkID, err
+	})
+	if err != nil {
+		return result, err
+	}
+
+	if s.Usage != nil && result.Committed && result.Succeeded > 0 {
+		if err := s.Usage.Record(ctx, userID, usage.MetricLinks, result.Succeeded); err != nil {
+			logger.Warn("BulkCreateLinks: failed to meter %d links for userID=%s: %v", result.Succeeded, userID, err)
+		}
+	}
+	return result, nil
+}
+
+// BulkUpdateLinks applies partial updates with the same transaction semantics
+// as BulkCreateLinks.
+func (s *LinkSvc) BulkUpdateLinks(ctx context.Context, userID string, items []model.BulkUpdateItem, atomic bool) (model.BulkResult, error) {
+	ent, err := s.loadPlan(ctx, userID)
+	if err != nil {
+		return model.BulkResult{}, err
+	}
+	rows := make([]int, len(items))
+	destinations := make([]string, len(items))
+	for i, it := range items {
+		rows[i] = it.Row
+		if it.Destination != nil {
+			destinations[i] = *it.Destination
+		}
+	}
+	screened := s.screenDestinations(ctx, destinations)
+	return s.runBulk(ctx, rows, atomic, false, nil, func(ctx context.Context, tx *sql.Tx, i int) (string, error) {
+		if items[i].Slug != nil {
+			if err := checkSlugPolicy(ent, *items[i].Slug); err != nil {
+				return items[i].ID, err
+			}
+		}
+		if screened[i] != nil {
+			return items[i].ID, screened[i]
+		}
+		return items[i].ID, updateLink(ctx, tx, userID, items[i].ID, items[i].UpdateLinkRequest)
+	})
+}
+
+// BulkDeleteLinks deletes links with the same transaction semantics as
+// BulkCreateLinks.
+func (s *LinkSvc) BulkDeleteLinks(ctx context.Context, userID string, items []model.BulkDeleteItem, atomic bool) (model.BulkResult, error) {
+	rows := make([]int, len(items))
+	for i, it := range items {
+		rows[i] = it.Row
+	}
+	return s.runBulk(ctx, rows, atomic, false, nil, func(ctx context.Context, tx *sql.Tx, i int) (string, error) {
+		res, err := tx.ExecContext(ctx, `DELETE FROM links WHERE id = $1 AND user_id = $2`, items[i].ID, userID)
+		if err != nil {
+			return items[i].ID, fmt.Errorf("delete failed: %w", err)
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return items[i].ID, ErrLinkNotFound
+		}
+		return items[i].ID, nil
+	})
+}
+
+// runBulk applies fn to each row under its own savepoint, after prep when
+// it is set. A dry run is rolled back at the end and reports Committed false
+// with the row statuses a real run would have produced.
+func (s *LinkSvc) runBulk(ctx context.Context, rows []int, atomic, dryRun bool, prep bulkPrepFunc, fn bulkRowFunc) (model.BulkResult, error) {
+	result := model.BulkResult{Total: len(rows), Rows: make([]model.BulkRowResult, 0, len(rows))}
+
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return result, fmt.Errorf("begin tx failed: %w", err)
+	}
+	defer tx.Rollback()
+
+	if prep != nil {
+		if err := prep(ctx, tx); err != nil {
+			return result, err
+		}
+	}
+	for i, row := range rows {
+		if _, err := tx.ExecContext(ctx, `SAVEPOINT bulk_row`); err != nil {
+			return result, fmt.Errorf("savepoint failed: %w", err)
+		}
+		id, err := fn(ctx, tx, i)
+		if err != nil {
+			if _, rbErr := tx.ExecContext(ctx, `ROLLBACK TO SAVEPOINT bulk_row`); rbErr != nil {
+				return result, fmt.Errorf("rollback to savepoint failed: %w", rbErr)
+			}
+			result.Failed++
+			result.Rows = append(result.Rows, model.BulkRowResult{Row: row, LinkID: id, Status: model.BulkRowFailed, Error: bulkRowError(err)})
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, `RELEASE SAVEPOINT bulk_row`); err != nil {
+			return result, fmt.Errorf("release savepoint failed: %w", err)
+		}
+		result.Succeeded++
+		result.Rows = append(result.Rows, model.BulkRowResult{Row: row, LinkID: id, Status: model.BulkRowOK})
+	}
+
+	if atomic && result.Failed > 0 {
+		for i := range result.Rows {
+			if result.Rows[i].Status == model.BulkRowOK {
+				result.Rows[i].Status = model.BulkRowRolledBack
+			}
+		}
+		result.Succeeded = 0
+		return result, nil
+	}
+	if dryRun {
+		return result, nil
+	}
+	if err := tx.Commit(); err != nil {
+		return result, fmt.Errorf("commit failed: %w", err)
+	}
+	result.Committed = true
+	return result, nil
+}
+
+// bulkRowError turns a row failure into a message safe to show the caller.
+func bulkRowError(err error) string {
+	for _, known := range []error{ErrSlugAlreadyExists, ErrLinkNotFound, ErrCampaignNotFound, ErrShortCodeTaken, ErrSlugReserved, ErrSlugTooShort, ErrLinkDisabledByAdmin} {
+		if errors.Is(err, known) {
+			return known.Error()
+		}
+	}
+	var v *urlpolicy.Violation
+	if errors.As(err, &v) {
+		return v.Error()
+	}
+	logger.Error("bulk row failed: %v", err)
+	return "internal error"
+}
@@ -0,0 +1,115 @@
+package link
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"redo.ai/internal/model"
+	"redo.ai/internal/service/usage"
+	"redo.ai/logger"
+)
+
+// ImportLinks creates links parsed from another shortener's export. Rows
+// that fail are skipped and reported. Each row first tries its original
+// short code and falls back to a fresh one when the code is taken. With
+// dryRun set every row is attempted and then rolled back.
+func (s *LinkSvc) ImportLinks(ctx context.Context, userID, format string, items []model.ImportItem, dryRun bool) (model.ImportReport, error) {
+	report := model.ImportReport{Format: format, DryRun: dryRun, Total: len(items), Rows: make([]model.ImportRowResult, 0, len(items))}
+
+	ent, err := s.checkLinkQuota(ctx, userID, len(items))
+	if err != nil {
+		return report, err
+	}
+	if s.Usage != nil {
+		if err := s.Usage.Check(ctx, userID, usage.MetricLinks, len(items)); err != nil {
+			return report, err
+		}
+	}
+
+	rows := make([]int, len(items))
+	codes := make([]string, len(items))
+	destinations := make([]string, len(items))
+	for i, it := range items {
+		rows[i] = it.Row
+		destinations[i] = it.Destination
+	}
+	screened := s.screenDestinations(ctx, destinations)
+	lock := func(ctx context.Context, tx *sql.Tx) error {
+		return lockLinkQuota(ctx, tx, userID, ent, len(items))
+	}
+	result, err := s.runBulk(ctx, rows, false, dryRun, lock, func(ctx context.Context, tx *sql.Tx, i int) (string, error) {
+		if err := checkSlugPolicy(ent, items[i].Slug); err != nil {
+			return "", err
+		}
+		if screened[i] != nil {
+			return "", screened[i]
+		}
+		lk, err := s.importLink(ctx, tx, userID, items[i])
+		codes[i] = lk.ShortCode
+		return lk.LinkID, err
+	})
+	if err != nil {
+		return report, err
+	}
+
+	for i, row := range result.Rows {
+		it := items[i]
+		rr := model.ImportRowResult{
+			Row:               row.Row,
+			Status:            row.Status,
+			LinkID:            row.LinkID,
+			Destination:       it.Destination,
+			Slug:              it.Slug,
+			OriginalShortCode: it.OriginalShortCode,
+			Error:             row.Error,
+		}
+		if row.Status == model.BulkRowOK {
+			rr.ShortCode = codes[i]
+			rr.ShortCodePreserved = it.OriginalShortCode != "" && codes[i] == it.OriginalShortCode
+			if rr.ShortCodePreserved {
+				report.ShortCodesPreserved++
+			} else if it.OriginalShortCode != "" {
+				report.ShortCodesReissued++
+			}
+			if dryRun {
+				// The link was rolled back; its id means nothing.
+				rr.LinkID = ""
+			}
+		}
+		report.Rows = append(report.Rows, rr)
+	}
+	report.Imported = result.Succeeded
+	report.Failed = result.Failed
+
+	if s.Usage != nil && result.Committed && result.Succeeded > 0 {
+		if err := s.Usage.Record(ctx, userID, usage.MetricLinks, result.Succeeded); err != nil {
+			logger.Warn("ImportLinks: failed to meter %d links for userID=%s: %v", result.Succeeded, userID, err)
+		}
+	}
+	return report, nil
+}
+
+// importLink inserts one row, retrying without the original short code when
+// another link already owns it.
+func (s *LinkSvc) importLink(ctx context.Context, tx *sql.Tx, userID string, it model.ImportItem) (model.Link, error) {
+	req := it.CreateLinkRequest
+	if it.OriginalShortCode == "" {
+		return s.insertLink(ctx, tx, userID, req)
+	}
+
+	if _, err := tx.ExecContext(ctx, `SAVEPOINT import_code`); err != nil {
+		return model.Link{}, fmt.Errorf("savepoint failed: %w", err)
+	}
+	req.ShortCode = it.OriginalShortCode
+	lk, err := s.insertLink(ctx, tx, userID, req)
+	if err != ErrShortCodeTaken {
+		return lk, err
+	}
+	if _, err := tx.ExecContext(ctx, `ROLLBACK TO SAVEPOINT import_code`); err != nil {
+		return model.Link{}, fmt.Errorf("rollback to savepoint failed: %w", err)
+	}
+	logger.Info("ImportLinks: short code %s is taken, issuing a new one", it.OriginalShortCode)
+	req.ShortCode = ""
+	return s.insertLink(ctx, tx, userID, req)
+}
@@ -4,12 +4,19 @@ package link
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/lib/pq"
 	"redo.ai/internal/model"
+	"redo.ai/internal/pkg/entitlements"
+	"redo.ai/internal/pkg/ogmeta"
+	"redo.ai/internal/pkg/shortcode"
+	"redo.ai/internal/pkg/urlpolicy"
+	"redo.ai/internal/service/usage"
 	"redo.ai/internal/service/user"
 	"redo.ai/logger"
 )
@@ -18,130 +25,650 @@ import (
 type LinkService interface {
 	CreateLink(ctx context.Context, userID string, req model.CreateLinkRequest) (model.Link, error)
 	ListLinks(ctx context.Context, userID string) ([]model.Link, error)
-	ResolveLink(ctx context.Context, shortCode string) (string, string, error)
+	StreamLinks(ctx context.Context, userID string, fn func(model.Link) error) error
+	QueryLinks(ctx context.Context, userID string, q model.LinkQuery) (model.LinkPage, error)
+	SearchLinks(ctx context.Context, userID, term string, limit int) ([]model.LinkSearchResult, error)
+	GetLink(ctx context.Context, userID, linkID string) (model.Link, error)
+	UpdateLink(ctx context.Context, userID, linkID string, req model.UpdateLinkRequest) (model.Link, error)
+	BulkCreateLinks(ctx context.Context, userID string, items []model.BulkCreateItem, atomic bool) (model.BulkResult, error)
+	BulkUpdateLinks(ctx context.Context, userID string, items []model.BulkUpdateItem, atomic bool) (model.BulkResult, error)
+	BulkDeleteLinks(ctx context.Context, userID string, items []model.BulkDeleteItem, atomic bool) (model.BulkResult, error)
+	ImportLinks(ctx context.Context, userID, format string, items []model.ImportItem, dryRun bool) (model.ImportReport, error)
+	ResolveLink(ctx context.Context, shortCode string) (model.ResolvedLink, error)
+	CheckLinkPassword(ctx context.Context, linkID, password string) error
 	ResolveUserSlug(ctx context.Context, userID string, slug string) (model.Link, error)
-	TrackClick(ctx context.Context, shortCode, ip, referrer, userAgent string) error
+	CheckSlugAvailability(ctx context.Context, userID, slug string) (model.SlugAvailability, error)
+	TrackClick(ctx context.Context, ev model.ClickEvent) error
+	ListVariants(ctx context.Context, userID, linkID string) ([]model.LinkVariant, error)
+	SetVariants(ctx context.Context, userID, linkID string, inputs []model.LinkVariantInput) ([]model.LinkVariant, error)
+	VariantStats(ctx context.Context, userID, linkID string, since time.Time) ([]model.VariantStats, error)
+	GetRules(ctx context.Context, userID, linkID string) ([]model.RoutingRule, error)
+	SetRules(ctx context.Context, userID, linkID string, rules []model.RoutingRule) ([]model.RoutingRule, error)
+	ListSchedules(ctx context.Context, userID, linkID string) ([]model.LinkSchedule, error)
+	CreateSchedule(ctx context.Context, userID, linkID string, req model.CreateScheduleRequest) (model.LinkSchedule, error)
+	CancelSchedule(ctx context.Context, userID, linkID, scheduleID string) (model.LinkSchedule, error)
+	ApplyDueSchedules(ctx context.Context, now time.Time) (int, error)
 	//GetClickCount(ctx context.Context, shortCode string) (int, error)
 	DeleteLink(ctx context.Context, userID, linkID string) error
 }
 
 var ErrSlugAlreadyExists = errors.New("slug already exists")
 var ErrLinkNotFound = errors.New("link not found")
+var ErrLinkDisabled = errors.New("link disabled")
+var ErrCampaignNotFound = errors.New("campaign not found")
+var ErrShortCodeTaken = errors.New("short code already taken")
+var ErrWrongPassword = errors.New("wrong link password")
+
+// ErrLinkDisabledByAdmin is returned when an owner tries to re-enable a link
+// an admin disabled; only an admin can lift that.
+var ErrLinkDisabledByAdmin = errors.New("link was disabled by an admin")
+
+// maxShortCodeAttempts bounds how often a generated short code that collides
+// with an existing one is replaced before CreateLink gives up.
+const maxShortCodeAttempts = 5
+
+// clickCountExpr counts a link's clicks; it expects links aliased as l.
+const clickCountExpr = `(SELECT COUNT(*) FROM clicks c WHERE c.link_id = l.id)`
+
+// linkColumns is the select list scanned by scanLink; it expects links
+// aliased as l.
+const linkColumns = `l.id::text, l.slug, l.short_code, l.destination, COALESCE(l.title, ''),
+	l.created_at, COALESCE(l.is_active, TRUE), COALESCE(l.disabled_reason, ''),
+	COALESCE(l.campaign_id::text, ''), l.password_hash IS NOT NULL, l.append_click_id, l.activates_at,
+	COALESCE(l.og_title, ''), COALESCE(l.og_description, ''), COALESCE(l.og_image, ''), ` + tagNamesExpr + `, ` + clickCountExpr
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// querier is satisfied by both *sql.DB and *sql.Tx.
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// scanLink scans linkColumns followed by any extra columns into extra.
+func scanLink(row rowScanner, extra ...any) (model.Link, error) {
+	var (
+		link    model.Link
+		preview model.LinkPreview
+	)
+	dest := append([]any{&link.LinkID, &link.Slug, &link.ShortCode, &link.Destination, &link.Title,
+		&link.CreatedAt, &link.Is_active, &link.DisabledReason, &link.CampaignID,
+		&link.PasswordProtected, &link.AppendClickID, &link.ActivatesAt,
+		&preview.Title, &preview.Description, &preview.Image, pq.Array(&link.Tags), &link.ClickCount}, extra...)
+	err := row.Scan(dest...)
+	if link.Tags == nil {
+		link.Tags = []string{}
+	}
+	if !preview.IsZero() {
+		link.Preview = &preview
+	}
+	return link, err
+}
 
 type LinkSvc struct {
 	DB          *sql.DB
 	UserService user.UserService
+	Usage       usage.UsageService
+	// ShortCodes generates short codes; nil falls back to the column default.
+	ShortCodes shortcode.Generator
+	// Destinations screens destinations on create and update; nil skips it.
+	Destinations *urlpolicy.Policy
+	// Previews prefills share previews from the destination on create; nil
+	// skips it.
+	Previews ogmeta.Fetcher
 }
 
 func (s *LinkSvc) CreateLink(ctx context.Context, userID string, req model.CreateLinkRequest) (model.Link, error) {
+	ent, err := s.checkLinkQuota(ctx, userID, 1)
+	if err != nil {
+		return model.Link{}, err
+	}
+	if err := checkSlugPolicy(ent, req.Slug); err != nil {
+		return model.Link{}, err
+	}
+	if err := s.checkDestination(ctx, req.Destination); err != nil {
+		return model.Link{}, err
+	}
+	if s.Usage != nil {
+		if err := s.Usage.Check(ctx, userID, usage.MetricLinks, 1); err != nil {
+			return model.Link{}, err
+		}
+	}
+	s.prefillPreview(ctx, &req)
+
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return model.Link{}, fmt.Errorf("begin tx failed: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := lockLinkQuota(ctx, tx, userID, ent, 1); err != nil {
+		return model.Link{}, err
+	}
+	lk, err := s.insertLink(ctx, tx, userID, req)
+	if err != nil {
+		return model.Link{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return model.Link{}, fmt.Errorf("commit failed: %w", err)
+	}
+
+	if s.Usage != nil {
+		if err := s.Usage.Record(ctx, userID, usage.MetricLinks, 1); err != nil {
+			logger.Warn("CreateLink: failed to meter link for userID=%s: %v", userID, err)
+		}
+	}
+	return lk, nil
+}
+
+// insertLink writes one link and its tags inside tx. Unless the request
+// asks for a specific short code, a generated code that collides with an
+// existing one is replaced and the insert retried.
+func (s *LinkSvc) insertLink(ctx context.Context, tx *sql.Tx, userID string, req model.CreateLinkRequest) (model.Link, error) {
+	if req.Password != "" {
+		hash, err := hashLinkPassword(req.Password)
+		if err != nil {
+			return model.Link{}, err
+		}
+		req.Password = hash
+	}
+	if req.ShortCode != "" {
+		return insertLinkRow(ctx, tx, userID, req)
+	}
+	for attempt := 1; attempt <= maxShortCodeAttempts; attempt++ {
+		if s.ShortCodes != nil {
+			code, err := s.ShortCodes.Generate(ctx)
+			if err != nil {
+				logger.Error("CreateLink: failed to generate short code: %v", err)
+				return model.Link{}, fmt.Errorf("generate short code failed: %w", err)
+			}
+			req.ShortCode = code
+		}
+		if _, err := tx.ExecContext(ctx, `SAVEPOINT short_code`); err != nil {
+			return model.Link{}, fmt.Errorf("savepoint failed: %w", err)
+		}
+		lk, err := insertLinkRow(ctx, tx, userID, req)
+		if err != ErrShortCodeTaken {
+			if err == nil {
+				_, err = tx.ExecContext(ctx, `RELEASE SAVEPOINT short_code`)
+			}
+			return lk, err
+		}
+		if _, err := tx.ExecContext(ctx, `ROLLBACK TO SAVEPOINT short_code`); err != nil {
+			return model.Link{}, fmt.Errorf("rollback to savepoint failed: %w", err)
+		}
+		logger.Warn("CreateLink: short code collision for userID=%s (attempt %d)", userID, attempt)
+	}
+	return model.Link{}, fmt.Errorf("no free short code after %d attempts", maxShortCodeAttempts)
+}
+
+// insertLinkRow inserts the link row and its tags. req.Password must already
+// be hashed.
+func insertLinkRow(ctx context.Context, q querier, userID string, req model.CreateLinkRequest) (model.Link, error) {
 	query := `
-        INSERT INTO links (user_id, slug, destination, created_at)
-        VALUES ($1, $2, $3, $4)
+        INSERT INTO links (user_id, slug, destination, title, campaign_id, created_at, short_code,
+                           password_hash, password_updated_at, append_click_id, activates_at,
+                           og_title, og_description, og_image, og_fetched)
+        SELECT $1, $2, $3, NULLIF($4, ''), c.id, $6, COALESCE(NULLIF($7, ''), encode(gen_random_bytes(4), 'hex')),
+               NULLIF($8, ''), CASE WHEN $8 <> '' THEN now() END, $9, $10,
+               NULLIF($11, ''), NULLIF($12, ''), NULLIF($13, ''), $14
+        FROM (SELECT NULLIF($5, '')::uuid AS wanted) w
+        LEFT JOIN campaigns c ON c.id = w.wanted AND c.user_id = $1
+        WHERE w.wanted IS NULL OR c.id IS NOT NULL
         RETURNING id, short_code, created_at, is_active
     `
 	var (
 		id, shortCode string
 		createdAt     time.Time
 		isactive      bool
+		preview       model.LinkPreview
 	)
+	if req.Preview != nil {
+		preview = *req.Preview
+	}
 
-	err := s.DB.QueryRowContext(
+	err := q.QueryRowContext(
 		ctx,
 		query,
 		userID,
 		req.Slug,
 		req.Destination,
+		req.Title,
+		req.CampaignID,
 		time.Now().UTC(),
+		req.ShortCode,
+		req.Password,
+		req.AppendClickID,
+		req.ActivatesAt,
+		preview.Title,
+		preview.Description,
+		preview.Image,
+		req.PreviewFetched,
 	).Scan(&id, &shortCode, &createdAt, &isactive)
 
-	if err != nil {
+	if err == sql.ErrNoRows {
+		logger.Warn("CreateLink: campaign %s not found for userID=%s", req.CampaignID, userID)
+		return model.Link{}, ErrCampaignNotFound
+	} else if err != nil {
 		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
-			if pqErr.Constraint == "unique_user_slug" {
+			if pqErr.Constraint == slugUniqueIndex {
 				logger.Error("CreateLink: duplicate slug for userID=%s: %s", userID, req.Slug)
 				return model.Link{}, ErrSlugAlreadyExists
 			}
+			if pqErr.Constraint == "links_short_code_key" {
+				return model.Link{}, ErrShortCodeTaken
+			}
 		}
 		logger.Error("CreateLink: failed to insert link: %v", err)
 		return model.Link{}, fmt.Errorf("create link failed: %w", err)
 	}
 
+	tags, err := setLinkTags(ctx, q, userID, id, req.Tags)
+	if err != nil {
+		return model.Link{}, err
+	}
+
 	return model.Link{
 		LinkID:      id,
 		Slug:        req.Slug,
 		ShortCode:   shortCode,
 		Destination: req.Destination,
+		Title:       req.Title,
+		Tags:        tags,
+		CampaignID:  req.CampaignID,
 		Is_active:   isactive,
 		CreatedAt:   createdAt.Format(time.RFC3339Nano),
+
+		PasswordProtected: req.Password != "",
+		AppendClickID:     req.AppendClickID,
+		ActivatesAt:       req.ActivatesAt,
+		Preview:           previewOrNil(preview),
 	}, nil
 }
 
+// checkDestination runs the destination policy. A check that could not run,
+// such as an unreachable threat-intel provider, is logged and lets the
+// destination through rather than blocking every link.
+func (s *LinkSvc) checkDestination(ctx context.Context, destination string) error {
+	return checkDestination(ctx, s.Destinations, destination)
+}
+
+func checkDestination(ctx context.Context, policy *urlpolicy.Policy, destination string) error {
+	if policy == nil {
+		return nil
+	}
+	err := policy.Check(ctx, destination)
+	var v *urlpolicy.Violation
+	if errors.As(err, &v) {
+		logger.Warn("checkDestination: rejected %s: %v", destination, err)
+		return err
+	} else if err != nil {
+		logger.Warn("checkDestination: could not screen %s: %v", destination, err)
+	}
+	return nil
+}
+
+// screenWorkers bounds how many destinations a batch screens at once.
+const screenWorkers = 8
+
+// screenDestinations runs checkDestination for each destination before a
+// batch opens its transaction, so network lookups do not hold it open.
+// Duplicate destinations are screened once and each host is resolved once.
+func (s *LinkSvc) screenDestinations(ctx context.Context, destinations []string) []error {
+	errs := make([]error, len(destinations))
+	if s.Destinations == nil {
+		return errs
+	}
+	policy := s.Destinations.ForBatch()
+
+	byURL := make(map[string][]int)
+	var unique []string
+	for i, d := range destinations {
+		if d == "" {
+			continue
+		}
+		if _, ok := byURL[d]; !ok {
+			unique = append(unique, d)
+		}
+		byURL[d] = append(byURL[d], i)
+	}
+
+	results := make([]error, len(unique))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < screenWorkers && w < len(unique); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range next {
+				results[j] = checkDestination(ctx, policy, unique[j])
+			}
+		}()
+	}
+	for j := range unique {
+		next <- j
+	}
+	close(next)
+	wg.Wait()
+
+	for j, d := range unique {
+		for _, i := range byURL[d] {
+			errs[i] = results[j]
+		}
+	}
+	return errs
+}
+
+// checkLinkQuota verifies the user's plan allows n more links and returns
+// the plan's entitlements. It fails fast before any work is done; the count
+// is only authoritative once lockLinkQuota has run in the inserting
+// transaction.
+func (s *LinkSvc) checkLinkQuota(ctx context.Context, userID string, n int) (entitlements.Entitlements, error) {
+	var (
+		role string
+		used int
+	)
+	query := `
+		SELECT u.role::text, (SELECT COUNT(*) FROM links WHERE user_id = u.id)
+		FROM users u
+		WHERE u.id = $1
+	`
+	if err := s.DB.QueryRowContext(ctx, query, userID).Scan(&role, &used); err != nil {
+		logger.Error("checkLinkQuota: failed to load plan for userID=%s: %v", userID, err)
+		return entitlements.Entitlements{}, fmt.Errorf("load plan failed: %w", err)
+	}
+	ent := entitlements.For(role)
+	if err := ent.CheckQuota(entitlements.LimitLinks, used, n); err != nil {
+		logger.Warn("checkLinkQuota: userID=%s: %v", userID, err)
+		return ent, err
+	}
+	return ent, nil
+}
+
+// lockLinkQuota re-checks the link quota inside tx while holding a lock on
+// the user's row, so concurrent creates for one user are serialized until
+// tx ends and cannot both pass the count.
+func lockLinkQuota(ctx context.Context, tx *sql.Tx, userID string, ent entitlements.Entitlements, n int) error {
+	if ent.Quota(entitlements.LimitLinks) == entitlements.Unlimited {
+		return nil
+	}
+	if _, err := tx.ExecContext(ctx, `SELECT 1 FROM users WHERE id = $1 FOR NO KEY UPDATE`, userID); err != nil {
+		logger.Error("lockLinkQuota: lock failed for userID=%s: %v", userID, err)
+		return fmt.Errorf("lock user failed: %w", err)
+	}
+	// A separate statement, so the count sees links committed by whoever
+	// held the lock before us.
+	var used int
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM links WHERE user_id = $1`, userID).Scan(&used); err != nil {
+		logger.Error("lockLinkQuota: count failed for userID=%s: %v", userID, err)
+		return fmt.Errorf("count links failed: %w", err)
+	}
+	if err := ent.CheckQuota(entitlements.LimitLinks, used, n); err != nil {
+		logger.Warn("lockLinkQuota: userID=%s: %v", userID, err)
+		return err
+	}
+	return nil
+}
+
 func (s *LinkSvc) ListLinks(ctx context.Context, userID string) ([]model.Link, error) {
 	var links []model.Link = make([]model.Link, 0)
+	err := s.StreamLinks(ctx, userID, func(link model.Link) error {
+		links = append(links, link)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return links, nil
+}
 
+// StreamLinks calls fn for each of the user's links, newest first, without
+// buffering the result set. An error from fn stops the iteration and is
+// returned as is.
+func (s *LinkSvc) StreamLinks(ctx context.Context, userID string, fn func(model.Link) error) error {
 	query := `
-    SELECT l.id::text, l.slug, l.short_code, l.destination, l.created_at, l.is_active,
-	COUNT(c.id) AS click_count
+    SELECT ` + linkColumns + `
     FROM links l
-    LEFT JOIN clicks c ON c.link_id = l.id
     WHERE l.user_id = $1
-    GROUP BY l.id
     ORDER BY l.created_at DESC
 	`
 
 	rows, err := s.DB.QueryContext(ctx, query, userID)
 	if err != nil {
-		logger.Error("ListLinks: query failed: %v", err)
-		return links, fmt.Errorf("query failed: %w", err)
+		logger.Error("StreamLinks: query failed: %v", err)
+		return fmt.Errorf("query failed: %w", err)
 	}
 	defer rows.Close()
 
 	for rows.Next() {
-		var link model.Link
-		if err := rows.Scan(&link.LinkID, &link.Slug, &link.ShortCode, &link.Destination, &link.CreatedAt, &link.Is_active, &link.ClickCount); err != nil {
-			logger.Error("ListLinks: row scan failed: %v", err)
-			return links, fmt.Errorf("scan failed: %w", err)
+		link, err := scanLink(rows)
+		if err != nil {
+			logger.Error("StreamLinks: row scan failed: %v", err)
+			return fmt.Errorf("scan failed: %w", err)
+		}
+		if err := fn(link); err != nil {
+			return err
 		}
-		links = append(links, link)
 	}
 
 	if err := rows.Err(); err != nil {
-		logger.Error("ListLinks: rows iteration error: %v", err)
-		return nil, fmt.Errorf("rows error: %w", err)
+		logger.Error("StreamLinks: rows iteration error: %v", err)
+		return fmt.Errorf("rows error: %w", err)
 	}
+	return nil
+}
 
-	return links, nil
+// GetLink loads a single link owned by the user.
+func (s *LinkSvc) GetLink(ctx context.Context, userID, linkID string) (model.Link, error) {
+	query := `
+    SELECT ` + linkColumns + `
+    FROM links l
+    WHERE l.id = $1 AND l.user_id = $2
+	`
+	link, err := scanLink(s.DB.QueryRowContext(ctx, query, linkID, userID))
+	if err == sql.ErrNoRows {
+		return model.Link{}, ErrLinkNotFound
+	} else if err != nil {
+		logger.Error("GetLink: DB error: %v", err)
+		return model.Link{}, fmt.Errorf("get link failed: %w", err)
+	}
+	return link, nil
 }
 
-func (s *LinkSvc) ResolveLink(ctx context.Context, shortCode string) (string, string, error) {
+// UpdateLink applies a partial update and keeps the previous destination in
+// link_revisions when it changes.
+func (s *LinkSvc) UpdateLink(ctx context.Context, userID, linkID string, req model.UpdateLinkRequest) (model.Link, error) {
+	if req.Slug != nil {
+		ent, err := s.loadPlan(ctx, userID)
+		if err != nil {
+			return model.Link{}, err
+		}
+		if err := checkSlugPolicy(ent, *req.Slug); err != nil {
+			return model.Link{}, err
+		}
+	}
+
+	if req.Destination != nil {
+		if err := s.checkDestination(ctx, *req.Destination); err != nil {
+			return model.Link{}, err
+		}
+	}
+
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return model.Link{}, fmt.Errorf("begin tx failed: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := updateLink(ctx, tx, userID, linkID, req); err != nil {
+		return model.Link{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return model.Link{}, fmt.Errorf("commit failed: %w", err)
+	}
+	return s.GetLink(ctx, userID, linkID)
+}
+
+// updateLink applies req inside the open transaction tx.
+func updateLink(ctx context.Context, tx querier, userID, linkID string, req model.UpdateLinkRequest) error {
 	var (
-		linkID      string
-		destination string
+		previous      string
+		adminDisabled bool
 	)
+	err := tx.QueryRowContext(ctx, `
+		SELECT destination, disabled_reason IS NOT NULL
+		FROM links WHERE id = $1 AND user_id = $2 FOR UPDATE
+	`, linkID, userID).Scan(&previous, &adminDisabled)
+	if err == sql.ErrNoRows {
+		logger.Warn("UpdateLink: link not found or access denied for linkID=%s, userID=%s", linkID, userID)
+		return ErrLinkNotFound
+	} else if err != nil {
+		logger.Error("UpdateLink: DB error: %v", err)
+		return fmt.Errorf("load link failed: %w", err)
+	}
+	if adminDisabled && req.IsActive != nil && *req.IsActive {
+		logger.Warn("UpdateLink: owner %s tried to re-enable admin-disabled linkID=%s", userID, linkID)
+		return ErrLinkDisabledByAdmin
+	}
 
-	query := `SELECT id::text, destination FROM links WHERE short_code = $1`
-	err := s.DB.QueryRowContext(ctx, query, shortCode).Scan(&linkID, &destination)
+	query := `
+        UPDATE links
+        SET slug = COALESCE($3, slug),
+            destination = COALESCE($4, destination),
+            is_active = COALESCE($5, is_active),
+            title = COALESCE($6, title),
+            append_click_id = COALESCE($7, append_click_id),
+            activates_at = CASE WHEN $9 THEN $8::timestamptz ELSE activates_at END,
+            updated_at = now()
+        WHERE id = $1 AND user_id = $2
+    `
+	if _, err := tx.ExecContext(ctx, query, linkID, userID, req.Slug, req.Destination, req.IsActive, req.Title, req.AppendClickID, req.ActivatesAt.Time, req.ActivatesAt.Set); err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" && pqErr.Constraint == slugUniqueIndex {
+			return ErrSlugAlreadyExists
+		}
+		logger.Error("UpdateLink: update failed for linkID=%s: %v", linkID, err)
+		return fmt.Errorf("update link failed: %w", err)
+	}
+
+	if req.CampaignID != nil {
+		res, err := tx.ExecContext(ctx, `
+			UPDATE links SET campaign_id = c.id
+			FROM (SELECT NULLIF($3, '')::uuid AS wanted) w
+			LEFT JOIN campaigns c ON c.id = w.wanted AND c.user_id = $2
+			WHERE links.id = $1 AND (w.wanted IS NULL OR c.id IS NOT NULL)
+		`, linkID, userID, *req.CampaignID)
+		if err != nil {
+			logger.Error("UpdateLink: failed to set campaign for linkID=%s: %v", linkID, err)
+			return fmt.Errorf("set campaign failed: %w", err)
+		}
+		if rows, _ := res.RowsAffected(); rows == 0 {
+			return ErrCampaignNotFound
+		}
+	}
+	if req.Tags != nil {
+		if _, err := setLinkTags(ctx, tx, userID, linkID, *req.Tags); err != nil {
+			return err
+		}
+	}
+	if req.Preview != nil {
+		query := `UPDATE links SET og_title = NULLIF($2, ''), og_description = NULLIF($3, ''), og_image = NULLIF($4, ''), og_fetched = FALSE WHERE id = $1`
+		if _, err := tx.ExecContext(ctx, query, linkID, req.Preview.Title, req.Preview.Description, req.Preview.Image); err != nil {
+			logger.Error("UpdateLink: failed to set preview for linkID=%s: %v", linkID, err)
+			return fmt.Errorf("set preview failed: %w", err)
+		}
+	}
+	if req.Password != nil {
+		hash := ""
+		if *req.Password != "" {
+			if hash, err = hashLinkPassword(*req.Password); err != nil {
+				return err
+			}
+		}
+		query := `UPDATE links SET password_hash = NULLIF($2, ''), password_updated_at = now() WHERE id = $1`
+		if _, err := tx.ExecContext(ctx, query, linkID, hash); err != nil {
+			logger.Error("UpdateLink: failed to set password for linkID=%s: %v", linkID, err)
+			return fmt.Errorf("set password failed: %w", err)
+		}
+	}
+
+	if req.Destination != nil && *req.Destination != previous {
+		revision := `INSERT INTO link_revisions (link_id, previous_destination) VALUES ($1, $2)`
+		if _, err := tx.ExecContext(ctx, revision, linkID, previous); err != nil {
+			logger.Error("UpdateLink: failed to record revision for linkID=%s: %v", linkID, err)
+			return fmt.Errorf("record revision failed: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (s *LinkSvc) ResolveLink(ctx context.Context, shortCode string) (model.ResolvedLink, error) {
+	var (
+		rl              model.ResolvedLink
+		active          bool
+		passwordUpdated sql.NullTime
+		activatesAt     sql.NullTime
+		variants        []byte
+		rules           []byte
+	)
+
+	query := `
+		SELECT l.id::text, l.short_code, l.destination, COALESCE(l.is_active, TRUE),
+		       l.password_hash IS NOT NULL, l.password_updated_at, l.append_click_id, l.routing_rules,
+		       CASE WHEN l.activates_at > now() THEN l.activates_at END,
+		       COALESCE(p.og_title, ''), COALESCE(p.og_description, ''), COALESCE(p.og_image, ''),
+		       (SELECT json_agg(json_build_object('id', v.id, 'destination', v.destination, 'weight', v.weight)
+		                        ORDER BY v.created_at, v.id)
+		        FROM link_variants v WHERE v.link_id = l.id)
+		FROM links l
+		-- A protected link only keeps a card its owner wrote; one fetched
+		-- from the destination would reveal it.
+		LEFT JOIN LATERAL (SELECT l.og_title, l.og_description, l.og_image
+		                   WHERE l.password_hash IS NULL OR NOT l.og_fetched) p ON TRUE
+		WHERE l.short_code = $1
+	`
+	err := s.DB.QueryRowContext(ctx, query, shortCode).Scan(&rl.ID, &rl.ShortCode, &rl.Destination, &active,
+		&rl.PasswordProtected, &passwordUpdated, &rl.AppendClickID, &rules, &activatesAt,
+		&rl.Preview.Title, &rl.Preview.Description, &rl.Preview.Image, &variants)
 	if err == sql.ErrNoRows {
 		logger.Warn("ResolveLink: short_code not found: %s", shortCode)
-		return "", "", ErrLinkNotFound
+		return model.ResolvedLink{}, ErrLinkNotFound
 	} else if err != nil {
 		logger.Error("ResolveLink: DB error: %v", err)
-		return "", "", fmt.Errorf("resolve failed: %w", err)
+		return model.ResolvedLink{}, fmt.Errorf("resolve failed: %w", err)
+	}
+	if !active {
+		logger.Warn("ResolveLink: short_code disabled: %s", shortCode)
+		return model.ResolvedLink{ID: rl.ID}, ErrLinkDisabled
+	}
+	rl.PasswordUpdatedAt = passwordUpdated.Time
+	if activatesAt.Valid {
+		rl.ActivatesAt = &activatesAt.Time
+	}
+	if rl.Rules, err = decodeRules(rules); err != nil {
+		logger.Error("ResolveLink: bad rules for %s: %v", shortCode, err)
+		return model.ResolvedLink{}, fmt.Errorf("resolve failed: %w", err)
+	}
+	if variants != nil {
+		if err := json.Unmarshal(variants, &rl.Variants); err != nil {
+			logger.Error("ResolveLink: bad variants for %s: %v", shortCode, err)
+			return model.ResolvedLink{}, fmt.Errorf("resolve failed: %w", err)
+		}
 	}
 
-	return destination, linkID, nil
+	return rl, nil
 }
 
 func (s *LinkSvc) ResolveUserSlug(ctx context.Context, userID, slug string) (model.Link, error) {
-	var link model.Link
-
 	query := `
-        SELECT id::text, slug, short_code, destination, created_at
-        FROM links
-        WHERE user_id = $1 AND slug = $2
+        SELECT ` + linkColumns + `
+        FROM links l
+        WHERE l.user_id = $1 AND lower(l.slug) = lower($2) AND l.slug <> ''
     `
-	err := s.DB.QueryRowContext(ctx, query, userID, slug).Scan(
-		&link.LinkID, &link.Slug, &link.ShortCode, &link.Destination, &link.CreatedAt,
-	)
+	link, err := scanLink(s.DB.QueryRowContext(ctx, query, userID, slug))
 	if err == sql.ErrNoRows {
 		logger.Warn("ResolveUserSlug: slug not found for userID=%s: %s", userID, slug)
 		return model.Link{}, ErrLinkNotFound
@@ -153,21 +680,42 @@ func (s *LinkSvc) ResolveUserSlug(ctx context.Context, userID, slug string) (mod
 	return link, nil
 }
 
-func (s *LinkSvc) TrackClick(ctx context.Context, shortCode, ip, referrer, userAgent string) error {
+// TrackClick records a click unless the owner has exhausted a hard monthly
+// click quota, in which case the visitor is still redirected but the click is
+// not stored.
+func (s *LinkSvc) TrackClick(ctx context.Context, ev model.ClickEvent) error {
+	shortCode := ev.ShortCode
+	var linkID, ownerID string
+	err := s.DB.QueryRowContext(ctx, `SELECT id::text, user_id::text FROM links WHERE short_code = $1 AND COALESCE(is_active, TRUE)`, shortCode).Scan(&linkID, &ownerID)
+	if err == sql.ErrNoRows {
+		logger.Warn("TrackClick: no link found for short_code=%s", shortCode)
+		return ErrLinkNotFound
+	} else if err != nil {
+		logger.Error("TrackClick: failed to load link: %v", err)
+		return fmt.Errorf("track click failed: %w", err)
+	}
+
+	if s.Usage != nil {
+		if err := s.Usage.Check(ctx, ownerID, usage.MetricClicks, 1); err != nil {
+			logger.Warn("TrackClick: not tracking click for short_code=%s: %v", shortCode, err)
+			return err
+		}
+	}
+
 	query := `
-		INSERT INTO clicks (id, link_id, ip, referrer, user_agent, created_at)
-		SELECT gen_random_uuid(), id, $2, $3, $4, now()
-		FROM links
-		WHERE short_code = $1
+		INSERT INTO clicks (id, link_id, ip, referrer, user_agent, source, variant_id, created_at)
+		VALUES (COALESCE(NULLIF($7, '')::uuid, gen_random_uuid()), $1, $2, $3, $4, NULLIF($5, ''),
+		        (SELECT id FROM link_variants WHERE id = NULLIF($6, '')::uuid AND link_id = $1), now())
 	`
-	res, err := s.DB.ExecContext(ctx, query, shortCode, ip, referrer, userAgent)
-	if err != nil {
+	if _, err := s.DB.ExecContext(ctx, query, linkID, ev.IP, ev.Referrer, ev.UserAgent, ev.Source, ev.VariantID, ev.ID); err != nil {
 		logger.Error("TrackClick: failed to insert click: %v", err)
 		return fmt.Errorf("track click failed: %w", err)
 	}
-	if rows, _ := res.RowsAffected(); rows == 0 {
-		logger.Warn("TrackClick: no link found for short_code=%s", shortCode)
-		return ErrLinkNotFound
+
+	if s.Usage != nil {
+		if err := s.Usage.Record(ctx, ownerID, usage.MetricClicks, 1); err != nil {
+			logger.Warn("TrackClick: failed to meter click for short_code=%s: %v", shortCode, err)
+		}
 	}
 	return nil
 }
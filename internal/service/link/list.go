@@ -0,0 +1,212 @@
+package link
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"redo.ai/internal/model"
+	"redo.ai/internal/pkg/cursor"
+	"redo.ai/internal/utils"
+	"redo.ai/logger"
+)
+
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 500
+)
+
+// Sort keys accepted by QueryLinks.
+const (
+	SortCreated = "created"
+	SortClicks  = "clicks"
+	SortSlug    = "slug"
+)
+
+var ErrInvalidQuery = errors.New("invalid link query")
+
+// sortColumns maps a sort key to the expression it orders by and the cast
+// applied to cursor values. buildLinkQuery bounds the clicks count to the
+// listing's snapshot.
+var sortColumns = map[string]struct{ expr, cast string }{
+	SortCreated: {"l.created_at", "timestamptz"},
+	SortClicks:  {clickCountExpr, "bigint"},
+	SortSlug:    {"l.slug", "text"},
+}
+
+type linkCursor struct {
+	Sort  string `json:"s"`
+	Order string `json:"o"`
+	Value string `json:"v"`
+	ID    string `json:"id"`
+	// AsOf is the snapshot a clicks sort counts up to; see linkQuery.
+	AsOf time.Time `json:"at,omitempty"`
+}
+
+// clicksAsOfExpr counts a link's clicks up to a bound; it expects links
+// aliased as l and takes the bound's placeholder index.
+const clicksAsOfExpr = `(SELECT COUNT(*) FROM clicks c WHERE c.link_id = l.id AND c.created_at <= $%d)`
+
+// linkQuery is one page of a listing ready to run.
+type linkQuery struct {
+	model.LinkQuery
+	// asOf bounds the clicks a clicks sort counts. It is fixed on the first
+	// page and carried in the cursor, so clicks arriving between pages
+	// cannot reorder links into pages already served.
+	asOf time.Time
+	sql  string
+	args []any
+}
+
+// linkFilter accumulates WHERE clauses and their positional arguments.
+type linkFilter struct {
+	where []string
+	args  []any
+}
+
+func (f *linkFilter) add(cond string, vals ...any) {
+	idx := make([]any, len(vals))
+	for i, v := range vals {
+		f.args = append(f.args, v)
+		idx[i] = len(f.args)
+	}
+	f.where = append(f.where, fmt.Sprintf(cond, idx...))
+}
+
+// QueryLinks returns one keyset-paginated page of a user's links.
+func (s *LinkSvc) QueryLinks(ctx context.Context, userID string, q model.LinkQuery) (model.LinkPage, error) {
+	lq, err := buildLinkQuery(userID, q, time.Now().UTC())
+	if err != nil {
+		return model.LinkPage{}, err
+	}
+
+	rows, err := s.DB.QueryContext(ctx, lq.sql, lq.args...)
+	if err != nil {
+		logger.Error("QueryLinks: query failed: %v", err)
+		return model.LinkPage{}, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	page := model.LinkPage{Links: make([]model.Link, 0, lq.Limit)}
+	for rows.Next() {
+		link, err := scanLink(rows)
+		if err != nil {
+			logger.Error("QueryLinks: row scan failed: %v", err)
+			return model.LinkPage{}, fmt.Errorf("scan failed: %w", err)
+		}
+		page.Links = append(page.Links, link)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error("QueryLinks: rows iteration error: %v", err)
+		return model.LinkPage{}, fmt.Errorf("rows error: %w", err)
+	}
+
+	if len(page.Links) > lq.Limit {
+		page.Links = page.Links[:lq.Limit]
+		page.NextCursor = nextCursor(lq, page.Links[lq.Limit-1])
+	}
+	return page, nil
+}
+
+// buildLinkQuery fills in q's defaults and builds the SQL for one page. It
+// fetches Limit+1 rows so the caller can tell whether another page follows.
+// A clicks sort without a cursor counts clicks up to now.
+func buildLinkQuery(userID string, q model.LinkQuery, now time.Time) (linkQuery, error) {
+	if q.Sort == "" {
+		q.Sort = SortCreated
+	}
+	if q.Order == "" {
+		q.Order = "desc"
+	}
+	col, ok := sortColumns[q.Sort]
+	if !ok || (q.Order != "asc" && q.Order != "desc") {
+		return linkQuery{}, ErrInvalidQuery
+	}
+	if q.Limit <= 0 || q.Limit > MaxPageSize {
+		q.Limit = DefaultPageSize
+	}
+
+	f := &linkFilter{}
+	f.add("l.user_id = $%d", userID)
+	if q.Active != nil {
+		f.add("COALESCE(l.is_active, TRUE) = $%d", *q.Active)
+	}
+	if !q.CreatedFrom.IsZero() {
+		f.add("l.created_at >= $%d", q.CreatedFrom)
+	}
+	if !q.CreatedTo.IsZero() {
+		f.add("l.created_at < $%d", q.CreatedTo)
+	}
+	if q.Domain != "" {
+		f.add("d.domain = $%d", strings.ToLower(q.Domain))
+	}
+	if q.Tag != "" {
+		f.add(`EXISTS (SELECT 1 FROM link_tags lt JOIN tags t ON t.id = lt.tag_id
+			WHERE lt.link_id = l.id AND t.name = $%d)`, NormalizeTag(q.Tag))
+	}
+	if q.CampaignID != "" {
+		f.add("l.campaign_id = $%d", q.CampaignID)
+	}
+	if q.Search != "" {
+		f.add("(l.slug ILIKE $%[1]d OR l.destination ILIKE $%[1]d OR l.title ILIKE $%[1]d)", utils.LikePattern(q.Search))
+	}
+
+	cmp := "<"
+	if q.Order == "asc" {
+		cmp = ">"
+	}
+	var c linkCursor
+	if q.Cursor != "" {
+		if err := cursor.Decode(q.Cursor, &c); err != nil || c.Sort != q.Sort || c.Order != q.Order {
+			return linkQuery{}, ErrInvalidQuery
+		}
+	}
+	lq := linkQuery{LinkQuery: q}
+	columns := linkColumns
+	if q.Sort == SortClicks {
+		lq.asOf = now
+		if q.Cursor != "" {
+			if c.AsOf.IsZero() {
+				return linkQuery{}, ErrInvalidQuery
+			}
+			lq.asOf = c.AsOf
+		}
+		f.args = append(f.args, lq.asOf)
+		col.expr = fmt.Sprintf(clicksAsOfExpr, len(f.args))
+		// The counts shown match the ones ordered by.
+		columns = strings.TrimSuffix(linkColumns, clickCountExpr) + col.expr
+	}
+	if q.Cursor != "" {
+		f.add(fmt.Sprintf("(%s, l.id) %s ($%%d::%s, $%%d::uuid)", col.expr, cmp, col.cast), c.Value, c.ID)
+	}
+	f.args = append(f.args, q.Limit+1)
+
+	lq.sql = fmt.Sprintf(`
+		SELECT %s
+		FROM links l
+		LEFT JOIN custom_domains d ON d.id = l.custom_domain_id
+		WHERE %s
+		ORDER BY %s %s, l.id %s
+		LIMIT $%d
+	`, columns, strings.Join(f.where, " AND "), col.expr, q.Order, q.Order, len(f.args))
+	lq.args = f.args
+	return lq, nil
+}
+
+// nextCursor returns the cursor that resumes q after last.
+func nextCursor(q linkQuery, last model.Link) string {
+	c := linkCursor{Sort: q.Sort, Order: q.Order, ID: last.LinkID, AsOf: q.asOf}
+	switch q.Sort {
+	case SortCreated:
+		c.Value = last.CreatedAt
+	case SortClicks:
+		c.Value = strconv.Itoa(last.ClickCount)
+	case SortSlug:
+		c.Value = last.Slug
+	}
+	token, _ := cursor.Encode(c)
+	return token
+}
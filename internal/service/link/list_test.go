@@ -0,0 +1,135 @@
+package link
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"redo.ai/internal/model"
+	"redo.ai/internal/pkg/cursor"
+)
+
+var testNow = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func TestBuildLinkQueryDefaults(t *testing.T) {
+	q, err := buildLinkQuery("user-1", model.LinkQuery{Limit: 10000}, testNow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query, args := q.sql, q.args
+	if q.Sort != SortCreated || q.Order != "desc" || q.Limit != DefaultPageSize {
+		t.Errorf("unexpected defaults: %+v", q)
+	}
+	if !strings.Contains(query, "WHERE l.user_id = $1") || !strings.Contains(query, "ORDER BY l.created_at desc, l.id desc") {
+		t.Errorf("unexpected query:\n%s", query)
+	}
+	if len(args) != 2 || args[0] != "user-1" || args[1] != DefaultPageSize+1 {
+		t.Errorf("unexpected args: %v", args)
+	}
+}
+
+func TestBuildLinkQueryFilters(t *testing.T) {
+	active := false
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	lq, err := buildLinkQuery("user-1", model.LinkQuery{
+		Limit:       20,
+		Active:      &active,
+		CreatedFrom: from,
+		Domain:      "Go.Example.COM",
+		Search:      "50%_off",
+		Sort:        SortSlug,
+		Order:       "asc",
+	}, testNow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query, args := lq.sql, lq.args
+	for _, want := range []string{
+		"COALESCE(l.is_active, TRUE) = $2",
+		"l.created_at >= $3",
+		"d.domain = $4",
+		"(l.slug ILIKE $5 OR l.destination ILIKE $5 OR l.title ILIKE $5)",
+		"ORDER BY l.slug asc, l.id asc",
+		"LIMIT $6",
+	} {
+		if !strings.Contains(query, want) {
+			t.Errorf("query is missing %q:\n%s", want, query)
+		}
+	}
+	want := []any{"user-1", false, from, "go.example.com", `%50\%\_off%`, 21}
+	if len(args) != len(want) {
+		t.Fatalf("got %d args %v, want %v", len(args), args, want)
+	}
+	for i := range want {
+		if args[i] != want[i] {
+			t.Errorf("arg %d = %v, want %v", i+1, args[i], want[i])
+		}
+	}
+}
+
+func TestBuildLinkQueryCursor(t *testing.T) {
+	q := model.LinkQuery{Sort: SortClicks, Order: "asc", Limit: 5}
+	first, err := buildLinkQuery("user-1", q, testNow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := nextCursor(first, model.Link{LinkID: "link-9", ClickCount: 42})
+
+	// Later pages count clicks up to the first page's snapshot, not their
+	// own time, so new clicks cannot reorder the listing.
+	q.Cursor = token
+	lq, err := buildLinkQuery("user-1", q, testNow.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshot := "(SELECT COUNT(*) FROM clicks c WHERE c.link_id = l.id AND c.created_at <= $2)"
+	for _, want := range []string{
+		snapshot + ", l.id) > ($3::bigint, $4::uuid)",
+		"ORDER BY " + snapshot + " asc, l.id asc",
+	} {
+		if !strings.Contains(lq.sql, want) {
+			t.Errorf("query is missing %q:\n%s", want, lq.sql)
+		}
+	}
+	if strings.Contains(lq.sql, clickCountExpr) {
+		t.Errorf("query still counts live clicks:\n%s", lq.sql)
+	}
+	if !lq.args[1].(time.Time).Equal(testNow) || lq.args[2] != "42" || lq.args[3] != "link-9" {
+		t.Errorf("unexpected cursor args: %v", lq.args)
+	}
+
+	// A cursor only resumes the ordering it was issued for.
+	for _, other := range []model.LinkQuery{
+		{Sort: SortClicks, Order: "desc", Cursor: token},
+		{Sort: SortSlug, Order: "asc", Cursor: token},
+		{Cursor: "not-a-cursor"},
+		// Issued before snapshots: resuming it would mix live and bounded counts.
+		{Sort: SortClicks, Order: "asc", Cursor: nextCursor(linkQuery{LinkQuery: q}, model.Link{LinkID: "link-9"})},
+	} {
+		if _, err := buildLinkQuery("user-1", other, testNow); err != ErrInvalidQuery {
+			t.Errorf("buildLinkQuery(%+v) = %v, want ErrInvalidQuery", other, err)
+		}
+	}
+}
+
+func TestBuildLinkQueryRejectsUnknownSort(t *testing.T) {
+	for _, q := range []model.LinkQuery{{Sort: "destination"}, {Order: "sideways"}} {
+		if _, err := buildLinkQuery("user-1", q, testNow); err != ErrInvalidQuery {
+			t.Errorf("buildLinkQuery(%+v) = %v, want ErrInvalidQuery", q, err)
+		}
+	}
+}
+
+func TestNextCursorValues(t *testing.T) {
+	last := model.Link{LinkID: "id-1", Slug: "spring", ClickCount: 7, CreatedAt: "2026-02-03T04:05:06Z"}
+	for sort, want := range map[string]string{SortCreated: last.CreatedAt, SortClicks: "7", SortSlug: "spring"} {
+		var c linkCursor
+		q := linkQuery{LinkQuery: model.LinkQuery{Sort: sort, Order: "desc"}}
+		if err := cursor.Decode(nextCursor(q, last), &c); err != nil {
+			t.Fatal(err)
+		}
+		if c.Value != want || c.ID != "id-1" || c.Sort != sort || c.Order != "desc" {
+			t.Errorf("%s: unexpected cursor %+v", sort, c)
+		}
+	}
+}
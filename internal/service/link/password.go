@@ -0,0 +1,41 @@
+package link
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"golang.org/x/crypto/bcrypt"
+	"redo.ai/logger"
+)
+
+// Link passwords are bcrypt hashed, which only considers the first 72 bytes.
+const (
+	MinPasswordLength = 4
+	MaxPasswordLength = 72
+)
+
+func hashLinkPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("hash password failed: %w", err)
+	}
+	return string(hash), nil
+}
+
+// CheckLinkPassword returns ErrWrongPassword unless password matches the
+// link's password. Links without a password never match.
+func (s *LinkSvc) CheckLinkPassword(ctx context.Context, linkID, password string) error {
+	var hash sql.NullString
+	err := s.DB.QueryRowContext(ctx, `SELECT password_hash FROM links WHERE id = $1`, linkID).Scan(&hash)
+	if err == sql.ErrNoRows {
+		return ErrLinkNotFound
+	} else if err != nil {
+		logger.Error("CheckLinkPassword: DB error: %v", err)
+		return fmt.Errorf("load password failed: %w", err)
+	}
+	if !hash.Valid || bcrypt.CompareHashAndPassword([]byte(hash.String), []byte(password)) != nil {
+		return ErrWrongPassword
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package link
+
+import (
+	"context"
+	"time"
+
+	"redo.ai/internal/model"
+	"redo.ai/logger"
+)
+
+// previewFetchTimeout bounds how long link creation waits for the
+// destination's metadata.
+const previewFetchTimeout = 4 * time.Second
+
+// prefillPreview fills the empty fields of req.Preview from the
+// destination's Open Graph tags. A page that cannot be fetched leaves the
+// preview as it was; it never fails link creation. Password-protected links
+// are skipped so their card cannot reveal what the password guards.
+func (s *LinkSvc) prefillPreview(ctx context.Context, req *model.CreateLinkRequest) {
+	if s.Previews == nil || req.Password != "" {
+		return
+	}
+	var preview model.LinkPreview
+	if req.Preview != nil {
+		preview = *req.Preview
+	}
+	if preview.Title != "" && preview.Description != "" && preview.Image != "" {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, previewFetchTimeout)
+	defer cancel()
+	md, err := s.Previews.Fetch(ctx, req.Destination)
+	if err != nil {
+		logger.Warn("CreateLink: could not fetch preview of %s: %v", req.Destination, err)
+		return
+	}
+	if preview.Title == "" {
+		preview.Title = md.Title
+	}
+	if preview.Description == "" {
+		preview.Description = md.Description
+	}
+	if preview.Image == "" {
+		preview.Image = md.Image
+	}
+	req.PreviewFetched = true
+	req.Preview = previewOrNil(preview)
+}
+
+func previewOrNil(p model.LinkPreview) *model.LinkPreview {
+	if p.IsZero() {
+		return nil
+	}
+	return &p
+}
@@ -0,0 +1,38 @@
+package link
+
+import (
+	"context"
+	"testing"
+
+	"redo.ai/internal/model"
+	"redo.ai/internal/pkg/ogmeta"
+)
+
+type stubFetcher struct {
+	md    ogmeta.Metadata
+	calls int
+}
+
+func (f *stubFetcher) Fetch(ctx context.Context, rawURL string) (ogmeta.Metadata, error) {
+	f.calls++
+	return f.md, nil
+}
+
+func TestPrefillPreview(t *testing.T) {
+	f := &stubFetcher{md: ogmeta.Metadata{Title: "Fetched", Description: "From the page", Image: "https://cdn.example/a.png"}}
+	s := &LinkSvc{Previews: f}
+
+	req := model.CreateLinkRequest{Destination: "https://example.com", Preview: &model.LinkPreview{Title: "Mine"}}
+	s.prefillPreview(context.Background(), &req)
+	want := model.LinkPreview{Title: "Mine", Description: "From the page", Image: "https://cdn.example/a.png"}
+	if req.Preview == nil || *req.Preview != want || !req.PreviewFetched {
+		t.Errorf("prefilled %+v (fetched=%v), want %+v (fetched=true)", req.Preview, req.PreviewFetched, want)
+	}
+
+	f.calls = 0
+	protected := model.CreateLinkRequest{Destination: "https://example.com/secret", Password: "hunter2"}
+	s.prefillPreview(context.Background(), &protected)
+	if f.calls != 0 || protected.Preview != nil || protected.PreviewFetched {
+		t.Errorf("protected link fetched %d times, preview %+v; want no fetch", f.calls, protected.Preview)
+	}
+}
@@ -0,0 +1,63 @@
+package link
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"redo.ai/internal/model"
+	"redo.ai/logger"
+)
+
+func (s *LinkSvc) GetRules(ctx context.Context, userID, linkID string) ([]model.RoutingRule, error) {
+	var raw []byte
+	err := s.DB.QueryRowContext(ctx, `SELECT routing_rules FROM links WHERE id = $1 AND user_id = $2`, linkID, userID).Scan(&raw)
+	if err == sql.ErrNoRows {
+		return nil, ErrLinkNotFound
+	} else if err != nil {
+		logger.Error("GetRules: DB error: %v", err)
+		return nil, fmt.Errorf("load rules failed: %w", err)
+	}
+	return decodeRules(raw)
+}
+
+// SetRules replaces the routing rules of a link. Rule destinations go
+// through the same destination policy as the link itself.
+func (s *LinkSvc) SetRules(ctx context.Context, userID, linkID string, rules []model.RoutingRule) ([]model.RoutingRule, error) {
+	for _, r := range rules {
+		if err := s.checkDestination(ctx, r.Destination); err != nil {
+			return nil, err
+		}
+	}
+	if rules == nil {
+		rules = []model.RoutingRule{}
+	}
+	raw, err := json.Marshal(rules)
+	if err != nil {
+		return nil, fmt.Errorf("encode rules failed: %w", err)
+	}
+	res, err := s.DB.ExecContext(ctx, `
+		UPDATE links SET routing_rules = $3, updated_at = now()
+		WHERE id = $1 AND user_id = $2
+	`, linkID, userID, raw)
+	if err != nil {
+		logger.Error("SetRules: update failed for linkID=%s: %v", linkID, err)
+		return nil, fmt.Errorf("set rules failed: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, ErrLinkNotFound
+	}
+	return rules, nil
+}
+
+func decodeRules(raw []byte) ([]model.RoutingRule, error) {
+	rules := make([]model.RoutingRule, 0)
+	if len(raw) == 0 {
+		return rules, nil
+	}
+	if err := json.Unmarshal(raw, &rules); err != nil {
+		return nil, fmt.Errorf("decode rules failed: %w", err)
+	}
+	return rules, nil
+}
@@ -0,0 +1,199 @@
+package link
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"redo.ai/internal/model"
+	"redo.ai/logger"
+)
+
+// MaxPendingSchedules bounds the pending destination changes per link.
+const MaxPendingSchedules = 50
+
+// scheduleBatch is how many due changes one ApplyDueSchedules run applies.
+const scheduleBatch = 100
+
+var ErrScheduleNotFound = errors.New("schedule not found")
+var ErrTooManySchedules = errors.New("too many pending schedules")
+
+const scheduleColumns = `s.id::text, s.link_id::text, s.destination, s.run_at, s.status, s.applied_at, s.created_at`
+
+func scanSchedule(row interface{ Scan(...any) error }) (model.LinkSchedule, error) {
+	var sc model.LinkSchedule
+	err := row.Scan(&sc.ID, &sc.LinkID, &sc.Destination, &sc.RunAt, &sc.Status, &sc.AppliedAt, &sc.CreatedAt)
+	return sc, err
+}
+
+// ListSchedules returns the destination changes of a link, upcoming first.
+func (s *LinkSvc) ListSchedules(ctx context.Context, userID, linkID string) ([]model.LinkSchedule, error) {
+	if err := ownLink(ctx, s.DB, userID, linkID); err != nil {
+		return nil, err
+	}
+	rows, err := s.DB.QueryContext(ctx, `
+		SELECT `+scheduleColumns+` FROM link_schedules s
+		WHERE s.link_id = $1
+		ORDER BY s.status <> 'pending', s.run_at DESC
+	`, linkID)
+	if err != nil {
+		logger.Error("ListSchedules: query failed: %v", err)
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	schedules := make([]model.LinkSchedule, 0)
+	for rows.Next() {
+		sc, err := scanSchedule(rows)
+		if err != nil {
+			logger.Error("ListSchedules: scan failed: %v", err)
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+		schedules = append(schedules, sc)
+	}
+	return schedules, rows.Err()
+}
+
+// CreateSchedule queues a destination change for runAt. The destination is
+// screened now, not when the change is applied.
+func (s *LinkSvc) CreateSchedule(ctx context.Context, userID, linkID string, req model.CreateScheduleRequest) (model.LinkSchedule, error) {
+	if err := ownLink(ctx, s.DB, userID, linkID); err != nil {
+		return model.LinkSchedule{}, err
+	}
+	if err := s.checkDestination(ctx, req.Destination); err != nil {
+		return model.LinkSchedule{}, err
+	}
+
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return model.LinkSchedule{}, fmt.Errorf("begin tx failed: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Lock the link so concurrent requests cannot all pass the count and
+	// exceed MaxPendingSchedules; the count runs as its own statement so it
+	// sees rows committed while waiting for the lock.
+	var locked int
+	err = tx.QueryRowContext(ctx, `SELECT 1 FROM links WHERE id = $1 AND user_id = $2 FOR NO KEY UPDATE`, linkID, userID).Scan(&locked)
+	if err == sql.ErrNoRows {
+		return model.LinkSchedule{}, ErrLinkNotFound
+	} else if err != nil {
+		logger.Error("CreateSchedule: lock failed for linkID=%s: %v", linkID, err)
+		return model.LinkSchedule{}, fmt.Errorf("lock link failed: %w", err)
+	}
+	var pending int
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM link_schedules WHERE link_id = $1 AND status = 'pending'`, linkID).Scan(&pending); err != nil {
+		logger.Error("CreateSchedule: count failed for linkID=%s: %v", linkID, err)
+		return model.LinkSchedule{}, fmt.Errorf("count schedules failed: %w", err)
+	}
+	if pending >= MaxPendingSchedules {
+		return model.LinkSchedule{}, ErrTooManySchedules
+	}
+
+	sc, err := scanSchedule(tx.QueryRowContext(ctx, `
+		INSERT INTO link_schedules (link_id, destination, run_at)
+		VALUES ($1, $2, $3)
+		RETURNING id::text, link_id::text, destination, run_at, status, applied_at, created_at
+	`, linkID, req.Destination, req.RunAt.UTC()))
+	if err != nil {
+		logger.Error("CreateSchedule: insert failed for linkID=%s: %v", linkID, err)
+		return model.LinkSchedule{}, fmt.Errorf("create schedule failed: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return model.LinkSchedule{}, fmt.Errorf("commit failed: %w", err)
+	}
+	return sc, nil
+}
+
+// CancelSchedule stops a pending change. Applied changes cannot be
+// cancelled and report ErrScheduleNotFound.
+func (s *LinkSvc) CancelSchedule(ctx context.Context, userID, linkID, scheduleID string) (model.LinkSchedule, error) {
+	if err := ownLink(ctx, s.DB, userID, linkID); err != nil {
+		return model.LinkSchedule{}, err
+	}
+	row := s.DB.QueryRowContext(ctx, `
+		UPDATE link_schedules SET status = 'cancelled'
+		WHERE id = $1 AND link_id = $2 AND status = 'pending'
+		RETURNING id::text, link_id::text, destination, run_at, status, applied_at, created_at
+	`, scheduleID, linkID)
+	sc, err := scanSchedule(row)
+	if err == sql.ErrNoRows {
+		return model.LinkSchedule{}, ErrScheduleNotFound
+	} else if err != nil {
+		logger.Error("CancelSchedule: update failed for scheduleID=%s: %v", scheduleID, err)
+		return model.LinkSchedule{}, fmt.Errorf("cancel schedule failed: %w", err)
+	}
+	return sc, nil
+}
+
+// ApplyDueSchedules applies pending destination changes whose time has
+// come, oldest first, and records each in link_revisions. Rows are claimed
+// with SKIP LOCKED so several instances can run the scheduler at once.
+func (s *LinkSvc) ApplyDueSchedules(ctx context.Context, now time.Time) (int, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin tx failed: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id::text, link_id::text, destination FROM link_schedules
+		WHERE status = 'pending' AND run_at <= $1
+		ORDER BY run_at
+		LIMIT $2
+		FOR UPDATE SKIP LOCKED
+	`, now, scheduleBatch)
+	if err != nil {
+		logger.Error("ApplyDueSchedules: query failed: %v", err)
+		return 0, fmt.Errorf("query failed: %w", err)
+	}
+	type due struct{ id, linkID, destination string }
+	var batch []due
+	for rows.Next() {
+		var d due
+		if err := rows.Scan(&d.id, &d.linkID, &d.destination); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("scan failed: %w", err)
+		}
+		batch = append(batch, d)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("row iteration failed: %w", err)
+	}
+
+	for _, d := range batch {
+		var previous string
+		err := tx.QueryRowContext(ctx, `SELECT destination FROM links WHERE id = $1 FOR UPDATE`, d.linkID).Scan(&previous)
+		if err != nil {
+			logger.Error("ApplyDueSchedules: load failed for linkID=%s: %v", d.linkID, err)
+			return 0, fmt.Errorf("load link failed: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE links SET destination = $2, updated_at = now() WHERE id = $1
+		`, d.linkID, d.destination); err != nil {
+			logger.Error("ApplyDueSchedules: update failed for linkID=%s: %v", d.linkID, err)
+			return 0, fmt.Errorf("apply schedule failed: %w", err)
+		}
+		if previous != d.destination {
+			if _, err := tx.ExecContext(ctx, `
+				INSERT INTO link_revisions (link_id, previous_destination, schedule_id) VALUES ($1, $2, $3)
+			`, d.linkID, previous, d.id); err != nil {
+				logger.Error("ApplyDueSchedules: failed to record revision for linkID=%s: %v", d.linkID, err)
+				return 0, fmt.Errorf("record revision failed: %w", err)
+			}
+		}
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE link_schedules SET status = 'applied', applied_at = now() WHERE id = $1
+		`, d.id); err != nil {
+			return 0, fmt.Errorf("mark schedule applied failed: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit failed: %w", err)
+	}
+	return len(batch), nil
+}
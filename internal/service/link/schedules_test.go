@@ -0,0 +1,165 @@
+package link
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"redo.ai/internal/model"
+	"redo.ai/internal/pkg/fakesql"
+)
+
+func TestApplyDueSchedules(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	f, db := fakesql.New(t)
+	f.On("FROM link_schedules", func(args []driver.Value) fakesql.Result {
+		if args[0] != now || args[1] != int64(scheduleBatch) {
+			t.Errorf("claim args = %v", args)
+		}
+		return fakesql.Result{
+			Columns: []string{"id", "link_id", "destination"},
+			Rows: [][]driver.Value{
+				{"s1", "l1", "https://new.example"},
+				{"s2", "l2", "https://same.example"},
+			},
+		}
+	})
+	current := map[string]string{"l1": "https://old.example", "l2": "https://same.example"}
+	f.On("SELECT destination FROM links", func(args []driver.Value) fakesql.Result {
+		return fakesql.Row([]string{"destination"}, current[args[0].(string)])
+	})
+	f.On("UPDATE links SET destination", func([]driver.Value) fakesql.Result { return fakesql.Result{} })
+	f.On("INSERT INTO link_revisions", func([]driver.Value) fakesql.Result { return fakesql.Result{} })
+	f.On("UPDATE link_schedules SET status = 'applied'", func([]driver.Value) fakesql.Result { return fakesql.Result{} })
+
+	n, err := (&LinkSvc{DB: db}).ApplyDueSchedules(context.Background(), now)
+	if err != nil || n != 2 {
+		t.Fatalf("ApplyDueSchedules = %d, %v; want 2, nil", n, err)
+	}
+
+	claim := f.Ran("FROM link_schedules")[0].Query
+	for _, want := range []string{"status = 'pending'", "run_at <= $1", "ORDER BY run_at", "FOR UPDATE SKIP LOCKED"} {
+		if !strings.Contains(claim, want) {
+			t.Errorf("claim query lacks %q", want)
+		}
+	}
+	if locks := f.Ran("SELECT destination FROM links"); len(locks) != 2 || !strings.Contains(locks[0].Query, "FOR UPDATE") {
+		t.Errorf("links not locked before update: %v", locks)
+	}
+	updates := f.Ran("UPDATE links SET destination")
+	if len(updates) != 2 || updates[0].Args[1] != "https://new.example" || updates[1].Args[1] != "https://same.example" {
+		t.Errorf("destination updates = %v", updates)
+	}
+	// Only a real change is recorded as a revision, tied to its schedule.
+	revisions := f.Ran("INSERT INTO link_revisions")
+	if len(revisions) != 1 {
+		t.Fatalf("got %d revisions, want 1", len(revisions))
+	}
+	if args := revisions[0].Args; args[0] != "l1" || args[1] != "https://old.example" || args[2] != "s1" {
+		t.Errorf("revision args = %v", args)
+	}
+	applied := f.Ran("UPDATE link_schedules SET status = 'applied'")
+	if len(applied) != 2 || applied[0].Args[0] != "s1" || applied[1].Args[0] != "s2" {
+		t.Errorf("applied = %v", applied)
+	}
+	if len(f.Ran("COMMIT")) != 1 {
+		t.Error("batch was not committed")
+	}
+}
+
+func TestApplyDueSchedulesRollsBackOnError(t *testing.T) {
+	f, db := fakesql.New(t)
+	f.On("FROM link_schedules", func([]driver.Value) fakesql.Result {
+		return fakesql.Row([]string{"id", "link_id", "destination"}, "s1", "l1", "https://new.example")
+	})
+	f.On("SELECT destination FROM links", func([]driver.Value) fakesql.Result {
+		return fakesql.Row([]string{"destination"}, "https://old.example")
+	})
+	f.On("UPDATE links SET destination", func([]driver.Value) fakesql.Result {
+		return fakesql.Result{Err: errors.New("connection reset")}
+	})
+
+	n, err := (&LinkSvc{DB: db}).ApplyDueSchedules(context.Background(), time.Now())
+	if err == nil || n != 0 {
+		t.Fatalf("ApplyDueSchedules = %d, %v; want an error", n, err)
+	}
+	if len(f.Ran("COMMIT")) != 0 || len(f.Ran("ROLLBACK")) != 1 {
+		t.Error("failed batch was not rolled back")
+	}
+	if len(f.Ran("UPDATE link_schedules")) != 0 {
+		t.Error("schedule marked applied after a failed update")
+	}
+}
+
+func TestApplyDueSchedulesNothingDue(t *testing.T) {
+	f, db := fakesql.New(t)
+	f.On("FROM link_schedules", func([]driver.Value) fakesql.Result {
+		return fakesql.Result{Columns: []string{"id", "link_id", "destination"}}
+	})
+	n, err := (&LinkSvc{DB: db}).ApplyDueSchedules(context.Background(), time.Now())
+	if err != nil || n != 0 {
+		t.Fatalf("ApplyDueSchedules = %d, %v; want 0, nil", n, err)
+	}
+}
+
+func scheduleRules(f *fakesql.DB, pending int64) {
+	f.On("SELECT EXISTS", func([]driver.Value) fakesql.Result { return fakesql.Row([]string{"exists"}, true) })
+	f.On("FOR NO KEY UPDATE", func([]driver.Value) fakesql.Result { return fakesql.Row([]string{"?column?"}, int64(1)) })
+	f.On("SELECT COUNT(*) FROM link_schedules", func([]driver.Value) fakesql.Result {
+		return fakesql.Row([]string{"count"}, pending)
+	})
+	f.On("INSERT INTO link_schedules", func(args []driver.Value) fakesql.Result {
+		now := time.Now()
+		return fakesql.Row([]string{"id", "link_id", "destination", "run_at", "status", "applied_at", "created_at"},
+			"s1", args[0], args[1], args[2], model.SchedulePending, nil, now)
+	})
+}
+
+func TestCreateScheduleLocksBeforeCounting(t *testing.T) {
+	f, db := fakesql.New(t)
+	scheduleRules(f, MaxPendingSchedules-1)
+	runAt := time.Now().Add(time.Hour)
+
+	sc, err := (&LinkSvc{DB: db}).CreateSchedule(context.Background(), "u1", "l1",
+		model.CreateScheduleRequest{Destination: "https://next.example", RunAt: runAt})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sc.ID != "s1" || sc.Destination != "https://next.example" {
+		t.Errorf("schedule = %+v", sc)
+	}
+
+	var order []string
+	for _, c := range f.Log() {
+		switch {
+		case strings.Contains(c.Query, "FOR NO KEY UPDATE"):
+			order = append(order, "lock")
+		case strings.Contains(c.Query, "SELECT COUNT(*)"):
+			order = append(order, "count")
+		case strings.Contains(c.Query, "INSERT INTO link_schedules"):
+			order = append(order, "insert")
+		case c.Query == "BEGIN" || c.Query == "COMMIT":
+			order = append(order, c.Query)
+		}
+	}
+	if got := strings.Join(order, " "); got != "BEGIN lock count insert COMMIT" {
+		t.Errorf("statement order = %s", got)
+	}
+}
+
+func TestCreateScheduleLimit(t *testing.T) {
+	f, db := fakesql.New(t)
+	scheduleRules(f, MaxPendingSchedules)
+
+	_, err := (&LinkSvc{DB: db}).CreateSchedule(context.Background(), "u1", "l1",
+		model.CreateScheduleRequest{Destination: "https://next.example", RunAt: time.Now()})
+	if err != ErrTooManySchedules {
+		t.Fatalf("err = %v, want ErrTooManySchedules", err)
+	}
+	if len(f.Ran("INSERT INTO link_schedules")) != 0 || len(f.Ran("COMMIT")) != 0 {
+		t.Error("schedule inserted past the limit")
+	}
+}
@@ -0,0 +1,135 @@
+package link
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"regexp"
+	"sort"
+	"strings"
+
+	"redo.ai/internal/model"
+	"redo.ai/internal/utils"
+	"redo.ai/logger"
+)
+
+const MaxSearchResults = 100
+
+// SearchLinks ranks the user's links against a free-text query using the
+// search_vector full-text index, with trigram similarity as a fallback for
+// partial words and typos. Tags are matched by name.
+func (s *LinkSvc) SearchLinks(ctx context.Context, userID, term string, limit int) ([]model.LinkSearchResult, error) {
+	term = strings.TrimSpace(term)
+	if term == "" {
+		return []model.LinkSearchResult{}, nil
+	}
+	if limit <= 0 || limit > MaxSearchResults {
+		limit = MaxSearchResults
+	}
+
+	query := `
+		WITH q AS (SELECT websearch_to_tsquery('simple', $2) AS tsq, lower($2) AS raw)
+		SELECT ` + linkColumns + `,
+		       ts_rank(l.search_vector, q.tsq)
+		       + GREATEST(similarity(l.slug, q.raw), similarity(COALESCE(l.title, ''), q.raw), similarity(l.destination, q.raw)) AS rank
+		FROM links l, q
+		WHERE l.user_id = $1
+		  AND (l.search_vector @@ q.tsq
+		       OR l.short_code = q.raw
+		       OR l.slug % q.raw
+		       OR l.title % q.raw
+		       OR l.destination ILIKE $3
+		       OR EXISTS (SELECT 1 FROM link_tags lt JOIN tags t ON t.id = lt.tag_id
+		                  WHERE lt.link_id = l.id AND (t.name = q.raw OR t.name % q.raw)))
+		ORDER BY rank DESC, l.created_at DESC
+		LIMIT $4
+	`
+	rows, err := s.DB.QueryContext(ctx, query, userID, term, utils.LikePattern(term), limit)
+	if err != nil {
+		logger.Error("SearchLinks: query failed: %v", err)
+		return nil, fmt.Errorf("search failed: %w", err)
+	}
+	defer rows.Close()
+
+	re := termMatcher(searchTerms(term))
+	results := make([]model.LinkSearchResult, 0)
+	for rows.Next() {
+		var (
+			r   model.LinkSearchResult
+			err error
+		)
+		if r.Link, err = scanLink(rows, &r.Rank); err != nil {
+			logger.Error("SearchLinks: row scan failed: %v", err)
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+		r.Highlights = map[string]string{}
+		for field, value := range map[string]string{
+			"slug":        r.Slug,
+			"short_code":  r.ShortCode,
+			"title":       r.Title,
+			"destination": r.Destination,
+			"tags":        strings.Join(r.Tags, ", "),
+		} {
+			if hl, ok := highlight(value, re); ok {
+				r.Highlights[field] = hl
+			}
+		}
+		results = append(results, r)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error("SearchLinks: rows iteration error: %v", err)
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return results, nil
+}
+
+var termSplit = regexp.MustCompile(`[^\pL\pN]+`)
+
+// searchTerms splits the query into words, longest first so overlapping
+// matches prefer the longer term.
+func searchTerms(q string) []string {
+	var terms []string
+	for _, t := range termSplit.Split(strings.ToLower(q), -1) {
+		if t != "" && !strings.EqualFold(t, "or") {
+			terms = append(terms, t)
+		}
+	}
+	sort.Slice(terms, func(i, j int) bool { return len(terms[i]) > len(terms[j]) })
+	return terms
+}
+
+// termMatcher compiles terms into one case-insensitive alternation, or
+// returns nil when there are none.
+func termMatcher(terms []string) *regexp.Regexp {
+	if len(terms) == 0 {
+		return nil
+	}
+	quoted := make([]string, len(terms))
+	for i, t := range terms {
+		quoted[i] = regexp.QuoteMeta(t)
+	}
+	return regexp.MustCompile(`(?i)` + strings.Join(quoted, "|"))
+}
+
+// highlight escapes value and wraps the matches of re in <mark>. It reports
+// whether anything matched.
+func highlight(value string, re *regexp.Regexp) (string, bool) {
+	if value == "" || re == nil {
+		return "", false
+	}
+	locs := re.FindAllStringIndex(value, -1)
+	if len(locs) == 0 {
+		return "", false
+	}
+	var b strings.Builder
+	prev := 0
+	for _, loc := range locs {
+		b.WriteString(html.EscapeString(value[prev:loc[0]]))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(value[loc[0]:loc[1]]))
+		b.WriteString("</mark>")
+		prev = loc[1]
+	}
+	b.WriteString(html.EscapeString(value[prev:]))
+	return b.String(), true
+}
@@ -0,0 +1,128 @@
+package link
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"redo.ai/internal/model"
+	"redo.ai/internal/pkg/entitlements"
+	"redo.ai/internal/pkg/slugs"
+	"redo.ai/logger"
+)
+
+// slugUniqueIndex enforces case-insensitive slug uniqueness per user.
+const slugUniqueIndex = "idx_links_user_slug_ci"
+
+// maxSlugSuggestions caps the alternatives offered for a taken slug.
+const maxSlugSuggestions = 5
+
+var ErrSlugReserved = errors.New("slug is reserved")
+var ErrSlugTooShort = errors.New("slug is shorter than the plan allows")
+
+// Reasons reported by CheckSlugAvailability when a slug cannot be used.
+const (
+	SlugReasonReserved = "reserved"
+	SlugReasonTooShort = "too_short"
+	SlugReasonTaken    = "taken"
+)
+
+// checkSlugPolicy applies the reserved list and the plan's minimum length to
+// a vanity slug. The empty slug means "no vanity slug" and always passes.
+func checkSlugPolicy(ent entitlements.Entitlements, slug string) error {
+	if slug == "" {
+		return nil
+	}
+	if slugs.IsReserved(slug) {
+		return ErrSlugReserved
+	}
+	if len(slug) < ent.MinSlugLength {
+		return ErrSlugTooShort
+	}
+	return nil
+}
+
+// loadPlan returns the entitlements of the user's plan.
+func (s *LinkSvc) loadPlan(ctx context.Context, userID string) (entitlements.Entitlements, error) {
+	var role string
+	if err := s.DB.QueryRowContext(ctx, `SELECT role::text FROM users WHERE id = $1`, userID).Scan(&role); err != nil {
+		logger.Error("loadPlan: failed to load plan for userID=%s: %v", userID, err)
+		return entitlements.Entitlements{}, fmt.Errorf("load plan failed: %w", err)
+	}
+	return entitlements.For(role), nil
+}
+
+// CheckSlugAvailability reports whether the user can claim slug and, when
+// they cannot, suggests free alternatives that pass the same rules.
+func (s *LinkSvc) CheckSlugAvailability(ctx context.Context, userID, slug string) (model.SlugAvailability, error) {
+	ent, err := s.loadPlan(ctx, userID)
+	if err != nil {
+		return model.SlugAvailability{}, err
+	}
+	result := model.SlugAvailability{Slug: slug, MinLength: ent.MinSlugLength, Suggestions: []string{}}
+
+	switch checkSlugPolicy(ent, slug) {
+	case ErrSlugReserved:
+		result.Reason = SlugReasonReserved
+	case ErrSlugTooShort:
+		result.Reason = SlugReasonTooShort
+	}
+
+	candidates := []string{slug}
+	for _, c := range slugs.Candidates(slug, time.Now().UTC()) {
+		if checkSlugPolicy(ent, c) == nil {
+			candidates = append(candidates, c)
+		}
+	}
+	taken, err := s.takenSlugs(ctx, userID, candidates)
+	if err != nil {
+		return model.SlugAvailability{}, err
+	}
+
+	if result.Reason == "" && taken[strings.ToLower(slug)] {
+		result.Reason = SlugReasonTaken
+	}
+	result.Available = result.Reason == ""
+	if result.Available {
+		return result, nil
+	}
+	for _, c := range candidates[1:] {
+		if !taken[strings.ToLower(c)] {
+			result.Suggestions = append(result.Suggestions, c)
+			if len(result.Suggestions) == maxSlugSuggestions {
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+// takenSlugs returns the lower-cased candidates the user already uses.
+func (s *LinkSvc) takenSlugs(ctx context.Context, userID string, candidates []string) (map[string]bool, error) {
+	lowered := make([]string, len(candidates))
+	for i, c := range candidates {
+		lowered[i] = strings.ToLower(c)
+	}
+	rows, err := s.DB.QueryContext(ctx, `
+		SELECT lower(slug) FROM links
+		WHERE user_id = $1 AND slug <> '' AND lower(slug) = ANY($2)
+	`, userID, pq.Array(lowered))
+	if err != nil {
+		logger.Error("takenSlugs: query failed: %v", err)
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	taken := map[string]bool{}
+	for rows.Next() {
+		var slug string
+		if err := rows.Scan(&slug); err != nil {
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+		taken[slug] = true
+	}
+	return taken, rows.Err()
+}
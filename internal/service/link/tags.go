@@ -0,0 +1,73 @@
+package link
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/lib/pq"
+	"redo.ai/logger"
+)
+
+// tagNamesExpr aggregates a link's tag names; it expects links aliased as l.
+const tagNamesExpr = `ARRAY(SELECT t.name FROM link_tags lt JOIN tags t ON t.id = lt.tag_id
+	WHERE lt.link_id = l.id ORDER BY t.name)`
+
+const MaxTagLength = 64
+
+// NormalizeTag trims and lower-cases a tag name so "Summer " and "summer"
+// are the same tag.
+func NormalizeTag(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// normalizeTags dedupes and sorts names, dropping empty ones.
+func normalizeTags(names []string) []string {
+	seen := map[string]bool{}
+	out := make([]string, 0, len(names))
+	for _, n := range names {
+		n = NormalizeTag(n)
+		if n == "" || len(n) > MaxTagLength || seen[n] {
+			continue
+		}
+		seen[n] = true
+		out = append(out, n)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// setLinkTags replaces a link's tags, creating any tags the user does not
+// have yet, and returns the normalised names.
+func setLinkTags(ctx context.Context, q querier, userID, linkID string, names []string) ([]string, error) {
+	tags := normalizeTags(names)
+
+	if _, err := q.ExecContext(ctx, `DELETE FROM link_tags WHERE link_id = $1`, linkID); err != nil {
+		logger.Error("setLinkTags: failed to clear tags for linkID=%s: %v", linkID, err)
+		return nil, fmt.Errorf("clear tags failed: %w", err)
+	}
+	if len(tags) == 0 {
+		return tags, nil
+	}
+
+	upsert := `
+		INSERT INTO tags (user_id, name)
+		SELECT $1, unnest($2::text[])
+		ON CONFLICT (user_id, name) DO NOTHING
+	`
+	if _, err := q.ExecContext(ctx, upsert, userID, pq.Array(tags)); err != nil {
+		logger.Error("setLinkTags: failed to create tags for userID=%s: %v", userID, err)
+		return nil, fmt.Errorf("create tags failed: %w", err)
+	}
+
+	assign := `
+		INSERT INTO link_tags (link_id, tag_id)
+		SELECT $1, id FROM tags WHERE user_id = $2 AND name = ANY($3)
+	`
+	if _, err := q.ExecContext(ctx, assign, linkID, userID, pq.Array(tags)); err != nil {
+		logger.Error("setLinkTags: failed to assign tags for linkID=%s: %v", linkID, err)
+		return nil, fmt.Errorf("assign tags failed: %w", err)
+	}
+	return tags, nil
+}
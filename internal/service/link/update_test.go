@@ -0,0 +1,81 @@
+package link
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"redo.ai/internal/model"
+	"redo.ai/internal/pkg/fakesql"
+)
+
+func TestUpdateLinkCannotReenableAdminDisabledLink(t *testing.T) {
+	f, db := fakesql.New(t)
+	f.On("disabled_reason IS NOT NULL", func([]driver.Value) fakesql.Result {
+		return fakesql.Row([]string{"destination", "disabled"}, "https://example.com", true)
+	})
+	f.On("UPDATE links", func([]driver.Value) fakesql.Result { return fakesql.Row(nil) })
+
+	active := true
+	err := updateLink(context.Background(), db, "u1", "l1", model.UpdateLinkRequest{IsActive: &active})
+	if err != ErrLinkDisabledByAdmin {
+		t.Fatalf("err = %v, want ErrLinkDisabledByAdmin", err)
+	}
+	if n := len(f.Ran("UPDATE links")); n != 0 {
+		t.Errorf("ran %d updates, want none", n)
+	}
+	if got := bulkRowError(err); got != ErrLinkDisabledByAdmin.Error() {
+		t.Errorf("bulk row error = %q, want %q", got, ErrLinkDisabledByAdmin.Error())
+	}
+
+	// Other edits, and keeping the link off, are still the owner's call.
+	title, inactive := "Renamed", false
+	for _, req := range []model.UpdateLinkRequest{{Title: &title}, {IsActive: &inactive}} {
+		if err := updateLink(context.Background(), db, "u1", "l1", req); err != nil {
+			t.Errorf("updateLink(%+v) = %v, want nil", req, err)
+		}
+	}
+}
+
+func TestUpdateLinkActivatesAt(t *testing.T) {
+	launch := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		body    string
+		set     bool
+		want    driver.Value
+		comment string
+	}{
+		{`{"title":"x"}`, false, nil, "absent leaves it unchanged"},
+		{`{"activates_at":null}`, true, nil, "null publishes now"},
+		{`{"activates_at":""}`, true, nil, "empty string publishes now"},
+		{`{"activates_at":"2026-05-01T09:00:00Z"}`, true, launch, "a time reschedules"},
+	}
+	for _, tt := range tests {
+		var req model.UpdateLinkRequest
+		if err := json.Unmarshal([]byte(tt.body), &req); err != nil {
+			t.Fatalf("%s: %v", tt.comment, err)
+		}
+
+		f, db := fakesql.New(t)
+		f.On("disabled_reason IS NOT NULL", func([]driver.Value) fakesql.Result {
+			return fakesql.Row([]string{"destination", "disabled"}, "https://example.com", false)
+		})
+		f.On("UPDATE links", func([]driver.Value) fakesql.Result { return fakesql.Row(nil) })
+		if err := updateLink(context.Background(), db, "u1", "l1", req); err != nil {
+			t.Fatalf("%s: %v", tt.comment, err)
+		}
+		args := f.Ran("activates_at = CASE")[0].Args
+		if args[8] != tt.set || (tt.want == nil && args[7] != nil) || (tt.want != nil && !launch.Equal(args[7].(time.Time))) {
+			t.Errorf("%s: activates_at args = %v, %v", tt.comment, args[7], args[8])
+		}
+
+		// Bulk jobs store requests as JSON; the field must survive the trip.
+		b, _ := json.Marshal(req)
+		var back model.UpdateLinkRequest
+		if err := json.Unmarshal(b, &back); err != nil || back.ActivatesAt.Set != tt.set || (back.ActivatesAt.Time == nil) != (tt.want == nil) {
+			t.Errorf("%s: round trip %s gave %+v", tt.comment, b, back.ActivatesAt)
+		}
+	}
+}
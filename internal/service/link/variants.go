@@ -0,0 +1,176 @@
+package link
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"redo.ai/internal/model"
+	"redo.ai/internal/utils"
+	"redo.ai/logger"
+)
+
+// Limits on A/B split links.
+const (
+	MaxVariants      = 10
+	MaxVariantWeight = 10000
+)
+
+var ErrVariantNotFound = errors.New("variant not found")
+
+const variantColumns = `v.id::text, v.link_id::text, v.label, v.destination, v.weight, v.created_at`
+
+func scanVariant(row interface{ Scan(...any) error }) (model.LinkVariant, error) {
+	var v model.LinkVariant
+	err := row.Scan(&v.ID, &v.LinkID, &v.Label, &v.Destination, &v.Weight, &v.CreatedAt)
+	return v, err
+}
+
+// ownLink returns ErrLinkNotFound unless userID owns linkID.
+func ownLink(ctx context.Context, q querier, userID, linkID string) error {
+	var exists bool
+	err := q.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM links WHERE id = $1 AND user_id = $2)`, linkID, userID).Scan(&exists)
+	if err != nil {
+		logger.Error("ownLink: query failed: %v", err)
+		return fmt.Errorf("load link failed: %w", err)
+	}
+	if !exists {
+		return ErrLinkNotFound
+	}
+	return nil
+}
+
+func (s *LinkSvc) ListVariants(ctx context.Context, userID, linkID string) ([]model.LinkVariant, error) {
+	if err := ownLink(ctx, s.DB, userID, linkID); err != nil {
+		return nil, err
+	}
+	return listVariants(ctx, s.DB, linkID)
+}
+
+func listVariants(ctx context.Context, q querier, linkID string) ([]model.LinkVariant, error) {
+	rows, err := q.QueryContext(ctx, `SELECT `+variantColumns+` FROM link_variants v WHERE v.link_id = $1 ORDER BY v.created_at, v.id`, linkID)
+	if err != nil {
+		logger.Error("listVariants: query failed: %v", err)
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	variants := make([]model.LinkVariant, 0)
+	for rows.Next() {
+		v, err := scanVariant(rows)
+		if err != nil {
+			logger.Error("listVariants: scan failed: %v", err)
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+		variants = append(variants, v)
+	}
+	return variants, rows.Err()
+}
+
+// SetVariants replaces the variants of a link. Inputs with an ID update that
+// variant in place so its click history is kept; variants left out are
+// deleted and their clicks lose the attribution.
+func (s *LinkSvc) SetVariants(ctx context.Context, userID, linkID string, inputs []model.LinkVariantInput) ([]model.LinkVariant, error) {
+	if err := ownLink(ctx, s.DB, userID, linkID); err != nil {
+		return nil, err
+	}
+	for _, in := range inputs {
+		if err := s.checkDestination(ctx, in.Destination); err != nil {
+			return nil, err
+		}
+	}
+
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx failed: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Lock the link so concurrent replacements do not interleave.
+	if _, err := tx.ExecContext(ctx, `SELECT 1 FROM links WHERE id = $1 FOR UPDATE`, linkID); err != nil {
+		return nil, fmt.Errorf("lock link failed: %w", err)
+	}
+
+	keep := make([]string, 0, len(inputs))
+	for _, in := range inputs {
+		if in.ID == "" {
+			continue
+		}
+		res, err := tx.ExecContext(ctx, `
+			UPDATE link_variants SET label = $3, destination = $4, weight = $5
+			WHERE id = $1 AND link_id = $2
+		`, in.ID, linkID, in.Label, in.Destination, in.Weight)
+		if err != nil {
+			logger.Error("SetVariants: update failed: %v", err)
+			return nil, fmt.Errorf("update variant failed: %w", err)
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return nil, ErrVariantNotFound
+		}
+		keep = append(keep, in.ID)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM link_variants WHERE link_id = $1 AND NOT (id::text = ANY($2))`, linkID, pq.Array(keep)); err != nil {
+		logger.Error("SetVariants: delete failed: %v", err)
+		return nil, fmt.Errorf("delete variants failed: %w", err)
+	}
+	for _, in := range inputs {
+		if in.ID != "" {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO link_variants (link_id, label, destination, weight)
+			VALUES ($1, $2, $3, $4)
+		`, linkID, in.Label, in.Destination, in.Weight); err != nil {
+			logger.Error("SetVariants: insert failed: %v", err)
+			return nil, fmt.Errorf("insert variant failed: %w", err)
+		}
+	}
+
+	variants, err := listVariants(ctx, tx, linkID)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit failed: %w", err)
+	}
+	return variants, nil
+}
+
+// VariantStats counts clicks and conversions per variant since the given
+// time; a zero since counts everything.
+func (s *LinkSvc) VariantStats(ctx context.Context, userID, linkID string, since time.Time) ([]model.VariantStats, error) {
+	if err := ownLink(ctx, s.DB, userID, linkID); err != nil {
+		return nil, err
+	}
+	rows, err := s.DB.QueryContext(ctx, `
+		SELECT v.id::text, v.label, v.destination, v.weight,
+		       COUNT(c.id), COUNT(c.id) FILTER (WHERE c.conversion), COALESCE(SUM(c.revenue_cents), 0)
+		FROM link_variants v
+		LEFT JOIN clicks c ON c.variant_id = v.id
+		     AND ($2::timestamptz IS NULL OR c.created_at >= $2)
+		WHERE v.link_id = $1
+		GROUP BY v.id
+		ORDER BY v.created_at, v.id
+	`, linkID, utils.NullTime(since))
+	if err != nil {
+		logger.Error("VariantStats: query failed: %v", err)
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	stats := make([]model.VariantStats, 0)
+	for rows.Next() {
+		var st model.VariantStats
+		if err := rows.Scan(&st.VariantID, &st.Label, &st.Destination, &st.Weight, &st.Clicks, &st.Conversions, &st.RevenueCents); err != nil {
+			logger.Error("VariantStats: scan failed: %v", err)
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+		if st.Clicks > 0 {
+			st.ConversionRate = float64(st.Conversions) / float64(st.Clicks)
+		}
+		stats = append(stats, st)
+	}
+	return stats, rows.Err()
+}
@@ -0,0 +1,128 @@
+package tag
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+	"redo.ai/internal/model"
+	"redo.ai/internal/service/link"
+	"redo.ai/logger"
+)
+
+// TagService defines the interface for managing a user's tags.
+type TagService interface {
+	ListTags(ctx context.Context, userID string) ([]model.Tag, error)
+	CreateTag(ctx context.Context, userID, name string) (model.Tag, error)
+	RenameTag(ctx context.Context, userID, tagID, name string) (model.Tag, error)
+	DeleteTag(ctx context.Context, userID, tagID string) error
+}
+
+var ErrTagNotFound = errors.New("tag not found")
+var ErrTagExists = errors.New("tag already exists")
+var ErrInvalidTag = errors.New("invalid tag name")
+
+type TagSvc struct {
+	DB *sql.DB
+}
+
+const tagColumns = `t.id::text, t.name, t.created_at,
+	(SELECT COUNT(*) FROM link_tags lt WHERE lt.tag_id = t.id)`
+
+func scanTag(row interface{ Scan(...any) error }) (model.Tag, error) {
+	var t model.Tag
+	err := row.Scan(&t.ID, &t.Name, &t.CreatedAt, &t.LinkCount)
+	return t, err
+}
+
+func validName(name string) (string, error) {
+	name = link.NormalizeTag(name)
+	if name == "" || len(name) > link.MaxTagLength {
+		return "", ErrInvalidTag
+	}
+	return name, nil
+}
+
+func isDuplicate(err error) bool {
+	pqErr, ok := err.(*pq.Error)
+	return ok && pqErr.Code == "23505"
+}
+
+func (s *TagSvc) ListTags(ctx context.Context, userID string) ([]model.Tag, error) {
+	query := `SELECT ` + tagColumns + ` FROM tags t WHERE t.user_id = $1 ORDER BY t.name`
+	rows, err := s.DB.QueryContext(ctx, query, userID)
+	if err != nil {
+		logger.Error("ListTags: query failed: %v", err)
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	tags := make([]model.Tag, 0)
+	for rows.Next() {
+		t, err := scanTag(rows)
+		if err != nil {
+			logger.Error("ListTags: scan failed: %v", err)
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+		tags = append(tags, t)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error("ListTags: rows iteration error: %v", err)
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return tags, nil
+}
+
+func (s *TagSvc) CreateTag(ctx context.Context, userID, name string) (model.Tag, error) {
+	name, err := validName(name)
+	if err != nil {
+		return model.Tag{}, err
+	}
+	query := `
+		WITH t AS (INSERT INTO tags (user_id, name) VALUES ($1, $2) RETURNING *)
+		SELECT ` + tagColumns + ` FROM t
+	`
+	t, err := scanTag(s.DB.QueryRowContext(ctx, query, userID, name))
+	if isDuplicate(err) {
+		return model.Tag{}, ErrTagExists
+	} else if err != nil {
+		logger.Error("CreateTag: insert failed for userID=%s: %v", userID, err)
+		return model.Tag{}, fmt.Errorf("create tag failed: %w", err)
+	}
+	return t, nil
+}
+
+func (s *TagSvc) RenameTag(ctx context.Context, userID, tagID, name string) (model.Tag, error) {
+	name, err := validName(name)
+	if err != nil {
+		return model.Tag{}, err
+	}
+	query := `
+		WITH t AS (UPDATE tags SET name = $3 WHERE id = $1 AND user_id = $2 RETURNING *)
+		SELECT ` + tagColumns + ` FROM t
+	`
+	t, err := scanTag(s.DB.QueryRowContext(ctx, query, tagID, userID, name))
+	if err == sql.ErrNoRows {
+		return model.Tag{}, ErrTagNotFound
+	} else if isDuplicate(err) {
+		return model.Tag{}, ErrTagExists
+	} else if err != nil {
+		logger.Error("RenameTag: update failed for tagID=%s: %v", tagID, err)
+		return model.Tag{}, fmt.Errorf("rename tag failed: %w", err)
+	}
+	return t, nil
+}
+
+func (s *TagSvc) DeleteTag(ctx context.Context, userID, tagID string) error {
+	res, err := s.DB.ExecContext(ctx, `DELETE FROM tags WHERE id = $1 AND user_id = $2`, tagID, userID)
+	if err != nil {
+		logger.Error("DeleteTag: delete failed for tagID=%s: %v", tagID, err)
+		return fmt.Errorf("delete tag failed: %w", err)
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return ErrTagNotFound
+	}
+	return nil
+}
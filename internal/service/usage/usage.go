@@ -0,0 +1,236 @@
+package usage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"redo.ai/internal/model"
+	"redo.ai/internal/pkg/entitlements"
+	"redo.ai/logger"
+)
+
+// Metric is a per-period counter tracked for every user.
+type Metric string
+
+const (
+	MetricLinks  Metric = "links_created"
+	MetricClicks Metric = "clicks_tracked"
+)
+
+// Thresholds (percent of the plan allowance) that emit an Event when crossed.
+var Thresholds = []int{80, 100}
+
+// UsageService defines the interface for usage metering operations.
+type UsageService interface {
+	Check(ctx context.Context, userID string, m Metric, n int) error
+	Record(ctx context.Context, userID string, m Metric, n int) error
+	Current(ctx context.Context, userID string) (model.Usage, error)
+}
+
+// Event is emitted once per period when a user crosses a usage threshold.
+type Event struct {
+	UserID      string
+	Plan        string
+	Metric      Metric
+	Threshold   int
+	Used        int
+	Allowed     int
+	PeriodStart time.Time
+}
+
+// EventSink receives threshold events, e.g. to prompt an upgrade.
+type EventSink interface {
+	Publish(ctx context.Context, ev Event)
+}
+
+// LogSink writes threshold events to the application log.
+type LogSink struct{}
+
+func (LogSink) Publish(ctx context.Context, ev Event) {
+	logger.Info("usage: user %s crossed %d%% of %s (%d/%d) on %s plan",
+		ev.UserID, ev.Threshold, ev.Metric, ev.Used, ev.Allowed, ev.Plan)
+}
+
+type UsageSvc struct {
+	DB     *sql.DB
+	Events EventSink
+}
+
+// PeriodStart returns the start of the billing period containing t. Periods
+// are calendar months in UTC.
+func PeriodStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+func limitFor(m Metric) entitlements.Limit {
+	if m == MetricClicks {
+		return entitlements.LimitMonthlyClicks
+	}
+	return entitlements.LimitMonthlyLinks
+}
+
+func column(m Metric) (string, error) {
+	switch m {
+	case MetricLinks:
+		return "links_created", nil
+	case MetricClicks:
+		return "clicks_tracked", nil
+	default:
+		return "", fmt.Errorf("unknown metric %q", m)
+	}
+}
+
+// Check returns a *entitlements.QuotaError when recording n more units would
+// exceed a hard quota. Soft quotas never block.
+func (s *UsageSvc) Check(ctx context.Context, userID string, m Metric, n int) error {
+	col, err := column(m)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf(`
+		SELECT u.role::text, COALESCE(uc.%s, 0)
+		FROM users u
+		LEFT JOIN usage_counters uc ON uc.user_id = u.id AND uc.period_start = $2
+		WHERE u.id = $1
+	`, col)
+	var (
+		role string
+		used int
+	)
+	period := PeriodStart(time.Now())
+	if err := s.DB.QueryRowContext(ctx, query, userID, period).Scan(&role, &used); err != nil {
+		logger.Error("usage.Check: query failed for userID=%s: %v", userID, err)
+		return fmt.Errorf("usage check failed: %w", err)
+	}
+	ent := entitlements.For(role)
+	if !ent.HardQuota {
+		return nil
+	}
+	return ent.CheckQuota(limitFor(m), used, n)
+}
+
+// Record adds n units to the current period and emits threshold events the
+// first time each threshold is crossed.
+func (s *UsageSvc) Record(ctx context.Context, userID string, m Metric, n int) error {
+	col, err := column(m)
+	if err != nil {
+		return err
+	}
+	period := PeriodStart(time.Now())
+	query := fmt.Sprintf(`
+		WITH counter AS (
+			INSERT INTO usage_counters (user_id, period_start, %[1]s)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (user_id, period_start)
+			DO UPDATE SET %[1]s = usage_counters.%[1]s + EXCLUDED.%[1]s, updated_at = now()
+			RETURNING %[1]s
+		)
+		SELECT counter.%[1]s, u.role::text
+		FROM counter, users u
+		WHERE u.id = $1
+	`, col)
+	var (
+		used int
+		role string
+	)
+	if err := s.DB.QueryRowContext(ctx, query, userID, period, n).Scan(&used, &role); err != nil {
+		logger.Error("usage.Record: failed to record %s for userID=%s: %v", m, userID, err)
+		return fmt.Errorf("usage record failed: %w", err)
+	}
+
+	allowed := entitlements.For(role).Quota(limitFor(m))
+	for _, threshold := range crossed(used, n, allowed) {
+		s.emit(ctx, Event{
+			UserID:      userID,
+			Plan:        role,
+			Metric:      m,
+			Threshold:   threshold,
+			Used:        used,
+			Allowed:     allowed,
+			PeriodStart: period,
+		})
+	}
+	return nil
+}
+
+// crossed returns the thresholds that adding n units crossed, ending at
+// used out of allowed. Unlimited and zero allowances have no thresholds.
+func crossed(used, n, allowed int) []int {
+	if allowed == entitlements.Unlimited || allowed == 0 {
+		return nil
+	}
+	var out []int
+	for _, threshold := range Thresholds {
+		mark := allowed * threshold / 100
+		if used-n < mark && used >= mark {
+			out = append(out, threshold)
+		}
+	}
+	return out
+}
+
+// emit stores the alert so each threshold fires once per period across all
+// instances, then hands it to the sink.
+func (s *UsageSvc) emit(ctx context.Context, ev Event) {
+	query := `
+		INSERT INTO usage_alerts (user_id, period_start, metric, threshold, used, allowed)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (user_id, period_start, metric, threshold) DO NOTHING
+	`
+	res, err := s.DB.ExecContext(ctx, query, ev.UserID, ev.PeriodStart, string(ev.Metric), ev.Threshold, ev.Used, ev.Allowed)
+	if err != nil {
+		logger.Error("usage.emit: failed to store alert for userID=%s: %v", ev.UserID, err)
+		return
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 || s.Events == nil {
+		return
+	}
+	s.Events.Publish(ctx, ev)
+}
+
+// Current returns the user's consumption for the current billing period.
+func (s *UsageSvc) Current(ctx context.Context, userID string) (model.Usage, error) {
+	period := PeriodStart(time.Now())
+	query := `
+		SELECT u.role::text, COALESCE(uc.links_created, 0), COALESCE(uc.clicks_tracked, 0)
+		FROM users u
+		LEFT JOIN usage_counters uc ON uc.user_id = u.id AND uc.period_start = $2
+		WHERE u.id = $1
+	`
+	var (
+		role          string
+		links, clicks int
+	)
+	if err := s.DB.QueryRowContext(ctx, query, userID, period).Scan(&role, &links, &clicks); err != nil {
+		logger.Error("usage.Current: query failed for userID=%s: %v", userID, err)
+		return model.Usage{}, fmt.Errorf("load usage failed: %w", err)
+	}
+
+	ent := entitlements.For(role)
+	return model.Usage{
+		Plan:        role,
+		PeriodStart: period,
+		PeriodEnd:   period.AddDate(0, 1, 0),
+		Metrics: []model.UsageMetric{
+			metric(ent, MetricLinks, links),
+			metric(ent, MetricClicks, clicks),
+		},
+	}, nil
+}
+
+func metric(ent entitlements.Entitlements, m Metric, used int) model.UsageMetric {
+	allowed := ent.Quota(limitFor(m))
+	um := model.UsageMetric{
+		Metric:  string(m),
+		Used:    used,
+		Allowed: allowed,
+		Hard:    ent.HardQuota,
+	}
+	if allowed > 0 {
+		um.Percent = float64(used) * 100 / float64(allowed)
+	}
+	return um
+}
@@ -0,0 +1,86 @@
+package usage
+
+import (
+	"context"
+	"database/sql/driver"
+	"reflect"
+	"testing"
+
+	"redo.ai/internal/pkg/entitlements"
+	"redo.ai/internal/pkg/fakesql"
+)
+
+func TestCrossed(t *testing.T) {
+	tests := []struct {
+		name             string
+		used, n, allowed int
+		want             []int
+	}{
+		{"below 80%", 7, 1, 10, nil},
+		{"reaches 80%", 8, 1, 10, []int{80}},
+		{"already past 80%", 9, 1, 10, nil},
+		{"reaches 100%", 10, 1, 10, []int{100}},
+		{"already over 100%", 11, 1, 10, nil},
+		{"jumps both thresholds", 10, 3, 10, []int{80, 100}},
+		{"jumps both and overshoots", 14, 7, 10, []int{80, 100}},
+		{"rounds the mark down", 2, 1, 3, []int{80}},
+		{"unlimited", 1000, 1000, entitlements.Unlimited, nil},
+		{"zero allowance", 5, 5, 0, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := crossed(tt.used, tt.n, tt.allowed); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("crossed(%d, %d, %d) = %v, want %v", tt.used, tt.n, tt.allowed, got, tt.want)
+			}
+		})
+	}
+}
+
+type recordingSink struct{ events []Event }
+
+func (s *recordingSink) Publish(ctx context.Context, ev Event) { s.events = append(s.events, ev) }
+
+func TestRecordPublishesEachThresholdOncePerPeriod(t *testing.T) {
+	f, db := fakesql.New(t)
+	used := int64(0)
+	f.On("INSERT INTO usage_counters", func(args []driver.Value) fakesql.Result {
+		used += args[2].(int64)
+		return fakesql.Row([]string{"links_created", "role"}, used, "free")
+	})
+	// The first insert of an alert for a period stores it; repeats conflict
+	// and affect no rows.
+	stored := map[int64]bool{}
+	f.On("INSERT INTO usage_alerts", func(args []driver.Value) fakesql.Result {
+		threshold := args[3].(int64)
+		if stored[threshold] {
+			return fakesql.Result{}
+		}
+		stored[threshold] = true
+		return fakesql.Row(nil)
+	})
+
+	sink := &recordingSink{}
+	s := &UsageSvc{DB: db, Events: sink}
+	// Free allows 25 links a month: 80% is 20.
+	for _, n := range []int{19, 1, 1, 4, 1} {
+		if err := s.Record(context.Background(), "u1", MetricLinks, n); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(sink.events) != 2 || sink.events[0].Threshold != 80 || sink.events[0].Used != 20 ||
+		sink.events[1].Threshold != 100 || sink.events[1].Used != 25 {
+		t.Fatalf("events = %+v, want 80%% at 20 then 100%% at 25", sink.events)
+	}
+
+	// Another instance that saw the same crossing finds the alert stored.
+	used = 19
+	if err := s.Record(context.Background(), "u1", MetricLinks, 1); err != nil {
+		t.Fatal(err)
+	}
+	if len(sink.events) != 2 {
+		t.Errorf("repeated crossing published again: %+v", sink.events[2:])
+	}
+	if got := len(f.Ran("INSERT INTO usage_alerts")); got != 3 {
+		t.Errorf("stored %d alerts, want 3 attempts", got)
+	}
+}
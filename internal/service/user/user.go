@@ -3,15 +3,33 @@ package user
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"time"
 
+	"github.com/lib/pq"
 	"redo.ai/internal/model"
+	"redo.ai/logger"
 )
 
+// DeletionGracePeriod is how long a deleted account can still be restored
+// before it is purged along with its links and clicks.
+const DeletionGracePeriod = 30 * 24 * time.Hour
+
+var ErrEmailTaken = errors.New("email already in use")
+var ErrUserNotFound = errors.New("user not found")
+
 // UserService defines the interface for user-related operations.
 type UserService interface {
 	SignUp(context.Context, string, string) (*model.User, error)
 	GetByID(ctx context.Context, auth0Sub string) (*model.User, error)
+	GetByUserID(ctx context.Context, userID string) (*model.User, error)
 	UserExists(ctx context.Context, userID string) (bool, error)
+	UpdateProfile(ctx context.Context, userID string, update model.ProfileUpdate) (*model.User, error)
+	ChangeEmail(ctx context.Context, userID, email string) (*model.User, error)
+	ScheduleDeletion(ctx context.Context, userID string) (*model.User, error)
+	CancelDeletion(ctx context.Context, userID string) (*model.User, error)
+	PurgeDeletedUsers(ctx context.Context) (int64, error)
 }
 
 // Concrete implementation of UserService.
@@ -19,27 +37,43 @@ type UserSvc struct {
 	DB *sql.DB
 }
 
-// SignUp creates a new user with only the Auth0 sub (no PII).
-func (s *UserSvc) SignUp(ctx context.Context, auth0Sub, email string) (*model.User, error) {
-	var user model.User
+const userColumns = `id::text, role, email, COALESCE(name, ''), COALESCE(business_name, ''), created_at, deletion_scheduled_for`
 
-	query := `
-        INSERT INTO users (auth0_sub, email)
-        VALUES ($1, $2)
-        RETURNING user_id, role
-    `
-	err := s.DB.QueryRowContext(ctx, query, auth0Sub, email).Scan(
+func scanUser(row *sql.Row) (*model.User, error) {
+	var (
+		user      model.User
+		createdAt sql.NullTime
+		deletion  sql.NullTime
+	)
+	err := row.Scan(
 		&user.UserID,
 		&user.Role,
+		&user.Email,
+		&user.Name,
+		&user.BusinessName,
+		&createdAt,
+		&deletion,
 	)
-
 	if err != nil {
 		return nil, err
 	}
-
+	user.CreatedAt = createdAt.Time
+	if deletion.Valid {
+		user.DeletionScheduledFor = &deletion.Time
+	}
 	return &user, nil
 }
 
+// SignUp creates a new user with only the Auth0 sub (no PII).
+func (s *UserSvc) SignUp(ctx context.Context, auth0Sub, email string) (*model.User, error) {
+	query := `
+        INSERT INTO users (auth0_sub, email)
+        VALUES ($1, $2)
+        RETURNING ` + userColumns
+
+	return scanUser(s.DB.QueryRowContext(ctx, query, auth0Sub, email))
+}
+
 func (s *UserSvc) UserExists(ctx context.Context, userID string) (bool, error) {
 	query := "SELECT EXISTS(SELECT 1 FROM users WHERE id = $1)"
 	var exists bool
@@ -52,22 +86,117 @@ func (s *UserSvc) UserExists(ctx context.Context, userID string) (bool, error) {
 
 // GetByID retrieves a user by Auth0 sub and returns the user with string ID.
 func (s *UserSvc) GetByID(ctx context.Context, auth0Sub string) (*model.User, error) {
-	var user model.User
-
 	query := `
-        SELECT id::text, role
+        SELECT ` + userColumns + `
         FROM users
         WHERE auth0_sub = $1
     `
 
-	err := s.DB.QueryRowContext(ctx, query, auth0Sub).Scan(
-		&user.UserID,
-		&user.Role,
-	)
+	return scanUser(s.DB.QueryRowContext(ctx, query, auth0Sub))
+}
 
-	if err != nil {
-		return nil, err
+// GetByUserID retrieves a user by the internal UUID.
+func (s *UserSvc) GetByUserID(ctx context.Context, userID string) (*model.User, error) {
+	query := `
+        SELECT ` + userColumns + `
+        FROM users
+        WHERE id = $1
+    `
+
+	return scanUser(s.DB.QueryRowContext(ctx, query, userID))
+}
+
+// UpdateProfile applies the non-nil fields of update.
+func (s *UserSvc) UpdateProfile(ctx context.Context, userID string, update model.ProfileUpdate) (*model.User, error) {
+	query := `
+        UPDATE users
+        SET name = COALESCE($2, name),
+            business_name = COALESCE($3, business_name),
+            updated_at = now()
+        WHERE id = $1
+        RETURNING ` + userColumns
+
+	user, err := scanUser(s.DB.QueryRowContext(ctx, query, userID, update.Name, update.BusinessName))
+	if err == sql.ErrNoRows {
+		return nil, ErrUserNotFound
+	} else if err != nil {
+		logger.Error("UpdateProfile: failed for userID=%s: %v", userID, err)
+		return nil, fmt.Errorf("update profile failed: %w", err)
 	}
+	return user, nil
+}
 
-	return &user, nil
+// ChangeEmail updates the user's email, returning ErrEmailTaken when another
+// account already uses it.
+func (s *UserSvc) ChangeEmail(ctx context.Context, userID, email string) (*model.User, error) {
+	query := `
+        UPDATE users
+        SET email = $2, updated_at = now()
+        WHERE id = $1
+        RETURNING ` + userColumns
+
+	user, err := scanUser(s.DB.QueryRowContext(ctx, query, userID, email))
+	if err == sql.ErrNoRows {
+		return nil, ErrUserNotFound
+	} else if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			if pqErr.Constraint == "idx_users_email" || pqErr.Constraint == "users_email_key" {
+				logger.Warn("ChangeEmail: email already in use for userID=%s", userID)
+				return nil, ErrEmailTaken
+			}
+		}
+		logger.Error("ChangeEmail: failed for userID=%s: %v", userID, err)
+		return nil, fmt.Errorf("change email failed: %w", err)
+	}
+	return user, nil
+}
+
+// ScheduleDeletion marks the account for purge after DeletionGracePeriod.
+// Calling it again keeps the original date.
+func (s *UserSvc) ScheduleDeletion(ctx context.Context, userID string) (*model.User, error) {
+	query := `
+        UPDATE users
+        SET deletion_scheduled_for = COALESCE(deletion_scheduled_for, $2), updated_at = now()
+        WHERE id = $1
+        RETURNING ` + userColumns
+
+	user, err := scanUser(s.DB.QueryRowContext(ctx, query, userID, time.Now().UTC().Add(DeletionGracePeriod)))
+	if err == sql.ErrNoRows {
+		return nil, ErrUserNotFound
+	} else if err != nil {
+		logger.Error("ScheduleDeletion: failed for userID=%s: %v", userID, err)
+		return nil, fmt.Errorf("schedule deletion failed: %w", err)
+	}
+	return user, nil
+}
+
+// CancelDeletion restores an account that is still within its grace period.
+func (s *UserSvc) CancelDeletion(ctx context.Context, userID string) (*model.User, error) {
+	query := `
+        UPDATE users
+        SET deletion_scheduled_for = NULL, updated_at = now()
+        WHERE id = $1
+        RETURNING ` + userColumns
+
+	user, err := scanUser(s.DB.QueryRowContext(ctx, query, userID))
+	if err == sql.ErrNoRows {
+		return nil, ErrUserNotFound
+	} else if err != nil {
+		logger.Error("CancelDeletion: failed for userID=%s: %v", userID, err)
+		return nil, fmt.Errorf("cancel deletion failed: %w", err)
+	}
+	return user, nil
+}
+
+// PurgeDeletedUsers removes accounts whose grace period has ended. Links and
+// clicks go with them through ON DELETE CASCADE.
+func (s *UserSvc) PurgeDeletedUsers(ctx context.Context) (int64, error) {
+	query := `DELETE FROM users WHERE deletion_scheduled_for IS NOT NULL AND deletion_scheduled_for <= now()`
+	res, err := s.DB.ExecContext(ctx, query)
+	if err != nil {
+		logger.Error("PurgeDeletedUsers: failed: %v", err)
+		return 0, fmt.Errorf("purge deleted users failed: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n, nil
 }
@@ -1,10 +1,13 @@
 package utils
 
 import (
+	"database/sql"
 	"encoding/json"
 	"net/http"
+	"net/mail"
 	"net/url"
 	"regexp"
+	"strings"
 	"time"
 
 	"redo.ai/logger"
@@ -39,8 +42,9 @@ func Contains(slice []string, item string) bool {
 func WithCORS(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "http://localhost:4040")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Expose-Headers", "X-Request-ID")
+		w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type, X-User-ID, X-Request-ID")
 
 		// Respond to preflight OPTIONS requests
 		if r.Method == http.MethodOptions {
@@ -71,3 +75,21 @@ func IsValidURL(destination string) bool {
 	_, err := url.ParseRequestURI(destination)
 	return err == nil
 }
+
+func IsValidEmail(email string) bool {
+	addr, err := mail.ParseAddress(email)
+	return err == nil && addr.Address == email
+}
+
+// LikePattern escapes LIKE wildcards so a search term matches literally
+// anywhere in the column.
+func LikePattern(term string) string {
+	r := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return "%" + r.Replace(term) + "%"
+}
+
+// NullTime maps the zero time to NULL so optional bounds can be passed
+// straight into queries.
+func NullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	if err := SetTrustedProxies("10.0.0.0/8, 192.0.2.1"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { trustedProxies = nil })
+
+	tests := []struct {
+		name   string
+		remote string
+		xff    string
+		want   string
+	}{
+		{name: "Direct connection", remote: "203.0.113.9:5000", want: "203.0.113.9"},
+		{name: "Forged header from untrusted peer", remote: "203.0.113.9:5000", xff: "1.2.3.4", want: "203.0.113.9"},
+		{name: "Trusted proxy", remote: "10.1.2.3:443", xff: "198.51.100.7", want: "198.51.100.7"},
+		{name: "Client-supplied prefix is ignored", remote: "10.1.2.3:443", xff: "1.2.3.4, 198.51.100.7", want: "198.51.100.7"},
+		{name: "Chain of trusted proxies", remote: "10.1.2.3:443", xff: "198.51.100.7, 192.0.2.1, 10.9.9.9", want: "198.51.100.7"},
+		{name: "Garbage hop stops the walk", remote: "10.1.2.3:443", xff: "198.51.100.7, not-an-ip", want: "10.1.2.3"},
+		{name: "Trusted proxy without header", remote: "10.1.2.3:443", want: "10.1.2.3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = tt.remote
+			if tt.xff != "" {
+				r.Header.Set("X-Forwarded-For", tt.xff)
+			}
+			if got := ClientIP(r); got != tt.want {
+				t.Errorf("ClientIP = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetTrustedProxiesRejectsGarbage(t *testing.T) {
+	defer func() { trustedProxies = nil }()
+	if err := SetTrustedProxies("10.0.0.0/8,nope"); err == nil {
+		t.Fatal("expected an error")
+	}
+}
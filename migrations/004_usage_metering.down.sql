@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS usage_alerts;
+DROP TABLE IF EXISTS usage_counters;
@@ -0,0 +1,22 @@
+-- Per-user, per-billing-period usage counters
+CREATE TABLE IF NOT EXISTS usage_counters (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    period_start DATE NOT NULL,
+    links_created INT NOT NULL DEFAULT 0,
+    clicks_tracked INT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ DEFAULT now(),
+    PRIMARY KEY (user_id, period_start)
+);
+
+-- One row per threshold crossed, so each alert fires once per period
+CREATE TABLE IF NOT EXISTS usage_alerts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    period_start DATE NOT NULL,
+    metric TEXT NOT NULL,
+    threshold INT NOT NULL,
+    used INT NOT NULL,
+    allowed INT NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT now(),
+    UNIQUE (user_id, period_start, metric, threshold)
+);
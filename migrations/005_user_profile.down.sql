@@ -0,0 +1,14 @@
+ALTER TABLE notifications
+DROP CONSTRAINT IF EXISTS notifications_user_id_fkey,
+ADD CONSTRAINT notifications_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id),
+DROP CONSTRAINT IF EXISTS notifications_link_id_fkey,
+ADD CONSTRAINT notifications_link_id_fkey FOREIGN KEY (link_id) REFERENCES links(id),
+DROP CONSTRAINT IF EXISTS notifications_click_id_fkey,
+ADD CONSTRAINT notifications_click_id_fkey FOREIGN KEY (click_id) REFERENCES clicks(id);
+
+DROP INDEX IF EXISTS idx_users_deletion_scheduled_for;
+
+ALTER TABLE users
+DROP COLUMN IF EXISTS deletion_scheduled_for,
+DROP COLUMN IF EXISTS business_name,
+DROP COLUMN IF EXISTS name;
@@ -0,0 +1,21 @@
+-- Profile fields
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS name TEXT,
+ADD COLUMN IF NOT EXISTS business_name TEXT;
+
+-- Account deletion grace period: the row is purged (cascading to links and
+-- clicks) once deletion_scheduled_for has passed.
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS deletion_scheduled_for TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_users_deletion_scheduled_for
+ON users(deletion_scheduled_for) WHERE deletion_scheduled_for IS NOT NULL;
+
+-- Let purged accounts and deleted links take their notifications with them
+ALTER TABLE notifications
+DROP CONSTRAINT IF EXISTS notifications_user_id_fkey,
+ADD CONSTRAINT notifications_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+DROP CONSTRAINT IF EXISTS notifications_link_id_fkey,
+ADD CONSTRAINT notifications_link_id_fkey FOREIGN KEY (link_id) REFERENCES links(id) ON DELETE CASCADE,
+DROP CONSTRAINT IF EXISTS notifications_click_id_fkey,
+ADD CONSTRAINT notifications_click_id_fkey FOREIGN KEY (click_id) REFERENCES clicks(id) ON DELETE CASCADE;
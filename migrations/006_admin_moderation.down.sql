@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_moderation_actions_target;
+DROP INDEX IF EXISTS idx_moderation_actions_created_at;
+DROP TABLE IF EXISTS moderation_actions;
+
+ALTER TABLE links
+DROP COLUMN IF EXISTS disabled_reason;
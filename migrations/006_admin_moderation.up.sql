@@ -0,0 +1,18 @@
+-- Reason shown to ops when a link was disabled by moderation
+ALTER TABLE links
+ADD COLUMN IF NOT EXISTS disabled_reason TEXT;
+
+-- Moderation audit log: every admin action across tenants
+CREATE TABLE IF NOT EXISTS moderation_actions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    admin_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    action TEXT NOT NULL,
+    target_type TEXT NOT NULL,
+    target_id UUID NOT NULL,
+    reason TEXT,
+    details JSONB DEFAULT '{}'::JSONB,
+    created_at TIMESTAMPTZ DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_moderation_actions_created_at ON moderation_actions(created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_moderation_actions_target ON moderation_actions(target_type, target_id);
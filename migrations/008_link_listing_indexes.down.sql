@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_links_custom_domain_id;
+DROP INDEX IF EXISTS idx_links_user_slug_id;
+DROP INDEX IF EXISTS idx_links_user_created;
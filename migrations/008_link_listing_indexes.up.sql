@@ -0,0 +1,4 @@
+-- Keyset pagination for link listings
+CREATE INDEX IF NOT EXISTS idx_links_user_created ON links(user_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_links_user_slug_id ON links(user_id, slug, id);
+CREATE INDEX IF NOT EXISTS idx_links_custom_domain_id ON links(custom_domain_id);
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_links_destination_trgm;
+DROP INDEX IF EXISTS idx_links_title_trgm;
+DROP INDEX IF EXISTS idx_links_slug_trgm;
+DROP INDEX IF EXISTS idx_links_search_vector;
+
+ALTER TABLE links
+DROP COLUMN IF EXISTS search_vector,
+DROP COLUMN IF EXISTS title;
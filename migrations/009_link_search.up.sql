@@ -0,0 +1,20 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE links
+ADD COLUMN IF NOT EXISTS title TEXT;
+
+-- Full-text document over everything a user might search a link by. URL
+-- punctuation is split so path segments and hosts become separate lexemes.
+ALTER TABLE links
+ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', coalesce(title, '')), 'A') ||
+    setweight(to_tsvector('simple', coalesce(slug, '') || ' ' || coalesce(short_code, '')), 'A') ||
+    setweight(to_tsvector('simple', regexp_replace(coalesce(destination, ''), '[/:.?=&#_+-]+', ' ', 'g')), 'B')
+) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_links_search_vector ON links USING GIN (search_vector);
+
+-- Trigram indexes for fuzzy and substring matches
+CREATE INDEX IF NOT EXISTS idx_links_slug_trgm ON links USING GIN (slug gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_links_title_trgm ON links USING GIN (title gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_links_destination_trgm ON links USING GIN (destination gin_trgm_ops);
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_links_campaign_id;
+
+ALTER TABLE links
+DROP COLUMN IF EXISTS campaign_id;
+
+DROP TABLE IF EXISTS campaigns;
+DROP INDEX IF EXISTS idx_link_tags_tag_id;
+DROP TABLE IF EXISTS link_tags;
+DROP TABLE IF EXISTS tags;
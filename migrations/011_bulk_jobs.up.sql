@@ -0,0 +1,17 @@
+-- Background bulk link operations and their per-row results
+CREATE TABLE IF NOT EXISTS bulk_jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    operation TEXT NOT NULL CHECK (operation IN ('create', 'update', 'delete')),
+    status TEXT NOT NULL DEFAULT 'queued' CHECK (status IN ('queued', 'running', 'completed', 'failed')),
+    atomic BOOLEAN NOT NULL DEFAULT FALSE,
+    total INTEGER NOT NULL,
+    result JSONB,
+    error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    started_at TIMESTAMPTZ,
+    finished_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_bulk_jobs_user_created ON bulk_jobs(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_bulk_jobs_unfinished ON bulk_jobs(status) WHERE status IN ('queued', 'running');
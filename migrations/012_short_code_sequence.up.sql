@@ -0,0 +1,2 @@
+-- Source of numbers for the sequential short code strategy
+CREATE SEQUENCE IF NOT EXISTS short_code_seq AS BIGINT START WITH 1;
@@ -0,0 +1,24 @@
+-- The old constraint also covers empty slugs, so it cannot be restored while
+-- a user has more than one link without a vanity slug.
+DO $$
+DECLARE
+    conflicts TEXT;
+BEGIN
+    SELECT string_agg(format('user %s: %s (%s links)', user_id, quote_literal(slug), n), E'\n' ORDER BY user_id)
+    INTO conflicts
+    FROM (
+        SELECT user_id, slug, count(*) AS n
+        FROM links
+        GROUP BY user_id, slug
+        HAVING count(*) > 1
+    ) c;
+    IF conflicts IS NOT NULL THEN
+        RAISE EXCEPTION 'links share a slug and would violate unique_user_slug'
+            USING DETAIL = conflicts;
+    END IF;
+END $$;
+
+DROP INDEX IF EXISTS idx_links_user_slug_ci;
+
+ALTER TABLE links
+ADD CONSTRAINT unique_user_slug UNIQUE (user_id, slug);
@@ -0,0 +1,28 @@
+-- Slugs become case-insensitively unique per user. Links without a vanity
+-- slug store '' and no longer collide with each other.
+
+-- Slugs that differ only in case cannot be told apart any more. Renaming
+-- them here would break links already shared, so stop and list them; the
+-- owners have to pick new slugs before the migration can run.
+DO $$
+DECLARE
+    conflicts TEXT;
+BEGIN
+    SELECT string_agg(format('user %s: %s', user_id, slugs), E'\n' ORDER BY user_id)
+    INTO conflicts
+    FROM (
+        SELECT user_id, string_agg(quote_literal(slug), ', ' ORDER BY slug) AS slugs
+        FROM links
+        WHERE slug <> ''
+        GROUP BY user_id, lower(slug)
+        HAVING count(*) > 1
+    ) c;
+    IF conflicts IS NOT NULL THEN
+        RAISE EXCEPTION 'links have slugs that differ only in case; rename them and rerun'
+            USING DETAIL = conflicts;
+    END IF;
+END $$;
+
+ALTER TABLE links DROP CONSTRAINT IF EXISTS unique_user_slug;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_links_user_slug_ci ON links (user_id, lower(slug)) WHERE slug <> '';
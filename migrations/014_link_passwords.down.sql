@@ -0,0 +1,3 @@
+ALTER TABLE links
+DROP COLUMN IF EXISTS password_updated_at,
+DROP COLUMN IF EXISTS password_hash;
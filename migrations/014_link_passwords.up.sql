@@ -0,0 +1,5 @@
+-- Password-protected links. password_updated_at is part of the access cookie
+-- signature, so changing or removing a password revokes existing cookies.
+ALTER TABLE links
+ADD COLUMN IF NOT EXISTS password_hash TEXT,
+ADD COLUMN IF NOT EXISTS password_updated_at TIMESTAMPTZ;
@@ -0,0 +1,5 @@
+-- Where a click came from when it is not a plain visit to the short URL,
+-- e.g. 'qr' for scans of a generated QR code. NULL for direct clicks.
+ALTER TABLE clicks ADD COLUMN IF NOT EXISTS source TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_clicks_link_source ON clicks(link_id, source);
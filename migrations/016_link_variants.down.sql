@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_clicks_variant_id;
+ALTER TABLE clicks DROP COLUMN IF EXISTS variant_id;
+
+DROP TABLE IF EXISTS link_variants;
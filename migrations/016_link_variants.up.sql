@@ -0,0 +1,18 @@
+-- A/B split destinations. A link with variants sends each visitor to one of
+-- them, chosen by weight and kept sticky with a cookie; links.destination is
+-- only used when there are none.
+CREATE TABLE IF NOT EXISTS link_variants (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    link_id UUID NOT NULL REFERENCES links(id) ON DELETE CASCADE,
+    label TEXT NOT NULL DEFAULT '',
+    destination TEXT NOT NULL,
+    weight INT NOT NULL CHECK (weight > 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_link_variants_link_id ON link_variants(link_id);
+
+ALTER TABLE clicks
+ADD COLUMN IF NOT EXISTS variant_id UUID REFERENCES link_variants(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_clicks_variant_id ON clicks(variant_id) WHERE variant_id IS NOT NULL;
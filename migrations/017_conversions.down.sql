@@ -0,0 +1,5 @@
+ALTER TABLE clicks
+DROP COLUMN IF EXISTS revenue_cents,
+DROP COLUMN IF EXISTS converted_at;
+
+ALTER TABLE links DROP COLUMN IF EXISTS append_click_id;
@@ -0,0 +1,8 @@
+-- Conversion tracking. Links with append_click_id pass a signed click ID to
+-- their destination, which reports conversions back against that click.
+ALTER TABLE links
+ADD COLUMN IF NOT EXISTS append_click_id BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE clicks
+ADD COLUMN IF NOT EXISTS converted_at TIMESTAMPTZ,
+ADD COLUMN IF NOT EXISTS revenue_cents BIGINT;
@@ -0,0 +1,4 @@
+-- Ordered conditional routing rules; the first match replaces the
+-- destination, links.destination remains the fallback.
+ALTER TABLE links
+ADD COLUMN IF NOT EXISTS routing_rules JSONB NOT NULL DEFAULT '[]'::JSONB;
@@ -0,0 +1,5 @@
+ALTER TABLE link_revisions DROP COLUMN IF EXISTS schedule_id;
+
+DROP TABLE IF EXISTS link_schedules;
+
+ALTER TABLE links DROP COLUMN IF EXISTS activates_at;
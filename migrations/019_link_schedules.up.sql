@@ -0,0 +1,22 @@
+-- Scheduled launches and destination changes. A link with activates_at in the
+-- future serves a "coming soon" page until then; link_schedules holds
+-- destination swaps that the scheduler applies once run_at has passed.
+ALTER TABLE links
+ADD COLUMN IF NOT EXISTS activates_at TIMESTAMPTZ;
+
+CREATE TABLE IF NOT EXISTS link_schedules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    link_id UUID NOT NULL REFERENCES links(id) ON DELETE CASCADE,
+    destination TEXT NOT NULL,
+    run_at TIMESTAMPTZ NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'applied', 'cancelled')),
+    applied_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_link_schedules_link_id ON link_schedules(link_id);
+CREATE INDEX IF NOT EXISTS idx_link_schedules_due ON link_schedules(run_at) WHERE status = 'pending';
+
+-- Revisions written by the scheduler point at the schedule that caused them.
+ALTER TABLE link_revisions
+ADD COLUMN IF NOT EXISTS schedule_id UUID REFERENCES link_schedules(id) ON DELETE SET NULL;
@@ -0,0 +1,17 @@
+-- Apps allowed to open short links on a user's custom domains, served as
+-- /.well-known/apple-app-site-association and /.well-known/assetlinks.json.
+-- A row without custom_domain_id applies to all of the user's domains.
+CREATE TABLE IF NOT EXISTS app_associations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    custom_domain_id UUID REFERENCES custom_domains(id) ON DELETE CASCADE,
+    platform TEXT NOT NULL CHECK (platform IN ('ios', 'android')),
+    app_id TEXT NOT NULL,
+    paths TEXT[] NOT NULL DEFAULT '{}',
+    fingerprints TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_app_associations_unique
+    ON app_associations (user_id, COALESCE(custom_domain_id, '00000000-0000-0000-0000-000000000000'::uuid), platform, app_id);
+CREATE INDEX IF NOT EXISTS idx_app_associations_domain ON app_associations(custom_domain_id);
@@ -0,0 +1,4 @@
+ALTER TABLE links
+DROP COLUMN IF EXISTS og_image,
+DROP COLUMN IF EXISTS og_description,
+DROP COLUMN IF EXISTS og_title;
@@ -0,0 +1,5 @@
+-- Open Graph card served to social preview crawlers in place of the redirect.
+ALTER TABLE links
+ADD COLUMN IF NOT EXISTS og_title TEXT,
+ADD COLUMN IF NOT EXISTS og_description TEXT,
+ADD COLUMN IF NOT EXISTS og_image TEXT;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS bio_page_items;
+DROP TABLE IF EXISTS bio_pages;
@@ -0,0 +1,27 @@
+-- Link-in-bio pages served at /@handle. Each user has at most one page,
+-- listing an ordered set of their links; visitors follow the items through
+-- the short URL so bio traffic is tracked like any other click.
+CREATE TABLE IF NOT EXISTS bio_pages (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+    handle TEXT NOT NULL,
+    title TEXT NOT NULL DEFAULT '',
+    description TEXT NOT NULL DEFAULT '',
+    avatar_url TEXT NOT NULL DEFAULT '',
+    theme TEXT NOT NULL DEFAULT 'light',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_bio_pages_handle ON bio_pages (lower(handle));
+
+CREATE TABLE IF NOT EXISTS bio_page_items (
+    page_id UUID NOT NULL REFERENCES bio_pages(id) ON DELETE CASCADE,
+    link_id UUID NOT NULL REFERENCES links(id) ON DELETE CASCADE,
+    position INT NOT NULL,
+    title TEXT NOT NULL DEFAULT '',
+    icon TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (page_id, link_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_bio_page_items_order ON bio_page_items (page_id, position);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_bulk_jobs_claim;
+ALTER TABLE bulk_jobs
+DROP COLUMN IF EXISTS heartbeat_at,
+DROP COLUMN IF EXISTS attempts,
+DROP COLUMN IF EXISTS origin,
+DROP COLUMN IF EXISTS payload;
@@ -0,0 +1,16 @@
+-- Persist what a background bulk job needs to run, so any instance can pick
+-- it up and a restart resumes it. A job is leased to a worker through
+-- heartbeat_at; one whose worker stopped heartbeating is claimed again.
+-- The payload is cleared once the job finishes.
+ALTER TABLE bulk_jobs
+ADD COLUMN IF NOT EXISTS payload JSONB,
+ADD COLUMN IF NOT EXISTS origin JSONB,
+ADD COLUMN IF NOT EXISTS attempts INTEGER NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS heartbeat_at TIMESTAMPTZ;
+
+-- Jobs submitted before this migration have nothing to resume from.
+UPDATE bulk_jobs
+SET status = 'failed', error = 'job interrupted', finished_at = now()
+WHERE status IN ('queued', 'running');
+
+CREATE INDEX IF NOT EXISTS idx_bulk_jobs_claim ON bulk_jobs(created_at) WHERE status IN ('queued', 'running');
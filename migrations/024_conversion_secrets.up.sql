@@ -0,0 +1,4 @@
+-- Per-user secret that signs server-to-server conversion reports. Only
+-- signed reports may carry revenue.
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS conversion_secret TEXT;
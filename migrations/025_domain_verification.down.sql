@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_custom_domains_user;
+ALTER TABLE custom_domains
+DROP COLUMN IF EXISTS verified_at,
+DROP COLUMN IF EXISTS verification_token;
@@ -0,0 +1,7 @@
+-- Custom domains prove ownership with a DNS TXT record holding their
+-- verification token before they serve app association files.
+ALTER TABLE custom_domains
+ADD COLUMN IF NOT EXISTS verification_token TEXT NOT NULL DEFAULT encode(gen_random_bytes(16), 'hex'),
+ADD COLUMN IF NOT EXISTS verified_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_custom_domains_user ON custom_domains(user_id);
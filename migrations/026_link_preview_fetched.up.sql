@@ -0,0 +1,4 @@
+-- Marks share previews filled from the destination's own Open Graph tags,
+-- which password-protected links must not show to crawlers.
+ALTER TABLE links
+ADD COLUMN IF NOT EXISTS og_fetched BOOLEAN NOT NULL DEFAULT FALSE;